@@ -0,0 +1,35 @@
+package containers
+
+import (
+	"github.com/IsaacDSC/featureflag/internal/env"
+	"github.com/IsaacDSC/featureflag/pkg/pubsub"
+	"github.com/redis/go-redis/v9"
+)
+
+type PubSubContainer struct {
+	Publisher  pubsub.Publisher
+	Subscriber pubsub.Subscriber
+}
+
+// NewPubSubContainer builds the backend of environment.PubSubType, rdb is only used
+// by the redis backends and can be nil with env.PubSubMemory
+func NewPubSubContainer(rdb *redis.Client, environment *env.Environment) PubSubContainer {
+	switch environment.PubSubType {
+	case env.PubSubMemory:
+		memory := pubsub.NewMemory(int(environment.StreamMaxLen))
+		return PubSubContainer{
+			Publisher:  memory,
+			Subscriber: memory,
+		}
+	case env.PubSubStream:
+		return PubSubContainer{
+			Publisher:  pubsub.NewStreamPublisher(rdb, environment.StreamMaxLen),
+			Subscriber: pubsub.NewStreamSubscriber(rdb),
+		}
+	}
+
+	return PubSubContainer{
+		Publisher:  pubsub.NewRedisPublisher(rdb),
+		Subscriber: pubsub.NewRedisSubscriber(rdb),
+	}
+}
@@ -1,25 +1,52 @@
 package containers
 
 import (
+	"github.com/IsaacDSC/featureflag/internal/changestream"
 	"github.com/IsaacDSC/featureflag/internal/contenthub"
 	"github.com/IsaacDSC/featureflag/internal/env"
 	"github.com/IsaacDSC/featureflag/internal/featureflag"
+	"github.com/IsaacDSC/featureflag/internal/outbox"
+	"github.com/IsaacDSC/featureflag/internal/revision"
+	"github.com/IsaacDSC/featureflag/internal/webhook"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// backends of the repositories, recorded in their spans
+const (
+	backendJsonfile = "jsonfile"
+	backendMongodb  = "mongodb"
+)
+
 type RepositoryContainer struct {
 	FeatureFlagRepository featureflag.Adapter
 	ContentHubRepository  contenthub.Adapter
+	ContentTypeRepository contenthub.TypeAdapter
+	WebhookRepository     webhook.Adapter
+	// OutboxStores hold the events saved with the changes, published by the outbox.Relay
+	OutboxStores []outbox.Store
+	// ChangeSources and ChangeTokens are only set when the events come from the mongodb change streams
+	ChangeSources []changestream.Source
+	ChangeTokens  changestream.TokenStore
+	// Revisions count the changes made through the services, for the ETags and the delta sync
+	Revisions revision.Store
 }
 
 func NewRepositoryContainer() RepositoryContainer {
+	outboxRepository := outbox.NewRepository(env.FilePathOutbox)
+
 	return RepositoryContainer{
-		FeatureFlagRepository: featureflag.NewFeatureFlagRepository(),
-		ContentHubRepository:  contenthub.NewContentHubRepository(env.FilePathContentHub),
+		FeatureFlagRepository: featureflag.NewInstrumentedRepository(featureflag.NewFeatureFlagRepository(outboxRepository), backendJsonfile),
+		ContentHubRepository:  contenthub.NewInstrumentedRepository(contenthub.NewContentHubRepository(env.FilePathContentHub, outboxRepository), backendJsonfile),
+		ContentTypeRepository: contenthub.NewContentTypeRepository(env.FilePathContentTypes),
+		WebhookRepository:     webhook.NewWebhookRepository(env.FilePathWebhook),
+		OutboxStores:          []outbox.Store{outboxRepository},
+		Revisions:             revision.NewRepository(env.FilePathRevisions),
 	}
 }
 
-func NewRepositoryContainerMongodb(client *mongo.Client, mongodbName string) RepositoryContainer {
+// NewRepositoryContainerMongodb publishes the changes through the outbox, or from the change streams
+// of the collections when changeStream is true
+func NewRepositoryContainerMongodb(client *mongo.Client, mongodbName string, changeStream bool) RepositoryContainer {
 	database := client.Database(mongodbName)
 
 	featureFlagRepository, err := featureflag.NewMongoDBFeatureFlagRepository(database)
@@ -32,8 +59,40 @@ func NewRepositoryContainerMongodb(client *mongo.Client, mongodbName string) Rep
 		panic(err)
 	}
 
+	contentTypeRepository, err := contenthub.NewMongoDBContentTypeRepository(database)
+	if err != nil {
+		panic(err)
+	}
+
+	webhookRepository, err := webhook.NewMongoDBWebhookRepository(database)
+	if err != nil {
+		panic(err)
+	}
+
+	// deletes holds the events of the deletes, saved in both modes as the change streams can't tell a deleted key
+	deletes := outbox.NewMongoDBRepository(database.Collection(outbox.Collection))
+
+	// without Revisions in change stream mode: the changes made directly in the database reach the SDK
+	// streams but not the revisions, so the ETags and the changes endpoint would hide them from the
+	// polling SDKs, which load the full lists instead
+	if changeStream {
+		return RepositoryContainer{
+			FeatureFlagRepository: featureflag.NewInstrumentedRepository(featureFlagRepository.SkipEvents(), backendMongodb),
+			ContentHubRepository:  contenthub.NewInstrumentedRepository(contentHubRepository.SkipEvents(), backendMongodb),
+			ContentTypeRepository: contentTypeRepository,
+			WebhookRepository:     webhookRepository,
+			OutboxStores:          []outbox.Store{deletes},
+			ChangeSources:         []changestream.Source{featureFlagRepository.Changes(), contentHubRepository.Changes()},
+			ChangeTokens:          changestream.NewTokenRepository(database),
+		}
+	}
+
 	return RepositoryContainer{
-		FeatureFlagRepository: featureFlagRepository,
-		ContentHubRepository:  contentHubRepository,
+		FeatureFlagRepository: featureflag.NewInstrumentedRepository(featureFlagRepository, backendMongodb),
+		ContentHubRepository:  contenthub.NewInstrumentedRepository(contentHubRepository, backendMongodb),
+		ContentTypeRepository: contentTypeRepository,
+		WebhookRepository:     webhookRepository,
+		OutboxStores:          []outbox.Store{featureFlagRepository.Outbox(), contentHubRepository.Outbox(), deletes},
+		Revisions:             revision.NewMongoDBRepository(database),
 	}
 }
@@ -3,17 +3,22 @@ package containers
 import (
 	"github.com/IsaacDSC/featureflag/internal/contenthub"
 	"github.com/IsaacDSC/featureflag/internal/featureflag"
-	"github.com/IsaacDSC/featureflag/pkg/pubsub"
+	"github.com/IsaacDSC/featureflag/internal/outbox"
+	"github.com/IsaacDSC/featureflag/internal/webhook"
 )
 
 type ServiceContainer struct {
 	FeatureFlagService *featureflag.Service
 	ContentHubService  *contenthub.Service
+	WebhookService     *webhook.Service
+	OutboxRelay        *outbox.Relay
 }
 
-func NewServiceContainer(repositories RepositoryContainer, pub pubsub.Publisher) ServiceContainer {
+func NewServiceContainer(repositories RepositoryContainer, relay *outbox.Relay) ServiceContainer {
 	return ServiceContainer{
-		FeatureFlagService: featureflag.NewFeatureflagService(repositories.FeatureFlagRepository, pub),
-		ContentHubService:  contenthub.NewContentHubService(repositories.ContentHubRepository, pub),
+		FeatureFlagService: featureflag.NewFeatureflagService(repositories.FeatureFlagRepository, relay).WithRevisions(repositories.Revisions),
+		ContentHubService:  contenthub.NewContentHubService(repositories.ContentHubRepository, relay).WithRevisions(repositories.Revisions).WithTypes(repositories.ContentTypeRepository),
+		WebhookService:     webhook.NewWebhookService(repositories.WebhookRepository),
+		OutboxRelay:        relay,
 	}
 }
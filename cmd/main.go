@@ -2,7 +2,9 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -10,17 +12,21 @@ import (
 	"time"
 
 	"github.com/IsaacDSC/featureflag/cmd/containers"
+	"github.com/IsaacDSC/featureflag/internal/changestream"
 	"github.com/IsaacDSC/featureflag/internal/env"
+	"github.com/IsaacDSC/featureflag/internal/grpcserver"
+	"github.com/IsaacDSC/featureflag/internal/metrics"
+	"github.com/IsaacDSC/featureflag/internal/outbox"
+	"github.com/IsaacDSC/featureflag/internal/sdknotifier"
+	"github.com/IsaacDSC/featureflag/internal/webhook"
 	"github.com/IsaacDSC/featureflag/pkg/handlers"
 	"github.com/IsaacDSC/featureflag/pkg/middlewares"
-	"github.com/IsaacDSC/featureflag/pkg/pubsub"
+	"github.com/IsaacDSC/featureflag/pkg/telemetry"
 	"github.com/redis/go-redis/v9"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-var rdb *redis.Client
-
 func init() {
 	env.Init()
 	for i := range env.FilesPaths {
@@ -30,26 +36,50 @@ func init() {
 			}
 		}
 	}
+}
 
+// newRedisClient connects to REDIS_ADDR, only the redis pubsub backends need it
+func newRedisClient() (*redis.Client, error) {
 	redisAddr := os.Getenv("REDIS_ADDR")
 	if redisAddr == "" {
 		redisAddr = "localhost:6379"
 	}
 
-	rdb = redis.NewClient(&redis.Options{
+	rdb := redis.NewClient(&redis.Options{
 		Addr: redisAddr,
 	})
 
 	if err := rdb.Ping(context.Background()).Err(); err != nil {
-		panic(err)
+		rdb.Close()
+		return nil, fmt.Errorf("ping redis at %s: %w", redisAddr, err)
 	}
+
+	return rdb, nil
 }
 
 func main() {
 	environment := env.Get()
 
+	shutdownTelemetry, err := telemetry.Setup(context.Background(), telemetry.Config{
+		ServiceName: environment.OtelServiceName,
+		Exporter:    environment.OtelExporter,
+	})
+	if err != nil {
+		log.Fatalf("Failed to setup telemetry: %v", err)
+	}
+
+	if environment.MetricsAuth == metrics.AuthBearer && environment.MetricsToken == "" {
+		log.Fatalf("METRICS_AUTH=%s requires METRICS_TOKEN", metrics.AuthBearer)
+	}
+
+	changeStream := environment.EventSource == env.EventSourceChangeStream
+
 	var repositories containers.RepositoryContainer
 	if environment.RepositoryType == "jsonfile" {
+		if changeStream {
+			log.Fatalf("EVENT_SOURCE=%s requires REPOSITORY_TYPE=mongodb", env.EventSourceChangeStream)
+		}
+
 		repositories = containers.NewRepositoryContainer()
 	} else {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -68,18 +98,61 @@ func main() {
 
 		defer client.Disconnect(ctx)
 
-		repositories = containers.NewRepositoryContainerMongodb(client, environment.MongoDBName)
+		repositories = containers.NewRepositoryContainerMongodb(client, environment.MongoDBName, changeStream)
 	}
 
-	pub := pubsub.NewPublisher(rdb)
-	services := containers.NewServiceContainer(repositories, pub)
-	sub := pubsub.NewSubscriber(rdb)
+	var rdb *redis.Client
+	if environment.PubSubType != env.PubSubMemory {
+		client, err := newRedisClient()
+		if err != nil {
+			log.Fatalf("Failed to connect to Redis (set PUBSUB_TYPE=%s to run without it): %v", env.PubSubMemory, err)
+		}
+		rdb = client
+	}
+
+	pubSub := containers.NewPubSubContainer(rdb, environment)
+	relay := outbox.NewRelay(pubSub.Publisher, outbox.RelayConfig{
+		Interval:   environment.OutboxInterval,
+		Backoff:    environment.OutboxBackoff,
+		MaxBackoff: environment.OutboxMaxBackoff,
+		BatchSize:  environment.OutboxBatchSize,
+	}, repositories.OutboxStores...)
+	relay.Start()
+
+	var watcher *changestream.Watcher
+	if changeStream {
+		watcher = changestream.NewWatcher(pubSub.Publisher, repositories.ChangeTokens, repositories.ChangeSources...)
+		watcher.Start()
+	}
+
+	services := containers.NewServiceContainer(repositories, relay)
+	services.ContentHubService.WithRequiredLocales(environment.RequiredLocales)
+
+	hub := sdknotifier.NewHub(pubSub.Subscriber, sdknotifier.HubConfig{
+		ClientBuffer: environment.SSEClientBuffer,
+		SlowConsumer: environment.SSESlowConsumer,
+		Heartbeat:    environment.SSEHeartbeat,
+		History:      environment.SSEHistory,
+	})
+
+	worker := webhook.NewWorker(pubSub.Subscriber, services.WebhookService, webhook.WorkerConfig{
+		MaxAttempts: environment.WebhookMaxAttempts,
+		Backoff:     environment.WebhookBackoff,
+		MaxBackoff:  environment.WebhookMaxBackoff,
+		Timeout:     environment.WebhookTimeout,
+	})
+	worker.Start()
+
+	entries, err := telemetry.ObserveEntries(handlers.NewEntryCounters(services))
+	if err != nil {
+		log.Fatalf("Failed to register metrics: %v", err)
+	}
+	defer entries.Unregister()
 
 	mux := http.NewServeMux()
-	handlers := handlers.NewHandlers(services, sub)
-	for path, handler := range handlers {
+	for path, handler := range handlers.NewHandlers(services, hub) {
 		// mux.HandleFunc(path, middlewares.Authorization(handler))
-		mux.HandleFunc(path, middlewares.Logger(handler))
+		mux.HandleFunc(path, middlewares.Tracing(path, middlewares.Logger(path, handler)))
 	}
 
 	server := &http.Server{
@@ -87,6 +160,18 @@ func main() {
 		Handler: mux,
 	}
 
+	grpcServer := grpcserver.New(grpcserver.NewServer(
+		services.FeatureFlagService,
+		services.ContentHubService,
+		hub,
+		handlers.NewSnapshots(services),
+	))
+
+	lis, err := net.Listen("tcp", ":"+environment.GRPCPort)
+	if err != nil {
+		log.Fatalf("Failed to listen gRPC: %v", err)
+	}
+
 	stop := make(chan os.Signal, 1)
 	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
 
@@ -97,9 +182,21 @@ func main() {
 		}
 	}()
 
+	go func() {
+		log.Printf("[*] gRPC server started at :%s", environment.GRPCPort)
+		if err := grpcServer.Serve(lis); err != nil {
+			log.Fatalf("gRPC server error: %v", err)
+		}
+	}()
+
 	<-stop
 	log.Print("\n[*] Shutting down server...")
 
+	// disconnects the SSE clients, otherwise Shutdown waits for them until the timeout
+	hub.Close()
+	grpcServer.GracefulStop()
+	worker.Close()
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
@@ -107,8 +204,20 @@ func main() {
 		log.Printf("Server forced to shutdown: %v", err)
 	}
 
-	if err := rdb.Close(); err != nil {
-		log.Printf("Error closing Redis connection: %v", err)
+	// after the server, so the changes of the last requests are published
+	relay.Close()
+	if watcher != nil {
+		watcher.Close()
+	}
+
+	if rdb != nil {
+		if err := rdb.Close(); err != nil {
+			log.Printf("Error closing Redis connection: %v", err)
+		}
+	}
+
+	if err := shutdownTelemetry(ctx); err != nil {
+		log.Printf("Error flushing telemetry: %v", err)
 	}
 
 	log.Print("[*] Server stopped")
@@ -14,12 +14,10 @@ func main() {
 	ctx := context.Background()
 	contenthub := contenthub.NewContenthubSDK("http://localhost:3000")
 
-	go func() {
-		_, err := contenthub.Listenner(ctx)
-		if err != nil {
-			panic(err)
-		}
-	}()
+	if err := contenthub.Start(ctx); err != nil {
+		panic(err)
+	}
+	defer contenthub.Close()
 
 	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		content := contenthub.Content("invalid_ff").Val()
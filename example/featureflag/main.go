@@ -13,12 +13,10 @@ func main() {
 	ctx := context.Background()
 	ff := featureflag.NewFeatureFlagSDK("http://localhost:3000")
 
-	go func() {
-		_, err := ff.Listenner(ctx)
-		if err != nil {
-			panic(err)
-		}
-	}()
+	if err := ff.Start(ctx); err != nil {
+		panic(err)
+	}
+	defer ff.Close()
 
 	http.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
 		isActive := ff.GetFeatureFlag("invalid_ff").WithDefault(true)
@@ -0,0 +1,103 @@
+package changestream
+
+import (
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Source is a collection whose changes are published on Channel as its entity
+type Source struct {
+	Collection *mongo.Collection
+	Channel    string
+	// Ignore are the field paths (and their subfields) whose changes are not published,
+	// as the counters written on evaluation and the outbox of the documents
+	Ignore []string
+
+	decode func(raw bson.Raw) (any, error)
+	skip   func(event Event) bool
+}
+
+// NewSource decodes the documents of collection into T, the type published by the services on channel
+func NewSource[T any](collection *mongo.Collection, channel string, ignore ...string) Source {
+	return Source{
+		Collection: collection,
+		Channel:    channel,
+		Ignore:     ignore,
+		decode: func(raw bson.Raw) (any, error) {
+			var entity T
+			if err := bson.Unmarshal(raw, &entity); err != nil {
+				return nil, fmt.Errorf("error on decode %s document: %w", collection.Name(), err)
+			}
+			return entity, nil
+		},
+	}
+}
+
+// View maps the documents of source, decoded into T, to what is published, as to leave out the
+// fields that are not public
+func View[T any](source Source, view func(T) T) Source {
+	decode := source.decode
+	source.decode = func(raw bson.Raw) (any, error) {
+		entity, err := decode(raw)
+		if err != nil {
+			return nil, err
+		}
+		return view(entity.(T)), nil
+	}
+
+	return source
+}
+
+// Event is the part of the change event used by the Watcher
+type Event struct {
+	OperationType     string   `bson:"operationType"`
+	FullDocument      bson.Raw `bson:"fullDocument"`
+	UpdateDescription struct {
+		UpdatedFields bson.M   `bson:"updatedFields"`
+		RemovedFields []string `bson:"removedFields"`
+	} `bson:"updateDescription"`
+}
+
+// Only reports whether the event is an update that only changes fields (or their subfields)
+func (e Event) Only(fields ...string) bool {
+	if e.OperationType != "update" {
+		return false
+	}
+
+	changed := e.UpdateDescription.RemovedFields
+	for field := range e.UpdateDescription.UpdatedFields {
+		changed = append(changed, field)
+	}
+
+	for _, field := range changed {
+		if !hasPrefix(field, fields) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func hasPrefix(field string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if field == prefix || strings.HasPrefix(field, prefix+".") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WithSkip adds a filter for the events that depend on the document, as the Ignore fields cannot express
+func (s Source) WithSkip(skip func(event Event) bool) Source {
+	s.skip = skip
+	return s
+}
+
+// Ignored reports whether the event is not published
+func (s Source) Ignored(event Event) bool {
+	return event.Only(s.Ignore...) || (s.skip != nil && s.skip(event))
+}
@@ -0,0 +1,65 @@
+package changestream
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/IsaacDSC/featureflag/pkg/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const collectionName = mongodb.CollectionName("change_stream_tokens")
+
+// TokenRepository keeps the resume token of each watched collection, by collection name
+type TokenRepository struct {
+	collection *mongo.Collection
+	timeout    time.Duration
+}
+
+func NewTokenRepository(database *mongo.Database) *TokenRepository {
+	return &TokenRepository{
+		collection: database.Collection(collectionName.String()),
+		timeout:    10 * time.Second,
+	}
+}
+
+// GetToken returns nil when the collection was never watched
+func (tr *TokenRepository) GetToken(ctx context.Context, name string) (bson.Raw, error) {
+	ctx, cancel := context.WithTimeout(ctx, tr.timeout)
+	defer cancel()
+
+	var doc struct {
+		Token bson.Raw `bson:"token"`
+	}
+
+	err := tr.collection.FindOne(ctx, bson.M{"_id": name}).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return doc.Token, nil
+}
+
+func (tr *TokenRepository) SaveToken(ctx context.Context, name string, token bson.Raw) error {
+	ctx, cancel := context.WithTimeout(ctx, tr.timeout)
+	defer cancel()
+
+	update := bson.M{"$set": bson.M{"token": token, "updated_at": time.Now()}}
+	_, err := tr.collection.UpdateOne(ctx, bson.M{"_id": name}, update, options.Update().SetUpsert(true))
+	return err
+}
+
+func (tr *TokenRepository) DeleteToken(ctx context.Context, name string) error {
+	ctx, cancel := context.WithTimeout(ctx, tr.timeout)
+	defer cancel()
+
+	_, err := tr.collection.DeleteOne(ctx, bson.M{"_id": name})
+	return err
+}
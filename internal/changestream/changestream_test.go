@@ -0,0 +1,180 @@
+package changestream
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/IsaacDSC/featureflag/pkg/pubsub"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type flag struct {
+	FlagName string `bson:"flag_name"`
+	Active   bool   `bson:"active"`
+}
+
+type fakePublisher struct {
+	channels []string
+	payloads []pubsub.Payload
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, channel string, msg pubsub.Payload) error {
+	p.channels = append(p.channels, channel)
+	p.payloads = append(p.payloads, msg)
+	return nil
+}
+
+func newCollection(t *testing.T) *mongo.Collection {
+	t.Helper()
+
+	// the client connects lazily, no server is needed to build the collection
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://localhost:1"))
+	if err != nil {
+		t.Fatalf("error on create client: %v", err)
+	}
+	t.Cleanup(func() { client.Disconnect(context.Background()) })
+
+	return client.Database("test").Collection("featureflags")
+}
+
+func rawEvent(t *testing.T, operation string, doc any, updated bson.M) bson.Raw {
+	t.Helper()
+
+	event := bson.M{"operationType": operation}
+	if doc != nil {
+		event["fullDocument"] = doc
+	}
+	if updated != nil {
+		event["updateDescription"] = bson.M{"updatedFields": updated, "removedFields": bson.A{}}
+	}
+
+	b, err := bson.Marshal(event)
+	if err != nil {
+		t.Fatalf("error on marshal event: %v", err)
+	}
+
+	return b
+}
+
+func TestSource_Ignored(t *testing.T) {
+	source := NewSource[flag](newCollection(t), "featureflag", "outbox", "strategy.qtdcall")
+
+	tests := []struct {
+		name    string
+		event   Event
+		ignored bool
+	}{
+		{name: "insert", event: Event{OperationType: "insert"}, ignored: false},
+		{name: "replace", event: Event{OperationType: "replace"}, ignored: false},
+		{name: "update of outbox only", event: updateEvent(bson.M{"outbox": bson.A{}}), ignored: true},
+		{name: "update of outbox entry", event: updateEvent(bson.M{"outbox.0.attempts": 1}), ignored: true},
+		{name: "update of counter", event: updateEvent(bson.M{"strategy.qtdcall": 3}), ignored: true},
+		{name: "update of active", event: updateEvent(bson.M{"active": true, "outbox.1": bson.M{}}), ignored: false},
+		{name: "update of field with ignored prefix", event: updateEvent(bson.M{"outboxes": 1}), ignored: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := source.Ignored(tt.event); got != tt.ignored {
+				t.Errorf("Ignored() = %v, want %v", got, tt.ignored)
+			}
+		})
+	}
+}
+
+func updateEvent(updated bson.M) Event {
+	event := Event{OperationType: "update"}
+	event.UpdateDescription.UpdatedFields = updated
+	return event
+}
+
+func TestWatcher_Handle(t *testing.T) {
+	pub := &fakePublisher{}
+	watcher := NewWatcher(pub, nil)
+	defer watcher.Close()
+
+	source := NewSource[flag](newCollection(t), "featureflag", "outbox").
+		WithSkip(func(event Event) bool {
+			var f flag
+			bson.Unmarshal(event.FullDocument, &f)
+			return f.FlagName == "skipped"
+		})
+
+	events := []bson.Raw{
+		rawEvent(t, "update", bson.M{"flag_name": "a", "active": true, "outbox": bson.A{}}, bson.M{"active": true}),
+		rawEvent(t, "update", bson.M{"flag_name": "a", "active": true}, bson.M{"outbox": bson.A{}}),
+		rawEvent(t, "insert", bson.M{"flag_name": "skipped", "active": true}, nil),
+		// deleted before the lookup of the full document
+		rawEvent(t, "update", nil, bson.M{"active": false}),
+		rawEvent(t, "replace", bson.M{"flag_name": "b", "active": false}, nil),
+	}
+
+	for _, event := range events {
+		if err := watcher.handle(source, event); err != nil {
+			t.Fatalf("handle() error = %v", err)
+		}
+	}
+
+	if len(pub.payloads) != 2 {
+		t.Fatalf("published %d events, want 2", len(pub.payloads))
+	}
+
+	for i, want := range []flag{{FlagName: "a", Active: true}, {FlagName: "b"}} {
+		if pub.channels[i] != "featureflag" || pub.payloads[i] != pubsub.NewPayload(want) {
+			t.Errorf("published %v on %s, want %v", pub.payloads[i], pub.channels[i], want)
+		}
+	}
+}
+
+func TestWatcher_Handle_View(t *testing.T) {
+	pub := &fakePublisher{}
+	watcher := NewWatcher(pub, nil)
+	defer watcher.Close()
+
+	source := View(NewSource[flag](newCollection(t), "featureflag"), func(f flag) flag {
+		f.Active = false
+		return f
+	})
+
+	event := rawEvent(t, "update", bson.M{"flag_name": "a", "active": true}, bson.M{"active": true})
+	if err := watcher.handle(source, event); err != nil {
+		t.Fatalf("handle() error = %v", err)
+	}
+
+	if want := pubsub.NewPayload(flag{FlagName: "a"}); len(pub.payloads) != 1 || pub.payloads[0] != want {
+		t.Errorf("published %v, want %v", pub.payloads, want)
+	}
+}
+
+func TestWatcher_Handle_Key(t *testing.T) {
+	pub := &fakePublisher{}
+	watcher := NewWatcher(pub, nil)
+	defer watcher.Close()
+
+	source := NewSource[flag](newCollection(t), "featureflag")
+
+	event, err := bson.Marshal(bson.M{
+		"_id":           bson.M{"_data": "8263"},
+		"operationType": "insert",
+		"fullDocument":  bson.M{"flag_name": "a", "active": true},
+	})
+	if err != nil {
+		t.Fatalf("error on marshal event: %v", err)
+	}
+
+	if err := watcher.handle(source, event); err != nil {
+		t.Fatalf("handle() error = %v", err)
+	}
+
+	if len(pub.payloads) != 1 {
+		t.Fatalf("published %d events, want 1", len(pub.payloads))
+	}
+
+	b, _ := json.Marshal(pub.payloads[0])
+	if got := pubsub.Key(b); got != "8263" {
+		t.Errorf("published %s, want the resume token as %s", b, pubsub.KeyField)
+	}
+}
@@ -0,0 +1,198 @@
+package changestream
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/IsaacDSC/featureflag/pkg/ctxlog"
+	"github.com/IsaacDSC/featureflag/pkg/pubsub"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	watchMinBackoff = time.Second
+	watchMaxBackoff = 30 * time.Second
+)
+
+// codes of the errors of a resume token that is no longer in the oplog
+var historyLostCodes = []int{
+	280, // ChangeStreamFatalError
+	286, // ChangeStreamHistoryLost
+}
+
+type TokenStore interface {
+	GetToken(ctx context.Context, name string) (bson.Raw, error)
+	SaveToken(ctx context.Context, name string, token bson.Raw) error
+	DeleteToken(ctx context.Context, name string) error
+}
+
+// Watcher publishes the inserts, updates and replaces of the sources, including the ones made directly
+// in the database. The resume token is saved after every event, so a restart continues where it stopped
+// while the oplog still has it. Deletes are not published, their events have no document to tell the key
+// and the services save them in the outbox instead.
+type Watcher struct {
+	pub     pubsub.Publisher
+	tokens  TokenStore
+	sources []Source
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewWatcher(pub pubsub.Publisher, tokens TokenStore, sources ...Source) *Watcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = ctxlog.SetLogger(ctx, ctxlog.NewLogger(ctx).With("component", "changestream"))
+
+	return &Watcher{
+		pub:     pub,
+		tokens:  tokens,
+		sources: sources,
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+func (w *Watcher) Start() {
+	for _, source := range w.sources {
+		w.wg.Add(1)
+		go w.run(source)
+	}
+}
+
+func (w *Watcher) Close() {
+	w.cancel()
+	w.wg.Wait()
+}
+
+func (w *Watcher) run(source Source) {
+	defer w.wg.Done()
+
+	backoff := watchMinBackoff
+	for {
+		err := w.watch(source, func() { backoff = watchMinBackoff })
+		if w.ctx.Err() != nil {
+			return
+		}
+
+		if isHistoryLost(err) {
+			// the changes made meanwhile are lost, the SDKs get them on their next snapshot
+			log.Printf("resume token of %s expired, watching from now: %v\n", source.Collection.Name(), err)
+			if err := w.tokens.DeleteToken(w.ctx, source.Collection.Name()); err != nil {
+				log.Printf("error on delete resume token of %s: %v\n", source.Collection.Name(), err)
+			}
+		}
+
+		log.Printf("change stream of %s stopped, restarting in %v: %v\n", source.Collection.Name(), backoff, err)
+
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, watchMaxBackoff)
+	}
+}
+
+func (w *Watcher) watch(source Source, connected func()) error {
+	name := source.Collection.Name()
+
+	token, err := w.tokens.GetToken(w.ctx, name)
+	if err != nil {
+		return fmt.Errorf("error on get resume token: %w", err)
+	}
+
+	opts := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+	if token != nil {
+		opts.SetStartAfter(token)
+	}
+
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: bson.M{
+		"operationType": bson.M{"$in": bson.A{"insert", "update", "replace"}},
+	}}}}
+
+	stream, err := source.Collection.Watch(w.ctx, pipeline, opts)
+	if err != nil {
+		return fmt.Errorf("error on watch: %w", err)
+	}
+	defer stream.Close(context.WithoutCancel(w.ctx))
+
+	log.Printf("watching change stream of %s (resumed: %t)", name, token != nil)
+	connected()
+
+	for stream.Next(w.ctx) {
+		if err := w.handle(source, stream.Current); err != nil {
+			// not saving the token makes the next start publish this event again
+			return err
+		}
+
+		if err := w.tokens.SaveToken(w.ctx, name, stream.ResumeToken()); err != nil {
+			log.Printf("error on save resume token of %s: %v\n", name, err)
+		}
+	}
+
+	return stream.Err()
+}
+
+func (w *Watcher) handle(source Source, raw bson.Raw) error {
+	var event Event
+	if err := bson.Unmarshal(raw, &event); err != nil {
+		return fmt.Errorf("error on decode change event: %w", err)
+	}
+
+	// fullDocument is empty when the document was deleted before the lookup
+	if source.Ignored(event) || len(event.FullDocument) == 0 {
+		return nil
+	}
+
+	entity, err := source.decode(event.FullDocument)
+	if err != nil {
+		log.Printf("skipping change of %s: %v\n", source.Collection.Name(), err)
+		return nil
+	}
+
+	payload := pubsub.NewPayload(entity)
+
+	// the resume token identifies the change, the same for every watcher of the collection
+	if token, ok := raw.Lookup("_id", "_data").StringValueOK(); ok {
+		b, err := json.Marshal(entity)
+		if err != nil {
+			return fmt.Errorf("error on marshal change of %s: %w", source.Collection.Name(), err)
+		}
+
+		if b, err = pubsub.WithKey(b, token); err != nil {
+			return err
+		}
+
+		payload = pubsub.NewPayload(json.RawMessage(b))
+	}
+
+	if err := w.pub.Publish(w.ctx, source.Channel, payload); err != nil {
+		return fmt.Errorf("error on publish change of %s: %w", source.Collection.Name(), err)
+	}
+
+	return nil
+}
+
+func isHistoryLost(err error) bool {
+	var serverErr mongo.ServerError
+	if !errors.As(err, &serverErr) {
+		return false
+	}
+
+	for _, code := range historyLostCodes {
+		if serverErr.HasErrorCode(code) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -7,9 +7,10 @@ import (
 const MaxCalls = 10
 
 type MultipleStrategy struct {
-	Weight   uint `json:"weight" bson:"weight"`
-	Response any  `json:"response" bson:"response"`
-	Qtt      uint `bson:"qtt"`
+	Weight   uint    `json:"weight" bson:"weight"`
+	Response any     `json:"response" bson:"response"`
+	Locales  Locales `json:"locales,omitempty" bson:"locales,omitempty"`
+	Qtt      uint    `bson:"qtt"`
 }
 
 type BalancerStrategy []MultipleStrategy
@@ -32,11 +33,23 @@ func (s *BalancerStrategy) Validate() error {
 // Distribution implementa a lógica de distribuição weighted
 // Distribui as respostas baseado no peso (weight) de cada estratégia
 // 10 chamadas = 100%
-func (s *BalancerStrategy) Distribution() any {
-	if s == nil || len(*s) == 0 {
+// A resposta escolhida é traduzida para o primeiro dos locales encontrado
+func (s *BalancerStrategy) Distribution(locales ...string) any {
+	i := s.next()
+	if i < 0 {
 		return nil
 	}
 
+	strategy := (*s)[i]
+	return Localize(strategy.Response, strategy.Locales, locales...)
+}
+
+// next conta a chamada e retorna o índice da resposta, -1 sem respostas
+func (s *BalancerStrategy) next() int {
+	if s == nil || len(*s) == 0 {
+		return -1
+	}
+
 	strategies := *s
 
 	var totalWeight uint
@@ -45,7 +58,7 @@ func (s *BalancerStrategy) Distribution() any {
 	}
 
 	if totalWeight == 0 {
-		return nil
+		return -1
 	}
 
 	var totalCalls uint
@@ -66,7 +79,7 @@ func (s *BalancerStrategy) Distribution() any {
 		if strategies[i].Qtt < expectedCalls {
 			strategies[i].Qtt++
 			(*s)[i].Qtt = strategies[i].Qtt
-			return strategies[i].Response
+			return i
 		}
 	}
 
@@ -74,13 +87,9 @@ func (s *BalancerStrategy) Distribution() any {
 		if totalCalls < MaxCalls {
 			strategies[i].Qtt++
 			(*s)[i].Qtt = strategies[i].Qtt
-			return strategies[i].Response
+			return i
 		}
 	}
 
-	if len(strategies) > 0 {
-		return strategies[0].Response
-	}
-
-	return nil
+	return 0
 }
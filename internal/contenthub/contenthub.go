@@ -1,6 +1,7 @@
 package contenthub
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,6 +16,66 @@ type Entity struct {
 	CreatedAt          time.Time          `json:"created_at" bson:"created_at"`
 	SessionsStrategies SessionsStrategies `json:"session_strategy" bson:"session_strategy"`
 	BalancerStrategy   BalancerStrategy   `json:"balancer_strategy" bson:"balancer_strategy"`
+	// Revision is the revision of the last change made through the service
+	Revision int64 `json:"revision" bson:"revision"`
+	// Draft is the change written by the editors, served only to the previews until it is published
+	Draft *Draft `json:"draft,omitempty" bson:"draft,omitempty"`
+	// Unpublished is set on the contents created as a draft and never published, which are not served
+	Unpublished bool `json:"unpublished,omitempty" bson:"unpublished,omitempty"`
+	// Schema is the JSON Schema of the value and the responses, Type the ContentType used without it
+	Schema json.RawMessage `json:"schema,omitempty" bson:"schema,omitempty"`
+	Type   string          `json:"type,omitempty" bson:"type,omitempty"`
+}
+
+// Draft is the state of a content waiting to be published
+type Draft struct {
+	Value              string             `json:"value" bson:"value"`
+	Description        string             `json:"description" bson:"description"`
+	Active             bool               `json:"active" bson:"active"`
+	SessionsStrategies SessionsStrategies `json:"session_strategy" bson:"session_strategy"`
+	BalancerStrategy   BalancerStrategy   `json:"balancer_strategy" bson:"balancer_strategy"`
+	UpdatedAt          time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// NewDraft takes the state of content, as received by PATCH
+func NewDraft(content Entity) Draft {
+	return Draft{
+		Value:              content.Value,
+		Description:        content.Description,
+		Active:             content.Active,
+		SessionsStrategies: content.SessionsStrategies,
+		BalancerStrategy:   content.BalancerStrategy,
+		UpdatedAt:          time.Now(),
+	}
+}
+
+// Published is the content served to the SDKs, without its draft
+func (e Entity) Published() Entity {
+	e.Draft = nil
+	return e
+}
+
+// Preview is the content with its draft applied, the published content when there is no draft
+func (e Entity) Preview() Entity {
+	if e.Draft == nil {
+		return e
+	}
+
+	e.Value = e.Draft.Value
+	e.Description = e.Draft.Description
+	e.Active = e.Draft.Active
+	e.SessionsStrategies = e.Draft.SessionsStrategies
+	e.BalancerStrategy = e.Draft.BalancerStrategy
+	e.Draft = nil
+
+	return e
+}
+
+// Publish promotes the draft to the published content
+func (e Entity) Publish() Entity {
+	e = e.Preview()
+	e.Unpublished = false
+	return e
 }
 
 func NewEntity(
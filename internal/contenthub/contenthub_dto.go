@@ -1,6 +1,7 @@
 package contenthub
 
 import (
+	"encoding/json"
 	"errors"
 	"strings"
 	"time"
@@ -17,6 +18,8 @@ type Dto struct {
 	CreatedAt          time.Time          `json:"created_at"`
 	SessionsStrategies SessionsStrategies `json:"session_strategy"`
 	BalancerStrategy   BalancerStrategy   `json:"balancer_strategy"`
+	Schema             json.RawMessage    `json:"schema,omitempty"`
+	Type               string             `json:"type,omitempty"`
 }
 
 func (c *Dto) ToDomain() (Entity, error) {
@@ -28,14 +31,30 @@ func (c *Dto) ToDomain() (Entity, error) {
 		return Entity{}, err
 	}
 
-	return NewEntity(
+	entity := NewEntity(
 		c.Active,
 		c.Variable,
 		c.Value,
 		c.Description,
 		c.SessionsStrategies,
 		c.BalancerStrategy,
-	), nil
+	)
+	entity.Schema = c.Schema
+	entity.Type = c.Type
+
+	// the schema of a content type is checked by the service, which can load it
+	if len(c.Schema) > 0 {
+		schema, err := CompileSchema(c.Schema)
+		if err != nil {
+			return Entity{}, err
+		}
+
+		if errs := ValidateContent(schema, entity); len(errs) > 0 {
+			return Entity{}, &SchemaValidationError{Key: entity.Variable, Errors: errs}
+		}
+	}
+
+	return entity, nil
 }
 
 func FromDomain(contenthub Entity) Dto {
@@ -48,6 +67,8 @@ func FromDomain(contenthub Entity) Dto {
 		CreatedAt:          contenthub.CreatedAt,
 		SessionsStrategies: contenthub.SessionsStrategies,
 		BalancerStrategy:   contenthub.BalancerStrategy,
+		Schema:             contenthub.Schema,
+		Type:               contenthub.Type,
 	}
 }
 
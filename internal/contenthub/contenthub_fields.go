@@ -9,4 +9,9 @@ const (
 	createdAt        = "created_at"
 	sessionStrategy  = "session_strategy"
 	balancerStrategy = "balancer_strategy"
+	revisionField    = "revision"
+	draft            = "draft"
+	unpublished      = "unpublished"
+	schema           = "schema"
+	contentType      = "type"
 )
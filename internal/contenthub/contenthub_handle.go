@@ -2,8 +2,14 @@ package contenthub
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+
+	"github.com/IsaacDSC/featureflag/internal/revision"
+	"github.com/IsaacDSC/featureflag/pkg/errorutils"
+	"github.com/IsaacDSC/featureflag/pkg/middlewares"
 )
 
 type ContenthubHandler struct {
@@ -11,17 +17,25 @@ type ContenthubHandler struct {
 	service *Service
 }
 
-const contenthubRouterPrefix = "/contenthub"
+const (
+	contenthubRouterPrefix  = "/contenthub"
+	contentTypeRouterPrefix = "/contenttypes"
+)
 
 func NewContenthubHandler(service *Service) *ContenthubHandler {
 	handler := new(ContenthubHandler)
 	handler.service = service
 	handler.routes = map[string]func(w http.ResponseWriter, r *http.Request){
-		fmt.Sprintf("PATCH %s", contenthubRouterPrefix):         handler.patchContenthub,    //middlewares.Authorization(middlewares.CheckPermission(handler.patchContenthub, middlewares.USERNAME_SERVICE)),
-		fmt.Sprintf("DELETE %s/{key}", contenthubRouterPrefix):  handler.deleteContenthub,   //middlewares.Authorization(middlewares.CheckPermission(handler.deleteContenthub, middlewares.USERNAME_SERVICE)),
-		fmt.Sprintf("GET %ss", contenthubRouterPrefix):          handler.getAllContenthub,   //middlewares.Authorization(middlewares.CheckPermission(handler.getAllContenthub, middlewares.USERNAME_SERVICE)),
-		fmt.Sprintf("GET %s/{key}", contenthubRouterPrefix):     handler.getContentHub,      //middlewares.Authentication(middlewares.CheckPermission(handler.getContentHub, middlewares.USERNAME_SERVICE)),
-		fmt.Sprintf("GET %s/sdk/{key}", contenthubRouterPrefix): handler.getContentHubBySDK, //middlewares.Authentication(middlewares.CheckPermission(handler.getContentHubBySDK, middlewares.USERNAME_SDK)),
+		fmt.Sprintf("PATCH %s", contenthubRouterPrefix):              handler.patchContenthub,   //middlewares.Authorization(middlewares.CheckPermission(handler.patchContenthub, middlewares.USERNAME_SERVICE)),
+		fmt.Sprintf("POST %s/{key}/publish", contenthubRouterPrefix): handler.publishContenthub, //middlewares.Authorization(middlewares.CheckPermission(handler.publishContenthub, middlewares.USERNAME_SERVICE)),
+		fmt.Sprintf("DELETE %s/{key}", contenthubRouterPrefix):       handler.deleteContenthub,  //middlewares.Authorization(middlewares.CheckPermission(handler.deleteContenthub, middlewares.USERNAME_SERVICE)),
+		fmt.Sprintf("GET %ss", contenthubRouterPrefix):               handler.getAllContenthub,  //middlewares.Authorization(middlewares.CheckPermission(handler.getAllContenthub, middlewares.USERNAME_SERVICE)),
+		fmt.Sprintf("GET %s/{key}", contenthubRouterPrefix):          handler.getContentHub,     //middlewares.Authentication(middlewares.CheckPermission(handler.getContentHub, middlewares.USERNAME_SERVICE)),
+		fmt.Sprintf("GET %s/{sdk}/{key}", contenthubRouterPrefix):    handler.getContentHubBySDK,
+		fmt.Sprintf("GET %s/{key}/schema", contenthubRouterPrefix):   handler.getSchema,
+		fmt.Sprintf("PUT %s/{name}", contentTypeRouterPrefix):        handler.putContentType,
+		fmt.Sprintf("GET %s/{name}", contentTypeRouterPrefix):        handler.getContentType,
+		fmt.Sprintf("GET %s", contentTypeRouterPrefix):               handler.getAllContentTypes,
 	}
 
 	return handler
@@ -44,12 +58,20 @@ func (h ContenthubHandler) patchContenthub(w http.ResponseWriter, r *http.Reques
 
 	payloadEntity, err := payload.ToDomain()
 	if err != nil {
+		if writeValidationError(w, err) {
+			return
+		}
+
 		w.WriteHeader(http.StatusBadRequest)
 		w.Write([]byte(err.Error()))
 		return
 	}
 
-	if err := h.service.CreateOrUpdate(ctx, payloadEntity); err != nil {
+	if err := h.service.SaveDraft(ctx, payloadEntity); err != nil {
+		if writeValidationError(w, err) {
+			return
+		}
+
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
@@ -57,15 +79,76 @@ func (h ContenthubHandler) patchContenthub(w http.ResponseWriter, r *http.Reques
 	w.WriteHeader(http.StatusCreated)
 }
 
+// writeValidationError answers the errors of a content that doesn't match its schema or its required
+// locales, it returns false for the other errors
+func writeValidationError(w http.ResponseWriter, err error) bool {
+	var missing *MissingTranslationsError
+	var invalid *SchemaValidationError
+
+	var body any
+	switch {
+	case errors.As(err, &missing):
+		body = missing
+	case errors.As(err, &invalid):
+		body = invalid
+	case errors.Is(err, ErrInvalidSchema), errors.Is(err, ErrUnknownContentType):
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+		return true
+	default:
+		return false
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	json.NewEncoder(w).Encode(body)
+	return true
+}
+
+// publishContenthub promotes the draft written by PATCH, the SDKs receive it by the stream
+func (h ContenthubHandler) publishContenthub(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	key := r.PathValue("key")
+
+	content, err := h.service.Publish(ctx, key)
+	if err != nil {
+		if writeValidationError(w, err) {
+			return
+		}
+
+		var notFound *errorutils.NotFoundError
+		switch {
+		case errors.As(err, &notFound):
+			w.WriteHeader(http.StatusNotFound)
+		case errors.Is(err, ErrNoDraft):
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(err.Error()))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(FromDomain(content)); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
 func (h ContenthubHandler) getContentHub(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	key := r.URL.Query().Get("key")
+	key := r.PathValue("key")
 	if key == "" {
 		w.WriteHeader(http.StatusBadRequest)
 		return
 	}
 
-	content, err := h.service.GetContentHub(ctx, key)
+	getContent := h.service.GetContentHub
+	if middlewares.IsClient(r, middlewares.USERNAME_PREVIEW) {
+		getContent = h.service.GetContentHubPreview
+	}
+
+	content, err := getContent(ctx, key)
 	if err != nil {
 		w.WriteHeader(http.StatusNotFound)
 		return
@@ -80,12 +163,26 @@ func (h ContenthubHandler) getContentHub(w http.ResponseWriter, r *http.Request)
 
 func (h ContenthubHandler) getAllContenthub(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	contents, err := h.service.GetAllContentHub(ctx)
+	if middlewares.IsClient(r, middlewares.USERNAME_PREVIEW) {
+		h.getAllContenthubPreview(w, r)
+		return
+	}
+
+	contents, current, err := h.service.GetAllContentHubRevision(ctx)
 	if err != nil {
 		w.WriteHeader(http.StatusNotFound)
 		return
 	}
 
+	if current != revision.Unknown {
+		w.Header().Set("ETag", revision.ETag(current))
+	}
+
+	if revision.NotModified(r, current) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
 	var result []Entity
 	for _, entity := range contents {
 		result = append(result, entity)
@@ -100,7 +197,7 @@ func (h ContenthubHandler) getAllContenthub(w http.ResponseWriter, r *http.Reque
 
 func (h ContenthubHandler) deleteContenthub(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
-	key := r.URL.Query().Get("key")
+	key := r.PathValue("key")
 	if key == "" {
 		w.WriteHeader(http.StatusBadRequest)
 		return
@@ -116,7 +213,15 @@ func (h ContenthubHandler) deleteContenthub(w http.ResponseWriter, r *http.Reque
 }
 
 // TODO: validate and implement this method correctly
+// getContentHubBySDK serves GET /contenthub/sdk/{key}, the mux refuses it with GET /contenthub/{key}/schema
+// so it is registered as GET /contenthub/{sdk}/{key}. It translates the responses to the locale query
+// param, or to the Accept-Language header
 func (h ContenthubHandler) getContentHubBySDK(w http.ResponseWriter, r *http.Request) {
+	if r.PathValue("sdk") != "sdk" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
 	ctx := r.Context()
 	key := r.PathValue("key")
 	if key == "" {
@@ -130,9 +235,113 @@ func (h ContenthubHandler) getContentHubBySDK(w http.ResponseWriter, r *http.Req
 		return
 	}
 
-	payload := FromDomain(content)
+	locales := ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+	if locale := r.URL.Query().Get("locale"); locale != "" {
+		locales = []string{locale}
+	}
+
+	w.Header().Set("Vary", "Accept-Language")
+
+	payload := FromDomain(content.Localize(locales...))
 	if err := json.NewEncoder(w).Encode(payload); err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 }
+
+// getAllContenthubPreview serves the drafts to the preview token, without ETag as the drafts don't
+// change the revision
+func (h ContenthubHandler) getAllContenthubPreview(w http.ResponseWriter, r *http.Request) {
+	contents, err := h.service.GetAllContentHubPreview(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	var result []Entity
+	for _, entity := range contents {
+		result = append(result, entity)
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
+
+// getSchema serves the JSON Schema of a content, so the clients can generate their types
+func (h ContenthubHandler) getSchema(w http.ResponseWriter, r *http.Request) {
+	schema, err := h.service.GetSchema(r.Context(), r.PathValue("key"))
+	if err != nil {
+		var notFound *errorutils.NotFoundError
+		if errors.As(err, &notFound) || errors.Is(err, ErrNoSchema) || errors.Is(err, ErrUnknownContentType) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/schema+json")
+	w.Write(schema)
+}
+
+func (h ContenthubHandler) putContentType(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	schema, err := io.ReadAll(r.Body)
+	if err != nil || !json.Valid(schema) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("error on decode body"))
+		return
+	}
+
+	if err := h.service.SaveContentType(r.Context(), r.PathValue("name"), schema); err != nil {
+		if errors.Is(err, ErrInvalidSchema) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(err.Error()))
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h ContenthubHandler) getContentType(w http.ResponseWriter, r *http.Request) {
+	contentType, err := h.service.GetContentType(r.Context(), r.PathValue("name"))
+	if err != nil {
+		if errors.Is(err, ErrUnknownContentType) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/schema+json")
+	w.Write(contentType.Schema)
+}
+
+func (h ContenthubHandler) getAllContentTypes(w http.ResponseWriter, r *http.Request) {
+	types, err := h.service.GetAllContentTypes(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	result := make([]ContentType, 0, len(types))
+	for _, contentType := range types {
+		result = append(result, contentType)
+	}
+
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+}
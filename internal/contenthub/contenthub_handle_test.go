@@ -0,0 +1,181 @@
+package contenthub
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+)
+
+const bannerSchema = `{
+	"type": "object",
+	"properties": {"title": {"type": "string"}, "weight": {"type": "integer"}},
+	"required": ["title"]
+}`
+
+func newTestMux(service *Service) *http.ServeMux {
+	mux := http.NewServeMux()
+	for pattern, handler := range NewContenthubHandler(service).GetRoutes() {
+		mux.HandleFunc(pattern, handler)
+	}
+
+	return mux
+}
+
+func TestContenthubHandler_Schema(t *testing.T) {
+	control := gomock.NewController(t)
+	repository := NewMockContentHubRepository(control)
+	typesPath := filepath.Join(t.TempDir(), "contenttypes.json")
+	if err := os.WriteFile(typesPath, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	types := NewContentTypeRepository(typesPath)
+
+	service := NewContentHubService(repository, nil).WithTypes(types)
+	mux := newTestMux(service)
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return w
+	}
+
+	if w := serve(http.MethodPut, "/contenttypes/banner", `{"type": "nope"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("PUT invalid schema status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+
+	if w := serve(http.MethodPut, "/contenttypes/banner", bannerSchema); w.Code != http.StatusNoContent {
+		t.Fatalf("PUT schema status = %d, want %d: %s", w.Code, http.StatusNoContent, w.Body)
+	}
+
+	repository.EXPECT().GetContentHub(gomock.Any(), "home").Return(Entity{}, nil).AnyTimes()
+
+	w := serve(http.MethodPatch, "/contenthub", `{
+		"key": "home",
+		"type": "banner",
+		"balancer_strategy": [
+			{"weight": 50, "response": {"title": "ok"}},
+			{"weight": 50, "response": {"titel": "typo", "weight": 1.5}}
+		]
+	}`)
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("PATCH invalid content status = %d, want %d", w.Code, http.StatusUnprocessableEntity)
+	}
+
+	var report SchemaValidationError
+	if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+		t.Fatalf("decode report: %v", err)
+	}
+
+	fields := map[string]bool{}
+	for _, fieldError := range report.Errors {
+		fields[fieldError.Field] = true
+	}
+
+	if len(report.Errors) != 2 || !fields["/balancer_strategy/1/response"] || !fields["/balancer_strategy/1/response/weight"] {
+		t.Errorf("PATCH report = %+v, want the missing title and the weight of the second response", report)
+	}
+
+	repository.EXPECT().SaveContentHub(gomock.Any(), gomock.Any()).Return(nil)
+	w = serve(http.MethodPatch, "/contenthub", `{"key": "home", "type": "banner", "balancer_strategy": [{"weight": 100, "response": {"title": "ok"}}]}`)
+	if w.Code != http.StatusCreated {
+		t.Errorf("PATCH valid content status = %d, want %d: %s", w.Code, http.StatusCreated, w.Body)
+	}
+
+	var compact bytes.Buffer
+	json.Compact(&compact, []byte(bannerSchema))
+
+	repository.EXPECT().GetContentHub(gomock.Any(), "typed").Return(Entity{Variable: "typed", Type: "banner"}, nil)
+	w = serve(http.MethodGet, "/contenthub/typed/schema", "")
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/schema+json" || w.Body.String() != compact.String() {
+		t.Errorf("GET schema = %d %q %s, want the schema of the type", w.Code, w.Header().Get("Content-Type"), w.Body)
+	}
+
+	// a draft saved before the schema of its type was changed
+	stale := Entity{Variable: "stale", Type: "banner", Unpublished: true, Draft: &Draft{BalancerStrategy: BalancerStrategy{{Weight: 100, Response: map[string]any{"titel": "typo"}}}}}
+	repository.EXPECT().GetContentHub(gomock.Any(), "stale").Return(stale, nil)
+	if w := serve(http.MethodPost, "/contenthub/stale/publish", ""); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("POST publish of an invalid draft status = %d, want %d: %s", w.Code, http.StatusUnprocessableEntity, w.Body)
+	}
+
+	repository.EXPECT().GetContentHub(gomock.Any(), "plain").Return(Entity{Variable: "plain"}, nil)
+	if w := serve(http.MethodGet, "/contenthub/plain/schema", ""); w.Code != http.StatusNotFound {
+		t.Errorf("GET schema without schema status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+
+	repository.EXPECT().GetContentHub(gomock.Any(), "plain").Return(Entity{Variable: "plain", Value: "v"}, nil).Times(2)
+	if w := serve(http.MethodGet, "/contenthub/sdk/plain", ""); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"value":"v"`) {
+		t.Errorf("GET sdk = %d %s, want the content", w.Code, w.Body)
+	}
+
+	if w := serve(http.MethodGet, "/contenthub/plain", ""); w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"value":"v"`) {
+		t.Errorf("GET content = %d %s, want the content", w.Code, w.Body)
+	}
+
+	if w := serve(http.MethodGet, "/contenthub/other/plain", ""); w.Code != http.StatusNotFound {
+		t.Errorf("GET unknown resource status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+
+	// the schema route is more specific than the one of the sdk
+	repository.EXPECT().GetContentHub(gomock.Any(), "sdk").Return(Entity{Variable: "sdk", Type: "banner"}, nil)
+	if w := serve(http.MethodGet, "/contenthub/sdk/schema", ""); w.Code != http.StatusOK || w.Body.String() != compact.String() {
+		t.Errorf("GET schema of sdk = %d %s, want the schema of the type", w.Code, w.Body)
+	}
+}
+
+func TestDto_ToDomain_Schema(t *testing.T) {
+	dto := Dto{
+		Variable: "home",
+		Value:    `{"title": 1}`,
+		Schema:   json.RawMessage(bannerSchema),
+		SessionsStrategies: SessionsStrategies{
+			{SessionID: "default", Response: map[string]any{"title": "ok"}, Locales: Locales{"pt": map[string]any{}}},
+		},
+		BalancerStrategy: BalancerStrategy{{Weight: 100, Response: map[string]any{"title": "ok"}}},
+	}
+
+	_, err := dto.ToDomain()
+	report, ok := err.(*SchemaValidationError)
+	if !ok {
+		t.Fatalf("ToDomain() error = %v, want SchemaValidationError", err)
+	}
+
+	want := []string{"/value/title", "/session_strategy/0/locales/pt"}
+	if len(report.Errors) != len(want) {
+		t.Fatalf("ToDomain() errors = %+v, want %v", report.Errors, want)
+	}
+
+	for i, field := range want {
+		if report.Errors[i].Field != field {
+			t.Errorf("errors[%d].Field = %s, want %s", i, report.Errors[i].Field, field)
+		}
+	}
+
+	dto.Schema = json.RawMessage(`{"type": 1}`)
+	if _, err := dto.ToDomain(); err == nil || !strings.Contains(err.Error(), ErrInvalidSchema.Error()) {
+		t.Errorf("ToDomain() with invalid schema error = %v", err)
+	}
+}
+
+func TestContentHubService_CreateOrUpdate_Schema(t *testing.T) {
+	control := gomock.NewController(t)
+	repository := NewMockContentHubRepository(control)
+
+	ch := Service{repository: repository}
+	content := Entity{Variable: "home", Type: "missing"}
+	if err := ch.CreateOrUpdate(context.Background(), content); err == nil || !strings.Contains(err.Error(), ErrUnknownContentType.Error()) {
+		t.Errorf("CreateOrUpdate() with unknown type error = %v", err)
+	}
+
+	content = Entity{Variable: "home", Schema: json.RawMessage(bannerSchema), SessionsStrategies: SessionsStrategies{{SessionID: "default", Response: "text"}}}
+	if _, ok := ch.CreateOrUpdate(context.Background(), content).(*SchemaValidationError); !ok {
+		t.Error("CreateOrUpdate() with invalid response error is not a SchemaValidationError")
+	}
+}
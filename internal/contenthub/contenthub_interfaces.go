@@ -1,10 +1,30 @@
 package contenthub
 
-import "context"
+import (
+	"context"
+
+	"github.com/IsaacDSC/featureflag/internal/outbox"
+)
 
 type Adapter interface {
 	SaveContentHub(ctx context.Context, input Entity) error
+	// SaveContentHubWithEvent saves input and the event of its change, atomically when the backend allows it
+	SaveContentHubWithEvent(ctx context.Context, input Entity, event outbox.Entry) error
 	GetContentHub(ctx context.Context, key string) (Entity, error)
 	GetAllContentHub(ctx context.Context) (map[string]Entity, error)
 	DeleteContentHub(ctx context.Context, key string) error
+	// DeleteContentHubWithEvent removes key and saves the event of its delete
+	DeleteContentHubWithEvent(ctx context.Context, key string, event outbox.Entry) error
+}
+
+// TypeAdapter stores the content types, by name
+type TypeAdapter interface {
+	SaveContentType(ctx context.Context, input ContentType) error
+	GetContentType(ctx context.Context, name string) (ContentType, error)
+	GetAllContentTypes(ctx context.Context) (map[string]ContentType, error)
+}
+
+// EventWriter saves the events of the repositories that cannot write them together with the change
+type EventWriter interface {
+	Add(ctx context.Context, entry outbox.Entry) error
 }
@@ -5,16 +5,19 @@ import (
 	"encoding/json"
 	"os"
 
+	"github.com/IsaacDSC/featureflag/internal/outbox"
 	"github.com/IsaacDSC/featureflag/pkg/errorutils"
 )
 
 type Repository struct {
 	filePathContentHub string
+	events             EventWriter
 }
 
-func NewContentHubRepository(filePathContentHub string) *Repository {
+func NewContentHubRepository(filePathContentHub string, events EventWriter) *Repository {
 	return &Repository{
 		filePathContentHub: filePathContentHub,
+		events:             events,
 	}
 }
 
@@ -33,6 +36,15 @@ func (fr Repository) SaveContentHub(ctx context.Context, input Entity) error {
 	return os.WriteFile(fr.filePathContentHub, b, 0644)
 }
 
+// SaveContentHubWithEvent writes the event after the content, an event is lost if the process stops between both
+func (fr Repository) SaveContentHubWithEvent(ctx context.Context, input Entity, event outbox.Entry) error {
+	if err := fr.SaveContentHub(ctx, input); err != nil {
+		return err
+	}
+
+	return fr.events.Add(ctx, event)
+}
+
 func (fr Repository) GetContentHub(ctx context.Context, key string) (Entity, error) {
 	b, err := os.ReadFile(fr.filePathContentHub)
 	if err != nil {
@@ -88,3 +100,12 @@ func (fr Repository) DeleteContentHub(ctx context.Context, key string) error {
 
 	return os.WriteFile(fr.filePathContentHub, b, 0644)
 }
+
+// DeleteContentHubWithEvent writes the event after removing the content, an event is lost if the process stops between both
+func (fr Repository) DeleteContentHubWithEvent(ctx context.Context, key string, event outbox.Entry) error {
+	if err := fr.DeleteContentHub(ctx, key); err != nil {
+		return err
+	}
+
+	return fr.events.Add(ctx, event)
+}
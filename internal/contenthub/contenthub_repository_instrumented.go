@@ -0,0 +1,62 @@
+package contenthub
+
+import (
+	"context"
+
+	"github.com/IsaacDSC/featureflag/internal/outbox"
+	"github.com/IsaacDSC/featureflag/pkg/telemetry"
+)
+
+const instrumentedCollection = "contenthubs"
+
+type instrumentedRepository struct {
+	repository Adapter
+	backend    string
+}
+
+// NewInstrumentedRepository records a span, the duration and the errors of every operation of repository, backend is its db.system
+func NewInstrumentedRepository(repository Adapter, backend string) Adapter {
+	return instrumentedRepository{repository: repository, backend: backend}
+}
+
+func (ir instrumentedRepository) SaveContentHub(ctx context.Context, input Entity) (err error) {
+	ctx, op := telemetry.StartRepository(ctx, ir.backend, instrumentedCollection, "SaveContentHub")
+	defer func() { op.End(ctx, err) }()
+
+	return ir.repository.SaveContentHub(ctx, input)
+}
+
+func (ir instrumentedRepository) SaveContentHubWithEvent(ctx context.Context, input Entity, event outbox.Entry) (err error) {
+	ctx, op := telemetry.StartRepository(ctx, ir.backend, instrumentedCollection, "SaveContentHubWithEvent")
+	defer func() { op.End(ctx, err) }()
+
+	return ir.repository.SaveContentHubWithEvent(ctx, input, event)
+}
+
+func (ir instrumentedRepository) GetContentHub(ctx context.Context, key string) (_ Entity, err error) {
+	ctx, op := telemetry.StartRepository(ctx, ir.backend, instrumentedCollection, "GetContentHub")
+	defer func() { op.End(ctx, err) }()
+
+	return ir.repository.GetContentHub(ctx, key)
+}
+
+func (ir instrumentedRepository) GetAllContentHub(ctx context.Context) (_ map[string]Entity, err error) {
+	ctx, op := telemetry.StartRepository(ctx, ir.backend, instrumentedCollection, "GetAllContentHub")
+	defer func() { op.End(ctx, err) }()
+
+	return ir.repository.GetAllContentHub(ctx)
+}
+
+func (ir instrumentedRepository) DeleteContentHub(ctx context.Context, key string) (err error) {
+	ctx, op := telemetry.StartRepository(ctx, ir.backend, instrumentedCollection, "DeleteContentHub")
+	defer func() { op.End(ctx, err) }()
+
+	return ir.repository.DeleteContentHub(ctx, key)
+}
+
+func (ir instrumentedRepository) DeleteContentHubWithEvent(ctx context.Context, key string, event outbox.Entry) (err error) {
+	ctx, op := telemetry.StartRepository(ctx, ir.backend, instrumentedCollection, "DeleteContentHubWithEvent")
+	defer func() { op.End(ctx, err) }()
+
+	return ir.repository.DeleteContentHubWithEvent(ctx, key, event)
+}
@@ -8,6 +8,7 @@ import (
 	context "context"
 	reflect "reflect"
 
+	outbox "github.com/IsaacDSC/featureflag/internal/outbox"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -48,6 +49,20 @@ func (mr *MockContentHubRepositoryMockRecorder) DeleteContentHub(ctx, key interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteContentHub", reflect.TypeOf((*MockContentHubRepository)(nil).DeleteContentHub), ctx, key)
 }
 
+// DeleteContentHubWithEvent mocks base method.
+func (m *MockContentHubRepository) DeleteContentHubWithEvent(ctx context.Context, key string, event outbox.Entry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteContentHubWithEvent", ctx, key, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteContentHubWithEvent indicates an expected call of DeleteContentHubWithEvent.
+func (mr *MockContentHubRepositoryMockRecorder) DeleteContentHubWithEvent(ctx, key, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteContentHubWithEvent", reflect.TypeOf((*MockContentHubRepository)(nil).DeleteContentHubWithEvent), ctx, key, event)
+}
+
 // GetAllContentHub mocks base method.
 func (m *MockContentHubRepository) GetAllContentHub(ctx context.Context) (map[string]Entity, error) {
 	m.ctrl.T.Helper()
@@ -91,3 +106,17 @@ func (mr *MockContentHubRepositoryMockRecorder) SaveContentHub(ctx, input interf
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveContentHub", reflect.TypeOf((*MockContentHubRepository)(nil).SaveContentHub), ctx, input)
 }
+
+// SaveContentHubWithEvent mocks base method.
+func (m *MockContentHubRepository) SaveContentHubWithEvent(ctx context.Context, input Entity, event outbox.Entry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveContentHubWithEvent", ctx, input, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveContentHubWithEvent indicates an expected call of SaveContentHubWithEvent.
+func (mr *MockContentHubRepositoryMockRecorder) SaveContentHubWithEvent(ctx, input, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveContentHubWithEvent", reflect.TypeOf((*MockContentHubRepository)(nil).SaveContentHubWithEvent), ctx, input, event)
+}
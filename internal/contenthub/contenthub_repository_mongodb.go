@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/IsaacDSC/featureflag/internal/changestream"
+	"github.com/IsaacDSC/featureflag/internal/outbox"
 	"github.com/IsaacDSC/featureflag/pkg/errorutils"
 	"github.com/IsaacDSC/featureflag/pkg/mongodb"
 	"go.mongodb.org/mongo-driver/bson"
@@ -14,7 +16,10 @@ import (
 
 type MongoDBRepository struct {
 	collection *mongo.Collection
+	// events holds the events of the deletes, which have no document to be pushed to
+	events     *outbox.MongoDBRepository
 	timeout    time.Duration
+	skipEvents bool
 }
 
 const (
@@ -31,26 +36,70 @@ func NewMongoDBContentHubRepository(database *mongo.Database) (*MongoDBRepositor
 
 	return &MongoDBRepository{
 		collection: collection,
+		events:     outbox.NewMongoDBRepository(database.Collection(outbox.Collection)),
 		timeout:    10 * time.Second,
 	}, nil
 }
 
+// SkipEvents makes SaveContentHubWithEvent save only the change, when the changes are published from the change stream
+func (mr *MongoDBRepository) SkipEvents() *MongoDBRepository {
+	mr.skipEvents = true
+	return mr
+}
+
+// Changes is the change stream source of the collection, without the drafts: the changes of a draft
+// are not published and the contents are published without their draft
+func (mr *MongoDBRepository) Changes() changestream.Source {
+	source := changestream.NewSource[Entity](mr.collection, "contenthub", outbox.Field, draft)
+
+	return changestream.View(source, Entity.Published).
+		WithSkip(func(event changestream.Event) bool {
+			var content Entity
+			if err := bson.Unmarshal(event.FullDocument, &content); err != nil {
+				return false
+			}
+
+			return content.Unpublished
+		})
+}
+
+// Outbox is the store of the events saved with SaveContentHubWithEvent
+func (mr *MongoDBRepository) Outbox() *outbox.MongoDBRepository {
+	return outbox.NewMongoDBRepository(mr.collection)
+}
+
 func (mr *MongoDBRepository) SaveContentHub(ctx context.Context, input Entity) error {
+	return mr.save(ctx, input, bson.M{})
+}
+
+// SaveContentHubWithEvent pushes the event to the outbox of the content document in the same update
+func (mr *MongoDBRepository) SaveContentHubWithEvent(ctx context.Context, input Entity, event outbox.Entry) error {
+	if mr.skipEvents {
+		return mr.save(ctx, input, bson.M{})
+	}
+
+	return mr.save(ctx, input, bson.M{"$push": bson.M{outbox.Field: event}})
+}
+
+func (mr *MongoDBRepository) save(ctx context.Context, input Entity, update bson.M) error {
 	ctx, cancel := context.WithTimeout(ctx, mr.timeout)
 	defer cancel()
 
 	filter := bson.M{keyIndexModel.String(): input.Variable}
-	update := bson.M{
-		"$set": bson.M{
-			id:               input.ID,
-			key:              input.Variable,
-			value:            input.Value,
-			description:      input.Description,
-			active:           input.Active,
-			createdAt:        input.CreatedAt,
-			sessionStrategy:  input.SessionsStrategies,
-			balancerStrategy: input.BalancerStrategy,
-		},
+	update["$set"] = bson.M{
+		id:               input.ID,
+		key:              input.Variable,
+		value:            input.Value,
+		description:      input.Description,
+		active:           input.Active,
+		createdAt:        input.CreatedAt,
+		sessionStrategy:  input.SessionsStrategies,
+		balancerStrategy: input.BalancerStrategy,
+		revisionField:    input.Revision,
+		draft:            input.Draft,
+		unpublished:      input.Unpublished,
+		schema:           input.Schema,
+		contentType:      input.Type,
 	}
 
 	opts := options.Update().SetUpsert(true)
@@ -122,3 +171,13 @@ func (mr *MongoDBRepository) DeleteContentHub(ctx context.Context, key string) e
 
 	return nil
 }
+
+// DeleteContentHubWithEvent saves the event in the outbox collection after removing the content, also with SkipEvents as the
+// change stream can't tell the key of a deleted document. An event is lost if the process stops between both.
+func (mr *MongoDBRepository) DeleteContentHubWithEvent(ctx context.Context, key string, event outbox.Entry) error {
+	if err := mr.DeleteContentHub(ctx, key); err != nil {
+		return err
+	}
+
+	return mr.events.Add(ctx, event)
+}
@@ -12,7 +12,7 @@ func TestContentHubRepository(t *testing.T) {
 	ts := testrepository.NewSetupRepositoryTest(contenthubPath)
 	ts.Setup()
 	defer ts.TearDown()
-	repo := NewContentHubRepository(contenthubPath)
+	repo := NewContentHubRepository(contenthubPath, nil)
 
 	// Arrange
 	testCases := []struct {
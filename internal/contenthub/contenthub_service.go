@@ -2,66 +2,417 @@ package contenthub
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
+	"github.com/IsaacDSC/featureflag/internal/outbox"
+	"github.com/IsaacDSC/featureflag/internal/revision"
 	"github.com/IsaacDSC/featureflag/pkg/errorutils"
-	"github.com/IsaacDSC/featureflag/pkg/pubsub"
+	"github.com/IsaacDSC/featureflag/pkg/telemetry"
+	"go.opentelemetry.io/otel/attribute"
 )
 
-type Publisher interface {
-	Publish(ctx context.Context, channel string, msg pubsub.Payload) error
+// Notifier is told when an event was saved to the outbox, so it is published without waiting
+type Notifier interface {
+	Notify()
 }
 
+// ErrNoDraft is returned when publishing a content without draft
+var ErrNoDraft = errors.New("contenthub has no draft to publish")
+
+// revisionResource is the resource of the contents in the revision.Store
+const revisionResource = "contenthub"
+
 type Service struct {
-	repository Adapter
-	pub        Publisher
+	repository      Adapter
+	notifier        Notifier
+	revisions       revision.Store
+	requiredLocales []string
+	types           TypeAdapter
 }
 
-func NewContentHubService(repository Adapter, pub Publisher) *Service {
-	return &Service{repository: repository, pub: pub}
+func NewContentHubService(repository Adapter, notifier Notifier) *Service {
+	return &Service{repository: repository, notifier: notifier}
 }
 
-func (ch Service) CreateOrUpdate(ctx context.Context, contenthub Entity) error {
+// WithRevisions stamps the changes with the revisions of revisions, for the ETag of the contents
+func (ch *Service) WithRevisions(revisions revision.Store) *Service {
+	ch.revisions = revisions
+	return ch
+}
+
+func (ch Service) CreateOrUpdate(ctx context.Context, contenthub Entity) (err error) {
+	ctx, span := telemetry.Start(ctx, "contenthub.CreateOrUpdate", attribute.String("key", contenthub.Variable))
+	defer func() { telemetry.End(span, err) }()
+
+	if err := ch.validate(ctx, contenthub); err != nil {
+		return err
+	}
+
 	data, err := ch.repository.GetContentHub(ctx, contenthub.Variable)
 
 	if err != nil {
 		switch err.(type) {
 		case *errorutils.NotFoundError:
-			if err := ch.repository.SaveContentHub(ctx, contenthub); err != nil {
+			return revision.Write(ctx, ch.revisions, revisionResource, func(rev int64) error {
+				contenthub.Revision = rev
+				return ch.repository.SaveContentHub(ctx, contenthub)
+			})
+		default:
+			return err
+		}
+	}
+
+	data.Active = contenthub.Active
+
+	err = revision.Write(ctx, ch.revisions, revisionResource, func(rev int64) error {
+		data.Revision = rev
+
+		event, err := outbox.NewEntry("contenthub", data.Published())
+		if err != nil {
+			return err
+		}
+
+		if err := ch.repository.SaveContentHubWithEvent(ctx, data, event); err != nil {
+			return fmt.Errorf("error on save contenthub: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	ch.notifier.Notify()
+
+	return nil
+}
+
+// WithRequiredLocales makes SaveDraft refuse the contents whose responses have no translation for
+// some of locales, with a MissingTranslationsError
+func (ch *Service) WithRequiredLocales(locales []string) *Service {
+	ch.requiredLocales = locales
+	return ch
+}
+
+// WithTypes stores the content types in types, the contents reference them by name in their Type
+func (ch *Service) WithTypes(types TypeAdapter) *Service {
+	ch.types = types
+	return ch
+}
+
+// SaveDraft writes content as the draft of its key, the published content and the SDKs are not changed
+// until Publish. A new key is saved unpublished.
+func (ch Service) SaveDraft(ctx context.Context, content Entity) (err error) {
+	ctx, span := telemetry.Start(ctx, "contenthub.SaveDraft", attribute.String("key", content.Variable))
+	defer func() { telemetry.End(span, err) }()
+
+	if missing := content.MissingTranslations(ch.requiredLocales); len(missing) > 0 {
+		return &MissingTranslationsError{Key: content.Variable, Missing: missing}
+	}
+
+	draft := NewDraft(content)
+
+	data, err := ch.repository.GetContentHub(ctx, content.Variable)
+	if err != nil {
+		switch err.(type) {
+		case *errorutils.NotFoundError:
+			if err := ch.validate(ctx, content); err != nil {
 				return err
 			}
-			return nil
+
+			return ch.repository.SaveContentHub(ctx, Entity{
+				ID:          content.ID,
+				Variable:    content.Variable,
+				CreatedAt:   content.CreatedAt,
+				Draft:       &draft,
+				Unpublished: true,
+				Schema:      content.Schema,
+				Type:        content.Type,
+			})
 		default:
 			return err
 		}
 	}
 
-	data.Active = contenthub.Active
+	// the schema is not staged, it applies to the draft and the next writes
+	if len(content.Schema) > 0 || content.Type != "" {
+		data.Schema = content.Schema
+		data.Type = content.Type
+	}
+
+	content.Schema = data.Schema
+	content.Type = data.Type
+	if err := ch.validate(ctx, content); err != nil {
+		return err
+	}
+
+	data.Draft = &draft
+
+	return ch.repository.SaveContentHub(ctx, data)
+}
+
+// Publish promotes the draft of key to the published content and sends it to the SDKs
+func (ch Service) Publish(ctx context.Context, key string) (_ Entity, err error) {
+	ctx, span := telemetry.Start(ctx, "contenthub.Publish", attribute.String("key", key))
+	defer func() { telemetry.End(span, err) }()
+
+	data, err := ch.repository.GetContentHub(ctx, key)
+	if err != nil {
+		return Entity{}, err
+	}
+
+	if data.Draft == nil {
+		return Entity{}, ErrNoDraft
+	}
 
-	if err := ch.repository.SaveContentHub(ctx, data); err != nil {
-		return fmt.Errorf("error on save contenthub: %w", err)
+	// the schema of the type may have changed since the draft was saved
+	published := data.Publish()
+	if err := ch.validate(ctx, published); err != nil {
+		return Entity{}, err
 	}
 
-	if err := ch.pub.Publish(ctx, "contenthub", pubsub.NewPayload(data)); err != nil {
-		return fmt.Errorf("error on publisher event writer contenthub: %w", err)
+	err = revision.Write(ctx, ch.revisions, revisionResource, func(rev int64) error {
+		published.Revision = rev
+
+		event, err := outbox.NewEntry("contenthub", published)
+		if err != nil {
+			return err
+		}
+
+		if err := ch.repository.SaveContentHubWithEvent(ctx, published, event); err != nil {
+			return fmt.Errorf("error on publish contenthub: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return Entity{}, err
 	}
 
+	ch.notifier.Notify()
+
+	return published, nil
+}
+
+func (ch Service) RemoveContentHub(ctx context.Context, key string) (err error) {
+	ctx, span := telemetry.Start(ctx, "contenthub.RemoveContentHub", attribute.String("key", key))
+	defer func() { telemetry.End(span, err) }()
+
+	err = revision.Write(ctx, ch.revisions, revisionResource, func(rev int64) error {
+		event, err := outbox.NewEntry(outbox.DeletedChannel("contenthub"), revision.Tombstone{Key: key, Revision: rev})
+		if err != nil {
+			return err
+		}
+
+		return ch.repository.DeleteContentHubWithEvent(ctx, key, event)
+	})
+	if err != nil {
+		return err
+	}
+
+	ch.notifier.Notify()
+
 	return nil
 }
 
-func (ch Service) RemoveContentHub(ctx context.Context, key string) error {
-	return ch.repository.DeleteContentHub(ctx, key)
+func (ch Service) GetAllContentHub(ctx context.Context) (_ map[string]Entity, err error) {
+	ctx, span := telemetry.Start(ctx, "contenthub.GetAllContentHub")
+	defer func() { telemetry.End(span, err) }()
+
+	contents, err := ch.repository.GetAllContentHub(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return published(contents), nil
+}
+
+// GetAllContentHubPreview returns the contents with their drafts applied, including the unpublished ones
+func (ch Service) GetAllContentHubPreview(ctx context.Context) (_ map[string]Entity, err error) {
+	ctx, span := telemetry.Start(ctx, "contenthub.GetAllContentHubPreview")
+	defer func() { telemetry.End(span, err) }()
+
+	contents, err := ch.repository.GetAllContentHub(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	output := make(map[string]Entity, len(contents))
+	for key, content := range contents {
+		output[key] = content.Preview()
+	}
+
+	return output, nil
+}
+
+// GetAllContentHubRevision returns the contents with the revision read before them, revision.Unknown
+// without revisions
+func (ch Service) GetAllContentHubRevision(ctx context.Context) (_ map[string]Entity, _ int64, err error) {
+	ctx, span := telemetry.Start(ctx, "contenthub.GetAllContentHubRevision")
+	defer func() { telemetry.End(span, err) }()
+
+	current := revision.Unknown
+	if ch.revisions != nil {
+		if current, err = ch.revisions.Current(ctx, revisionResource); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	contents, err := ch.repository.GetAllContentHub(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return published(contents), current, nil
 }
 
-func (ch Service) GetAllContentHub(ctx context.Context) (map[string]Entity, error) {
-	return ch.repository.GetAllContentHub(ctx)
+func (ch Service) GetContentHub(ctx context.Context, key string) (_ Entity, err error) {
+	ctx, span := telemetry.Start(ctx, "contenthub.GetContentHub", attribute.String("key", key))
+	defer func() { telemetry.End(span, err) }()
+
+	contenthub, err := ch.repository.GetContentHub(ctx, key)
+	if err != nil {
+		return contenthub, err
+	}
+
+	if contenthub.Unpublished {
+		return Entity{}, errorutils.NewNotFoundError("contenthub")
+	}
+
+	return contenthub.Published(), nil
 }
 
-func (ch Service) GetContentHub(ctx context.Context, key string) (Entity, error) {
+// GetContentHubPreview returns the content of key with its draft applied
+func (ch Service) GetContentHubPreview(ctx context.Context, key string) (_ Entity, err error) {
+	ctx, span := telemetry.Start(ctx, "contenthub.GetContentHubPreview", attribute.String("key", key))
+	defer func() { telemetry.End(span, err) }()
+
 	contenthub, err := ch.repository.GetContentHub(ctx, key)
 	if err != nil {
 		return contenthub, err
 	}
 
-	return contenthub, nil
+	return contenthub.Preview(), nil
+}
+
+// published returns the contents served to the SDKs, the drafts are left out
+func published(contents map[string]Entity) map[string]Entity {
+	output := make(map[string]Entity, len(contents))
+	for key, content := range contents {
+		if !content.Unpublished {
+			output[key] = content.Published()
+		}
+	}
+
+	return output
+}
+
+// SaveContentType saves a JSON Schema for the contents of type name, the schema must compile
+func (ch Service) SaveContentType(ctx context.Context, name string, schema json.RawMessage) (err error) {
+	ctx, span := telemetry.Start(ctx, "contenthub.SaveContentType", attribute.String("type", name))
+	defer func() { telemetry.End(span, err) }()
+
+	if ch.types == nil {
+		return errors.New("content types are not configured")
+	}
+
+	if _, err := CompileSchema(schema); err != nil {
+		return err
+	}
+
+	return ch.types.SaveContentType(ctx, ContentType{Name: name, Schema: schema, UpdatedAt: time.Now()})
+}
+
+func (ch Service) GetContentType(ctx context.Context, name string) (_ ContentType, err error) {
+	ctx, span := telemetry.Start(ctx, "contenthub.GetContentType", attribute.String("type", name))
+	defer func() { telemetry.End(span, err) }()
+
+	if ch.types == nil {
+		return ContentType{}, fmt.Errorf("%w: %s", ErrUnknownContentType, name)
+	}
+
+	contentType, err := ch.types.GetContentType(ctx, name)
+	if err != nil {
+		var notFound *errorutils.NotFoundError
+		if errors.As(err, &notFound) {
+			return ContentType{}, fmt.Errorf("%w: %s", ErrUnknownContentType, name)
+		}
+		return ContentType{}, err
+	}
+
+	return contentType, nil
+}
+
+func (ch Service) GetAllContentTypes(ctx context.Context) (_ map[string]ContentType, err error) {
+	ctx, span := telemetry.Start(ctx, "contenthub.GetAllContentTypes")
+	defer func() { telemetry.End(span, err) }()
+
+	if ch.types == nil {
+		return map[string]ContentType{}, nil
+	}
+
+	return ch.types.GetAllContentTypes(ctx)
+}
+
+// GetSchema returns the schema of the content of key, its own or the one of its type, ErrNoSchema without any
+func (ch Service) GetSchema(ctx context.Context, key string) (_ json.RawMessage, err error) {
+	ctx, span := telemetry.Start(ctx, "contenthub.GetSchema", attribute.String("key", key))
+	defer func() { telemetry.End(span, err) }()
+
+	content, err := ch.repository.GetContentHub(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := ch.schemaOf(ctx, content)
+	if err != nil {
+		return nil, err
+	}
+
+	if schema == nil {
+		return nil, ErrNoSchema
+	}
+
+	return schema, nil
+}
+
+// schemaOf returns the schema of content, nil when it has no schema and no type
+func (ch Service) schemaOf(ctx context.Context, content Entity) (json.RawMessage, error) {
+	if len(content.Schema) > 0 {
+		return content.Schema, nil
+	}
+
+	if content.Type == "" {
+		return nil, nil
+	}
+
+	contentType, err := ch.GetContentType(ctx, content.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	return contentType.Schema, nil
+}
+
+// validate checks the value and the responses of content against its schema, the values that
+// don't match are reported by a SchemaValidationError
+func (ch Service) validate(ctx context.Context, content Entity) error {
+	raw, err := ch.schemaOf(ctx, content)
+	if err != nil || raw == nil {
+		return err
+	}
+
+	schema, err := CompileSchema(raw)
+	if err != nil {
+		return err
+	}
+
+	if errs := ValidateContent(schema, content); len(errs) > 0 {
+		return &SchemaValidationError{Key: content.Variable, Errors: errs}
+	}
+
+	return nil
 }
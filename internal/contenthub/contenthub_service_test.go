@@ -6,6 +6,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/IsaacDSC/featureflag/internal/outbox"
 	"github.com/IsaacDSC/featureflag/pkg/errorutils"
 	"github.com/golang/mock/gomock"
 )
@@ -13,7 +14,7 @@ import (
 func TestContentHubService_CreateOrUpdate(t *testing.T) {
 	control := gomock.NewController(t)
 	repository := NewMockContentHubRepository(control)
-	publisher := NewMockPublisher(control)
+	notifier := NewMockNotifier(control)
 
 	tests := []struct {
 		name       string
@@ -42,8 +43,8 @@ func TestContentHubService_CreateOrUpdate(t *testing.T) {
 				}
 				repository.EXPECT().GetContentHub(gomock.Any(), contenthub.Variable).Return(existing, nil)
 				existing.Active = contenthub.Active
-				repository.EXPECT().SaveContentHub(gomock.Any(), existing).Return(nil)
-				publisher.EXPECT().Publish(gomock.Any(), "contenthub", gomock.Any()).Return(nil)
+				repository.EXPECT().SaveContentHubWithEvent(gomock.Any(), existing, gomock.Any()).Return(nil)
+				notifier.EXPECT().Notify()
 			},
 			contenthub: Entity{
 				Variable: "test1",
@@ -68,7 +69,7 @@ func TestContentHubService_CreateOrUpdate(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			ch := Service{
 				repository: repository,
-				pub:        publisher,
+				notifier:   notifier,
 			}
 			tt.behavior(tt.contenthub)
 			if err := ch.CreateOrUpdate(context.Background(), tt.contenthub); (err != nil) != tt.wantErr {
@@ -81,6 +82,7 @@ func TestContentHubService_CreateOrUpdate(t *testing.T) {
 func TestContentHubService_RemoveContentHub(t *testing.T) {
 	control := gomock.NewController(t)
 	repository := NewMockContentHubRepository(control)
+	notifier := NewMockNotifier(control)
 
 	tests := []struct {
 		name     string
@@ -92,7 +94,14 @@ func TestContentHubService_RemoveContentHub(t *testing.T) {
 			name: "should remove content hub",
 			key:  "test1",
 			behavior: func(key string) {
-				repository.EXPECT().DeleteContentHub(gomock.Any(), key).Return(nil)
+				repository.EXPECT().DeleteContentHubWithEvent(gomock.Any(), key, gomock.Any()).
+					DoAndReturn(func(_ context.Context, key string, event outbox.Entry) error {
+						if event.Channel != "contenthub.deleted" || string(event.Data) != `{"key":"test1","revision":0}` {
+							t.Errorf("DeleteContentHubWithEvent() event = %s %s", event.Channel, event.Data)
+						}
+						return nil
+					})
+				notifier.EXPECT().Notify()
 			},
 			wantErr: false,
 		},
@@ -100,7 +109,7 @@ func TestContentHubService_RemoveContentHub(t *testing.T) {
 			name: "should return error on repository failure",
 			key:  "test1",
 			behavior: func(key string) {
-				repository.EXPECT().DeleteContentHub(gomock.Any(), key).Return(errors.New("repository error"))
+				repository.EXPECT().DeleteContentHubWithEvent(gomock.Any(), key, gomock.Any()).Return(errors.New("repository error"))
 			},
 			wantErr: true,
 		},
@@ -110,6 +119,7 @@ func TestContentHubService_RemoveContentHub(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 		ch := Service{
 			repository: repository,
+			notifier:   notifier,
 		}
 		tt.behavior(tt.key)
 		if err := ch.RemoveContentHub(context.Background(), tt.key); (err != nil) != tt.wantErr {
@@ -221,3 +231,176 @@ func TestContentHubService_GetContentHub(t *testing.T) {
 		})
 	}
 }
+
+func TestContentHubService_SaveDraft(t *testing.T) {
+	control := gomock.NewController(t)
+	repository := NewMockContentHubRepository(control)
+
+	tests := []struct {
+		name     string
+		behavior func(content Entity)
+		content  Entity
+		wantErr  bool
+	}{
+		{
+			name: "should create unpublished content with draft",
+			behavior: func(content Entity) {
+				repository.EXPECT().GetContentHub(gomock.Any(), content.Variable).Return(Entity{}, errorutils.NewNotFoundError("contenthub"))
+				repository.EXPECT().SaveContentHub(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, saved Entity) error {
+					if !saved.Unpublished || saved.Value != "" || saved.Draft == nil || saved.Draft.Value != "new" {
+						t.Errorf("SaveContentHub() saved = %+v, want unpublished with the draft", saved)
+					}
+					return nil
+				})
+			},
+			content: Entity{Variable: "test1", Value: "new", Active: true},
+		},
+		{
+			name: "should keep published content and write draft",
+			behavior: func(content Entity) {
+				existing := Entity{Variable: "test1", Value: "old", Active: true}
+				repository.EXPECT().GetContentHub(gomock.Any(), content.Variable).Return(existing, nil)
+				repository.EXPECT().SaveContentHub(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, saved Entity) error {
+					if saved.Value != "old" || saved.Unpublished || saved.Draft == nil || saved.Draft.Value != "new" {
+						t.Errorf("SaveContentHub() saved = %+v, want published old with the draft", saved)
+					}
+					return nil
+				})
+			},
+			content: Entity{Variable: "test1", Value: "new", Active: true},
+		},
+		{
+			name: "should return error on repository failure",
+			behavior: func(content Entity) {
+				repository.EXPECT().GetContentHub(gomock.Any(), content.Variable).Return(Entity{}, errors.New("repository error"))
+			},
+			content: Entity{Variable: "test1"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch := Service{
+				repository: repository,
+			}
+			tt.behavior(tt.content)
+			if err := ch.SaveDraft(context.Background(), tt.content); (err != nil) != tt.wantErr {
+				t.Errorf("SaveDraft() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestContentHubService_SaveDraft_MissingTranslations(t *testing.T) {
+	control := gomock.NewController(t)
+	repository := NewMockContentHubRepository(control)
+
+	ch := Service{repository: repository, requiredLocales: []string{"pt-BR", "en-US"}}
+	content := Entity{
+		Variable: "test1",
+		BalancerStrategy: BalancerStrategy{
+			{Weight: 100, Response: "hello", Locales: Locales{"pt": "olá"}},
+		},
+	}
+
+	var missing *MissingTranslationsError
+	err := ch.SaveDraft(context.Background(), content)
+	if !errors.As(err, &missing) {
+		t.Fatalf("SaveDraft() error = %v, want MissingTranslationsError", err)
+	}
+
+	want := []MissingTranslation{{Strategy: "balancer_strategy", Index: 0, Locales: []string{"en-US"}}}
+	if missing.Key != "test1" || !reflect.DeepEqual(missing.Missing, want) {
+		t.Errorf("SaveDraft() missing = %+v, want %+v", missing, want)
+	}
+}
+
+func TestContentHubService_Publish(t *testing.T) {
+	control := gomock.NewController(t)
+	repository := NewMockContentHubRepository(control)
+	notifier := NewMockNotifier(control)
+
+	tests := []struct {
+		name     string
+		behavior func()
+		want     Entity
+		wantErr  error
+	}{
+		{
+			name: "should promote draft",
+			behavior: func() {
+				existing := Entity{Variable: "test1", Value: "old", Unpublished: true, Draft: &Draft{Value: "new", Active: true}}
+				published := Entity{Variable: "test1", Value: "new", Active: true}
+				repository.EXPECT().GetContentHub(gomock.Any(), "test1").Return(existing, nil)
+				repository.EXPECT().SaveContentHubWithEvent(gomock.Any(), published, gomock.Any()).Return(nil)
+				notifier.EXPECT().Notify()
+			},
+			want: Entity{Variable: "test1", Value: "new", Active: true},
+		},
+		{
+			name: "should return ErrNoDraft without draft",
+			behavior: func() {
+				repository.EXPECT().GetContentHub(gomock.Any(), "test1").Return(Entity{Variable: "test1"}, nil)
+			},
+			wantErr: ErrNoDraft,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch := Service{
+				repository: repository,
+				notifier:   notifier,
+			}
+			tt.behavior()
+			got, err := ch.Publish(context.Background(), "test1")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Publish() error = %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Publish() got = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContentHubService_Preview(t *testing.T) {
+	control := gomock.NewController(t)
+	repository := NewMockContentHubRepository(control)
+
+	contents := map[string]Entity{
+		"published": {Variable: "published", Value: "old", Draft: &Draft{Value: "new"}},
+		"draft":     {Variable: "draft", Unpublished: true, Draft: &Draft{Value: "draft"}},
+	}
+	repository.EXPECT().GetAllContentHub(gomock.Any()).Return(contents, nil).Times(2)
+	repository.EXPECT().GetContentHub(gomock.Any(), "draft").Return(contents["draft"], nil).Times(2)
+
+	ch := Service{repository: repository}
+	ctx := context.Background()
+
+	all, err := ch.GetAllContentHub(ctx)
+	if err != nil {
+		t.Fatalf("GetAllContentHub() error = %v", err)
+	}
+	if want := map[string]Entity{"published": {Variable: "published", Value: "old"}}; !reflect.DeepEqual(all, want) {
+		t.Errorf("GetAllContentHub() got = %+v, want %+v", all, want)
+	}
+
+	preview, err := ch.GetAllContentHubPreview(ctx)
+	if err != nil {
+		t.Fatalf("GetAllContentHubPreview() error = %v", err)
+	}
+	if len(preview) != 2 || preview["published"].Value != "new" || preview["draft"].Value != "draft" {
+		t.Errorf("GetAllContentHubPreview() got = %+v, want the drafts applied", preview)
+	}
+
+	if _, err := ch.GetContentHub(ctx, "draft"); err == nil {
+		t.Error("GetContentHub() of an unpublished content error = nil")
+	}
+
+	content, err := ch.GetContentHubPreview(ctx, "draft")
+	if err != nil || content.Value != "draft" {
+		t.Errorf("GetContentHubPreview() = %+v, %v, want the draft", content, err)
+	}
+}
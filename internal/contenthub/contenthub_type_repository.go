@@ -0,0 +1,63 @@
+package contenthub
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	"github.com/IsaacDSC/featureflag/pkg/errorutils"
+)
+
+type TypeRepository struct {
+	filePath string
+}
+
+func NewContentTypeRepository(filePath string) *TypeRepository {
+	return &TypeRepository{filePath: filePath}
+}
+
+func (tr TypeRepository) SaveContentType(ctx context.Context, input ContentType) error {
+	types, err := tr.GetAllContentTypes(ctx)
+	if err != nil {
+		return err
+	}
+
+	types[input.Name] = input
+	b, err := json.Marshal(types)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(tr.filePath, b, 0644)
+}
+
+func (tr TypeRepository) GetContentType(ctx context.Context, name string) (ContentType, error) {
+	types, err := tr.GetAllContentTypes(ctx)
+	if err != nil {
+		return ContentType{}, err
+	}
+
+	if output, ok := types[name]; ok {
+		return output, nil
+	}
+
+	return ContentType{}, errorutils.NewNotFoundError("contenttype")
+}
+
+func (tr TypeRepository) GetAllContentTypes(ctx context.Context) (map[string]ContentType, error) {
+	b, err := os.ReadFile(tr.filePath)
+	if err != nil {
+		return map[string]ContentType{}, err
+	}
+
+	if len(b) == 0 {
+		return map[string]ContentType{}, nil
+	}
+
+	var types map[string]ContentType
+	if err := json.Unmarshal(b, &types); err != nil {
+		return map[string]ContentType{}, err
+	}
+
+	return types, nil
+}
@@ -0,0 +1,86 @@
+package contenthub
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/IsaacDSC/featureflag/pkg/errorutils"
+	"github.com/IsaacDSC/featureflag/pkg/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type TypeMongoDBRepository struct {
+	collection *mongo.Collection
+	timeout    time.Duration
+}
+
+const (
+	typeCollectionName = mongodb.CollectionName("contenttypes")
+	nameIndexModel     = mongodb.IndexModel("name")
+)
+
+func NewMongoDBContentTypeRepository(database *mongo.Database) (*TypeMongoDBRepository, error) {
+	collection := database.Collection(typeCollectionName.String())
+	if err := mongodb.CreateUniqueIndex(collection, nameIndexModel); err != nil {
+		return nil, fmt.Errorf("error on create index: %w", err)
+	}
+
+	return &TypeMongoDBRepository{
+		collection: collection,
+		timeout:    10 * time.Second,
+	}, nil
+}
+
+func (tr *TypeMongoDBRepository) SaveContentType(ctx context.Context, input ContentType) error {
+	ctx, cancel := context.WithTimeout(ctx, tr.timeout)
+	defer cancel()
+
+	filter := bson.M{nameIndexModel.String(): input.Name}
+	_, err := tr.collection.ReplaceOne(ctx, filter, input, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (tr *TypeMongoDBRepository) GetContentType(ctx context.Context, name string) (ContentType, error) {
+	ctx, cancel := context.WithTimeout(ctx, tr.timeout)
+	defer cancel()
+
+	var output ContentType
+	err := tr.collection.FindOne(ctx, bson.M{nameIndexModel.String(): name}).Decode(&output)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return ContentType{}, errorutils.NewNotFoundError("contenttype")
+		}
+		return ContentType{}, err
+	}
+
+	return output, nil
+}
+
+func (tr *TypeMongoDBRepository) GetAllContentTypes(ctx context.Context) (map[string]ContentType, error) {
+	ctx, cancel := context.WithTimeout(ctx, tr.timeout)
+	defer cancel()
+
+	cursor, err := tr.collection.Find(ctx, bson.M{})
+	if err != nil {
+		return map[string]ContentType{}, err
+	}
+	defer cursor.Close(ctx)
+
+	output := make(map[string]ContentType)
+	for cursor.Next(ctx) {
+		var contentType ContentType
+		if err := cursor.Decode(&contentType); err != nil {
+			return map[string]ContentType{}, err
+		}
+		output[contentType.Name] = contentType
+	}
+
+	if err := cursor.Err(); err != nil {
+		return map[string]ContentType{}, err
+	}
+
+	return output, nil
+}
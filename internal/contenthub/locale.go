@@ -0,0 +1,173 @@
+package contenthub
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Locales are the translations of a response by locale, as pt-BR, the response itself is the default
+type Locales map[string]any
+
+// Chain returns the locales tried for locale, from the most specific one: pt-BR → pt.
+// The underscore is accepted as separator, pt_BR is pt-BR.
+func Chain(locale string) []string {
+	locale = strings.Trim(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"), "-")
+	if locale == "" {
+		return nil
+	}
+
+	var chain []string
+	for {
+		chain = append(chain, locale)
+
+		i := strings.LastIndex(locale, "-")
+		if i < 0 {
+			return chain
+		}
+		locale = locale[:i]
+	}
+}
+
+// Lookup returns the translation of the first locale of the chain of locale, the match is case insensitive
+func (l Locales) Lookup(locale string) (any, bool) {
+	for _, candidate := range Chain(locale) {
+		for tag, response := range l {
+			if strings.EqualFold(tag, candidate) {
+				return response, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// Localize returns the translation of the first preferred locale found, response when there is none
+func Localize(response any, locales Locales, preferred ...string) any {
+	for _, locale := range preferred {
+		if translation, ok := locales.Lookup(locale); ok {
+			return translation
+		}
+	}
+
+	return response
+}
+
+// ParseAcceptLanguage returns the locales of an Accept-Language header ordered by their quality,
+// the wildcard and the locales with q=0 are left out
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		locale  string
+		quality float64
+	}
+
+	var locales []weighted
+	for _, part := range strings.Split(header, ",") {
+		locale, params, _ := strings.Cut(part, ";")
+		locale = strings.TrimSpace(locale)
+		if locale == "" || locale == "*" {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+
+		if quality > 0 {
+			locales = append(locales, weighted{locale, quality})
+		}
+	}
+
+	sort.SliceStable(locales, func(i, j int) bool { return locales[i].quality > locales[j].quality })
+
+	output := make([]string, len(locales))
+	for i, locale := range locales {
+		output[i] = locale.locale
+	}
+
+	return output
+}
+
+// Localize returns the content with the responses of its strategies translated to the first preferred
+// locale found, without the translations
+func (e Entity) Localize(preferred ...string) Entity {
+	sessions := make(SessionsStrategies, len(e.SessionsStrategies))
+	for i, strategy := range e.SessionsStrategies {
+		strategy.Response = Localize(strategy.Response, strategy.Locales, preferred...)
+		strategy.Locales = nil
+		sessions[i] = strategy
+	}
+
+	balancer := make(BalancerStrategy, len(e.BalancerStrategy))
+	for i, strategy := range e.BalancerStrategy {
+		strategy.Response = Localize(strategy.Response, strategy.Locales, preferred...)
+		strategy.Locales = nil
+		balancer[i] = strategy
+	}
+
+	if e.SessionsStrategies != nil {
+		e.SessionsStrategies = sessions
+	}
+
+	if e.BalancerStrategy != nil {
+		e.BalancerStrategy = balancer
+	}
+
+	return e
+}
+
+// MissingTranslation is a response of a strategy without the translation of some required locales
+type MissingTranslation struct {
+	// Strategy is session_strategy or balancer_strategy, Index the position of the response in it
+	Strategy string   `json:"strategy"`
+	Index    int      `json:"index"`
+	Locales  []string `json:"locales"`
+}
+
+// MissingTranslationsError reports the responses of Key without the translations required
+type MissingTranslationsError struct {
+	Key     string               `json:"key"`
+	Missing []MissingTranslation `json:"missing"`
+}
+
+func (e *MissingTranslationsError) Error() string {
+	return fmt.Sprintf("contenthub %s has %d responses with missing translations", e.Key, len(e.Missing))
+}
+
+// MissingTranslations returns the responses without a translation for some of the required locales.
+// A locale is translated when its fallback chain finds a translation, pt-BR is translated by pt.
+func (e Entity) MissingTranslations(required []string) []MissingTranslation {
+	if len(required) == 0 {
+		return nil
+	}
+
+	var missing []MissingTranslation
+	check := func(strategy string, index int, locales Locales) {
+		var untranslated []string
+		for _, locale := range required {
+			if _, ok := locales.Lookup(locale); !ok {
+				untranslated = append(untranslated, locale)
+			}
+		}
+
+		if len(untranslated) > 0 {
+			missing = append(missing, MissingTranslation{Strategy: strategy, Index: index, Locales: untranslated})
+		}
+	}
+
+	for i, strategy := range e.SessionsStrategies {
+		check(sessionStrategy, i, strategy.Locales)
+	}
+
+	for i, strategy := range e.BalancerStrategy {
+		check(balancerStrategy, i, strategy.Locales)
+	}
+
+	return missing
+}
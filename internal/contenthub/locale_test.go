@@ -0,0 +1,120 @@
+package contenthub
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestChain(t *testing.T) {
+	tests := map[string][]string{
+		"pt-BR":      {"pt-BR", "pt"},
+		"pt_BR":      {"pt-BR", "pt"},
+		"zh-Hant-TW": {"zh-Hant-TW", "zh-Hant", "zh"},
+		"es":         {"es"},
+		" ":          nil,
+	}
+
+	for locale, want := range tests {
+		if got := Chain(locale); !reflect.DeepEqual(got, want) {
+			t.Errorf("Chain(%q) = %v, want %v", locale, got, want)
+		}
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	locales := Locales{"pt": "olá", "en-US": "hi", "ES-es": "hola"}
+
+	tests := []struct {
+		name      string
+		preferred []string
+		want      any
+	}{
+		{name: "exact locale", preferred: []string{"en-US"}, want: "hi"},
+		{name: "falls back to the language", preferred: []string{"pt-BR"}, want: "olá"},
+		{name: "case insensitive", preferred: []string{"es-ES"}, want: "hola"},
+		{name: "next preferred locale", preferred: []string{"fr-FR", "pt"}, want: "olá"},
+		{name: "falls back to the default", preferred: []string{"fr-FR"}, want: "default"},
+		{name: "without locale", want: "default"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Localize("default", locales, tt.preferred...); got != tt.want {
+				t.Errorf("Localize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := map[string][]string{
+		"pt-BR,pt;q=0.9,en;q=0.8":  {"pt-BR", "pt", "en"},
+		"en;q=0.5, es-ES, *;q=0.1": {"es-ES", "en"},
+		"fr;q=0, de":               {"de"},
+		"":                         {},
+	}
+
+	for header, want := range tests {
+		if got := ParseAcceptLanguage(header); !reflect.DeepEqual(got, want) {
+			t.Errorf("ParseAcceptLanguage(%q) = %v, want %v", header, got, want)
+		}
+	}
+}
+
+func TestEntity_Localize(t *testing.T) {
+	content := Entity{
+		Variable: "banner",
+		SessionsStrategies: SessionsStrategies{
+			{SessionID: "default", Response: "welcome", Locales: Locales{"pt": "bem-vindo"}},
+		},
+		BalancerStrategy: BalancerStrategy{
+			{Weight: 100, Response: "buy", Locales: Locales{"es": "comprar"}},
+		},
+	}
+
+	got := content.Localize("pt-BR")
+	if got.SessionsStrategies[0].Response != "bem-vindo" || got.SessionsStrategies[0].Locales != nil {
+		t.Errorf("session strategy = %+v, want translated without locales", got.SessionsStrategies[0])
+	}
+
+	if got.BalancerStrategy[0].Response != "buy" {
+		t.Errorf("balancer strategy = %+v, want the default response", got.BalancerStrategy[0])
+	}
+
+	if content.SessionsStrategies[0].Response != "welcome" {
+		t.Error("Localize() changed the content it was called on")
+	}
+
+	if got := content.BalancerStrategy.Distribution("es-ES"); got != "comprar" {
+		t.Errorf("Distribution(es-ES) = %v, want comprar", got)
+	}
+
+	if got := content.SessionsStrategies.Val("unknown", "pt-BR"); got != "bem-vindo" {
+		t.Errorf("Val(unknown, pt-BR) = %v, want bem-vindo", got)
+	}
+}
+
+func TestEntity_MissingTranslations(t *testing.T) {
+	content := Entity{
+		SessionsStrategies: SessionsStrategies{
+			{SessionID: "default", Response: "welcome", Locales: Locales{"pt": "bem-vindo", "es-ES": "bienvenido"}},
+		},
+		BalancerStrategy: BalancerStrategy{
+			{Weight: 50, Response: "a", Locales: Locales{"pt-BR": "a"}},
+			{Weight: 50, Response: "b"},
+		},
+	}
+
+	if missing := content.MissingTranslations(nil); missing != nil {
+		t.Errorf("MissingTranslations(nil) = %v, want nil", missing)
+	}
+
+	want := []MissingTranslation{
+		{Strategy: balancerStrategy, Index: 0, Locales: []string{"es-ES"}},
+		{Strategy: balancerStrategy, Index: 1, Locales: []string{"pt-BR", "es-ES"}},
+	}
+
+	if got := content.MissingTranslations([]string{"pt-BR", "es-ES"}); !reflect.DeepEqual(got, want) {
+		t.Errorf("MissingTranslations() = %+v, want %+v", got, want)
+	}
+}
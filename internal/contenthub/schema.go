@@ -0,0 +1,164 @@
+package contenthub
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+var (
+	ErrInvalidSchema      = errors.New("invalid json schema")
+	ErrUnknownContentType = errors.New("unknown content type")
+	ErrNoSchema           = errors.New("contenthub has no schema")
+)
+
+// ContentType is a JSON Schema shared by the contents that reference it by Name in their Type
+type ContentType struct {
+	Name      string          `json:"name" bson:"name"`
+	Schema    json.RawMessage `json:"schema" bson:"schema"`
+	UpdatedAt time.Time       `json:"updated_at" bson:"updated_at"`
+}
+
+// FieldError is a value that doesn't match the schema, Field is its JSON pointer in the content,
+// as /balancer_strategy/0/response/title
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// SchemaValidationError reports the values of Key that don't match its schema
+type SchemaValidationError struct {
+	Key    string       `json:"key"`
+	Errors []FieldError `json:"errors"`
+}
+
+func (e *SchemaValidationError) Error() string {
+	return fmt.Sprintf("contenthub %s has %d values not matching its schema", e.Key, len(e.Errors))
+}
+
+// CompileSchema compiles a JSON Schema (2020-12 unless $schema says otherwise). The $ref to other
+// documents are refused, a schema is self-contained.
+func CompileSchema(schema json.RawMessage) (*jsonschema.Schema, error) {
+	compiler := jsonschema.NewCompiler()
+	compiler.LoadURL = func(url string) (io.ReadCloser, error) {
+		return nil, fmt.Errorf("external $ref %s not allowed", url)
+	}
+
+	if err := compiler.AddResource("schema.json", bytes.NewReader(schema)); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchema, err)
+	}
+
+	compiled, err := compiler.Compile("schema.json")
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSchema, err)
+	}
+
+	return compiled, nil
+}
+
+// ValidateContent checks the value of content, when it has one, and every response of its strategies
+// with their translations against schema. A value that isn't JSON is checked as a string.
+func ValidateContent(schema *jsonschema.Schema, content Entity) []FieldError {
+	var errs []FieldError
+	check := func(field string, value any) {
+		normalized, err := normalize(value)
+		if err != nil {
+			errs = append(errs, FieldError{Field: field, Message: err.Error()})
+			return
+		}
+
+		errs = append(errs, fieldErrors(field, schema.Validate(normalized))...)
+	}
+
+	if content.Value != "" {
+		var v any = content.Value
+		if json.Valid([]byte(content.Value)) {
+			v = json.RawMessage(content.Value)
+		}
+		check("/"+value, v)
+	}
+
+	for i, strategy := range content.SessionsStrategies {
+		prefix := "/" + sessionStrategy + "/" + strconv.Itoa(i)
+		check(prefix+"/response", strategy.Response)
+		for _, locale := range sortedLocales(strategy.Locales) {
+			check(prefix+"/locales/"+locale, strategy.Locales[locale])
+		}
+	}
+
+	for i, strategy := range content.BalancerStrategy {
+		prefix := "/" + balancerStrategy + "/" + strconv.Itoa(i)
+		check(prefix+"/response", strategy.Response)
+		for _, locale := range sortedLocales(strategy.Locales) {
+			check(prefix+"/locales/"+locale, strategy.Locales[locale])
+		}
+	}
+
+	return errs
+}
+
+// normalize turns value into the types of encoding/json, which the validator expects
+func normalize(value any) (any, error) {
+	b, ok := value.(json.RawMessage)
+	if !ok {
+		var err error
+		if b, err = json.Marshal(value); err != nil {
+			return nil, err
+		}
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+
+	var output any
+	if err := decoder.Decode(&output); err != nil {
+		return nil, err
+	}
+
+	return output, nil
+}
+
+// fieldErrors flattens the causes of err, only the leaves tell which value failed
+func fieldErrors(field string, err error) []FieldError {
+	var validation *jsonschema.ValidationError
+	if err == nil {
+		return nil
+	}
+
+	if !errors.As(err, &validation) {
+		return []FieldError{{Field: field, Message: err.Error()}}
+	}
+
+	var errs []FieldError
+	var walk func(ve *jsonschema.ValidationError)
+	walk = func(ve *jsonschema.ValidationError) {
+		if len(ve.Causes) == 0 {
+			errs = append(errs, FieldError{Field: field + ve.InstanceLocation, Message: ve.Message})
+			return
+		}
+
+		for _, cause := range ve.Causes {
+			walk(cause)
+		}
+	}
+	walk(validation)
+
+	return errs
+}
+
+func sortedLocales(locales Locales) []string {
+	output := make([]string, 0, len(locales))
+	for locale := range locales {
+		output = append(output, locale)
+	}
+	sort.Strings(output)
+
+	return output
+}
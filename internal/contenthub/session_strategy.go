@@ -5,8 +5,9 @@ import (
 )
 
 type SessionStrategy struct {
-	SessionID string `json:"session_id" bson:"session_id"`
-	Response  any    `json:"response" bson:"response"`
+	SessionID string  `json:"session_id" bson:"session_id"`
+	Response  any     `json:"response" bson:"response"`
+	Locales   Locales `json:"locales,omitempty" bson:"locales,omitempty"`
 }
 
 type SessionsStrategies []SessionStrategy
@@ -29,24 +30,26 @@ func (bs *SessionsStrategies) Validate() error {
 	return nil
 }
 
-func (bs SessionsStrategies) Val(sessionID string) any {
+// Val returns the response of sessionID, or of the default session, translated to the first of
+// the locales found
+func (bs SessionsStrategies) Val(sessionID string, locales ...string) any {
 	var found bool
-	var defaultResponse any
-	var result any
+	var defaultResponse SessionStrategy
+	var result SessionStrategy
 	for _, strategy := range bs {
 		if strategy.SessionID == sessionID {
 			found = true
-			result = strategy.Response
+			result = strategy
 			break
 		}
 		if strategy.SessionID == "default" {
-			defaultResponse = strategy.Response
+			defaultResponse = strategy
 		}
 	}
 
 	if !found {
-		return defaultResponse
+		result = defaultResponse
 	}
 
-	return result
+	return Localize(result.Response, result.Locales, locales...)
 }
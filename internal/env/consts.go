@@ -2,5 +2,20 @@ package env
 
 const FilePath = "featureflags.json"
 const FilePathContentHub = "contenthub.json"
+const FilePathWebhook = "webhooks.json"
+const FilePathOutbox = "outbox.json"
+const FilePathRevisions = "revisions.json"
+const FilePathContentTypes = "contenttypes.json"
 
-var FilesPaths []string = []string{FilePath, FilePathContentHub}
+var FilesPaths []string = []string{FilePath, FilePathContentHub, FilePathWebhook, FilePathOutbox, FilePathRevisions, FilePathContentTypes}
+
+const (
+	PubSubRedis  = "redis"
+	PubSubStream = "stream"
+	PubSubMemory = "memory"
+)
+
+const (
+	EventSourceOutbox       = "outbox"
+	EventSourceChangeStream = "changestream"
+)
@@ -9,13 +9,35 @@ import (
 )
 
 type Environment struct {
-	SecretKey         string        `env:"SECRET_KEY" env-required:"true"`
-	ServiceClientAT   string        `env:"SERVICE_CLIENT_AT" env-required:"true"`
-	SDKClientAT       string        `env:"SDK_CLIENT_AT" env-required:"true"`
-	RepositoryType    string        `env:"REPOSITORY_TYPE" env-default:"jsonfile"`
-	MongoDBURI        string        `env:"MONGODB_URI"`
-	MongoDBName       string        `env:"MONGODB_NAME"`
-	MongoDbIdxTimeout time.Duration `env:"MONGODB_IDX_TIMEOUT" env-default:"2s"`
+	SecretKey          string        `env:"SECRET_KEY" env-required:"true"`
+	ServiceClientAT    string        `env:"SERVICE_CLIENT_AT" env-required:"true"`
+	SDKClientAT        string        `env:"SDK_CLIENT_AT" env-required:"true"`
+	PreviewClientAT    string        `env:"PREVIEW_CLIENT_AT"`
+	RepositoryType     string        `env:"REPOSITORY_TYPE" env-default:"jsonfile"`
+	MongoDBURI         string        `env:"MONGODB_URI"`
+	MongoDBName        string        `env:"MONGODB_NAME"`
+	MongoDbIdxTimeout  time.Duration `env:"MONGODB_IDX_TIMEOUT" env-default:"2s"`
+	PubSubType         string        `env:"PUBSUB_TYPE" env-default:"redis"`
+	StreamMaxLen       int64         `env:"PUBSUB_STREAM_MAXLEN" env-default:"1000"`
+	SSEHeartbeat       time.Duration `env:"SSE_HEARTBEAT" env-default:"15s"`
+	SSEClientBuffer    int           `env:"SSE_CLIENT_BUFFER" env-default:"64"`
+	SSESlowConsumer    string        `env:"SSE_SLOW_CONSUMER" env-default:"drop"`
+	SSEHistory         int           `env:"SSE_HISTORY" env-default:"256"`
+	GRPCPort           string        `env:"GRPC_PORT" env-default:"3001"`
+	WebhookMaxAttempts int           `env:"WEBHOOK_MAX_ATTEMPTS" env-default:"5"`
+	WebhookBackoff     time.Duration `env:"WEBHOOK_BACKOFF" env-default:"1s"`
+	WebhookMaxBackoff  time.Duration `env:"WEBHOOK_MAX_BACKOFF" env-default:"5m"`
+	WebhookTimeout     time.Duration `env:"WEBHOOK_TIMEOUT" env-default:"10s"`
+	EventSource        string        `env:"EVENT_SOURCE" env-default:"outbox"`
+	OutboxInterval     time.Duration `env:"OUTBOX_INTERVAL" env-default:"1s"`
+	OutboxBackoff      time.Duration `env:"OUTBOX_BACKOFF" env-default:"500ms"`
+	OutboxMaxBackoff   time.Duration `env:"OUTBOX_MAX_BACKOFF" env-default:"30s"`
+	OutboxBatchSize    int           `env:"OUTBOX_BATCH_SIZE" env-default:"100"`
+	OtelExporter       string        `env:"OTEL_EXPORTER" env-default:"none"`
+	OtelServiceName    string        `env:"OTEL_SERVICE_NAME" env-default:"featureflag"`
+	MetricsAuth        string        `env:"METRICS_AUTH" env-default:"none"`
+	MetricsToken       string        `env:"METRICS_TOKEN"`
+	RequiredLocales    []string      `env:"CONTENTHUB_REQUIRED_LOCALES" env-separator:","`
 }
 
 var (
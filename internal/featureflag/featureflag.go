@@ -14,6 +14,15 @@ type Entity struct {
 	Strategies strategy.Strategy `json:"strategy" bson:"strategy"`
 	Active     bool              `json:"active" bson:"active"`
 	CreatedAt  time.Time         `json:"created_at" bson:"created_at"`
+	// Revision is the revision of the last change made through the service
+	Revision int64 `json:"revision" bson:"revision"`
+}
+
+// Changes are the flags changed since a revision, Revision is the one to ask next
+type Changes struct {
+	Revision int64    `json:"revision"`
+	Changed  []Entity `json:"changed"`
+	Deleted  []string `json:"deleted"`
 }
 
 func (ff Entity) SetStrategy(sessionID string) Entity {
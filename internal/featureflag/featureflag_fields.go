@@ -6,4 +6,5 @@ const (
 	strategies  = "strategy"
 	active    = "active"
 	createdAt = "created_at"
+	revisionField = "revision"
 )
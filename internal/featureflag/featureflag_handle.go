@@ -2,10 +2,13 @@ package featureflag
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
 
+	"github.com/IsaacDSC/featureflag/internal/revision"
 	"github.com/IsaacDSC/featureflag/pkg/errorutils"
 	"github.com/IsaacDSC/featureflag/pkg/middlewares"
 )
@@ -24,6 +27,7 @@ func NewFeatureFlagHandler(service *Service) *Handler {
 		fmt.Sprintf("PATCH %s", featureFlagPrefix):         handler.createOrUpdate,
 		fmt.Sprintf("DELETE %s/{key}", featureFlagPrefix):  middlewares.Authorization(middlewares.CheckPermission(handler.delete, middlewares.USERNAME_SERVICE)),
 		fmt.Sprintf("GET %ss", featureFlagPrefix):          handler.getAll,
+		fmt.Sprintf("GET %ss/changes", featureFlagPrefix):  handler.changes,
 		fmt.Sprintf("GET %s/{key}", featureFlagPrefix):     middlewares.Authorization(middlewares.CheckPermission(handler.get, middlewares.USERNAME_SERVICE)),
 		fmt.Sprintf("GET %s/sdk/{key}", featureFlagPrefix): middlewares.Authorization(middlewares.CheckPermission(handler.getFeatureFlagBySDK, middlewares.USERNAME_SDK)),
 	}
@@ -156,13 +160,22 @@ func (h *Handler) getAll(w http.ResponseWriter, r *http.Request) {
 	// TODO: Possibilitar receber um parametro de query para filtrar por status
 	// status := r.URL.Query().Get("status")
 
-	database, err := h.service.GetAllFeatureFlag(ctx)
+	database, current, err := h.service.GetAllFeatureFlagRevision(ctx)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		w.Write([]byte(err.Error()))
 		return
 	}
 
+	if current != revision.Unknown {
+		w.Header().Set("ETag", revision.ETag(current))
+	}
+
+	if revision.NotModified(r, current) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
 	var result []Entity
 	for _, entity := range database {
 		result = append(result, entity)
@@ -177,3 +190,43 @@ func (h *Handler) getAll(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	w.Write(b)
 }
+
+// changes returns the flags changed since the revision of the previous response, or of the ETag of getAll
+func (h *Handler) changes(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	since, err := strconv.ParseInt(r.URL.Query().Get("since"), 10, 64)
+	if err != nil || since < 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("required since query param with a revision"))
+		return
+	}
+
+	changes, err := h.service.GetChanges(ctx, since)
+	if err != nil {
+		if errors.Is(err, revision.ErrDisabled) {
+			w.WriteHeader(http.StatusNotImplemented)
+			return
+		}
+
+		// the keys deleted since were pruned, the client loads all flags again
+		if errors.Is(err, revision.ErrResyncRequired) {
+			w.WriteHeader(http.StatusGone)
+			w.Write([]byte(err.Error()))
+			return
+		}
+
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	b, err := json.Marshal(changes)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
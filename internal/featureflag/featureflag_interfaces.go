@@ -1,10 +1,23 @@
 package featureflag
 
-import "context"
+import (
+	"context"
+
+	"github.com/IsaacDSC/featureflag/internal/outbox"
+)
 
 type Adapter interface {
 	SaveFF(ctx context.Context, input Entity) error
+	// SaveFFWithEvent saves input and the event of its change, atomically when the backend allows it
+	SaveFFWithEvent(ctx context.Context, input Entity, event outbox.Entry) error
 	GetAllFF(ctx context.Context) (map[string]Entity, error)
 	GetFF(ctx context.Context, key string) (Entity, error)
 	DeleteFF(ctx context.Context, key string) error
+	// DeleteFFWithEvent removes key and saves the event of its delete
+	DeleteFFWithEvent(ctx context.Context, key string, event outbox.Entry) error
+}
+
+// EventWriter saves the events of the repositories that cannot write them together with the change
+type EventWriter interface {
+	Add(ctx context.Context, entry outbox.Entry) error
 }
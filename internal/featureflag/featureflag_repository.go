@@ -6,13 +6,16 @@ import (
 	"os"
 
 	"github.com/IsaacDSC/featureflag/internal/env"
+	"github.com/IsaacDSC/featureflag/internal/outbox"
 	"github.com/IsaacDSC/featureflag/pkg/errorutils"
 )
 
-type Repository struct{}
+type Repository struct {
+	events EventWriter
+}
 
-func NewFeatureFlagRepository() *Repository {
-	return &Repository{}
+func NewFeatureFlagRepository(events EventWriter) *Repository {
+	return &Repository{events: events}
 }
 
 func (fr Repository) SaveFF(ctx context.Context, input Entity) error {
@@ -30,6 +33,15 @@ func (fr Repository) SaveFF(ctx context.Context, input Entity) error {
 	return os.WriteFile(env.FilePath, b, 0644)
 }
 
+// SaveFFWithEvent writes the event after the flag, an event is lost if the process stops between both
+func (fr Repository) SaveFFWithEvent(ctx context.Context, input Entity, event outbox.Entry) error {
+	if err := fr.SaveFF(ctx, input); err != nil {
+		return err
+	}
+
+	return fr.events.Add(ctx, event)
+}
+
 func (fr Repository) GetFF(ctx context.Context, key string) (Entity, error) {
 	b, err := os.ReadFile(env.FilePath)
 	if err != nil {
@@ -85,3 +97,12 @@ func (fr Repository) DeleteFF(ctx context.Context, key string) error {
 
 	return os.WriteFile(env.FilePath, b, 0644)
 }
+
+// DeleteFFWithEvent writes the event after removing the flag, an event is lost if the process stops between both
+func (fr Repository) DeleteFFWithEvent(ctx context.Context, key string, event outbox.Entry) error {
+	if err := fr.DeleteFF(ctx, key); err != nil {
+		return err
+	}
+
+	return fr.events.Add(ctx, event)
+}
@@ -0,0 +1,62 @@
+package featureflag
+
+import (
+	"context"
+
+	"github.com/IsaacDSC/featureflag/internal/outbox"
+	"github.com/IsaacDSC/featureflag/pkg/telemetry"
+)
+
+const instrumentedCollection = "featureflags"
+
+type instrumentedRepository struct {
+	repository Adapter
+	backend    string
+}
+
+// NewInstrumentedRepository records a span, the duration and the errors of every operation of repository, backend is its db.system
+func NewInstrumentedRepository(repository Adapter, backend string) Adapter {
+	return instrumentedRepository{repository: repository, backend: backend}
+}
+
+func (ir instrumentedRepository) SaveFF(ctx context.Context, input Entity) (err error) {
+	ctx, op := telemetry.StartRepository(ctx, ir.backend, instrumentedCollection, "SaveFF")
+	defer func() { op.End(ctx, err) }()
+
+	return ir.repository.SaveFF(ctx, input)
+}
+
+func (ir instrumentedRepository) SaveFFWithEvent(ctx context.Context, input Entity, event outbox.Entry) (err error) {
+	ctx, op := telemetry.StartRepository(ctx, ir.backend, instrumentedCollection, "SaveFFWithEvent")
+	defer func() { op.End(ctx, err) }()
+
+	return ir.repository.SaveFFWithEvent(ctx, input, event)
+}
+
+func (ir instrumentedRepository) GetAllFF(ctx context.Context) (_ map[string]Entity, err error) {
+	ctx, op := telemetry.StartRepository(ctx, ir.backend, instrumentedCollection, "GetAllFF")
+	defer func() { op.End(ctx, err) }()
+
+	return ir.repository.GetAllFF(ctx)
+}
+
+func (ir instrumentedRepository) GetFF(ctx context.Context, key string) (_ Entity, err error) {
+	ctx, op := telemetry.StartRepository(ctx, ir.backend, instrumentedCollection, "GetFF")
+	defer func() { op.End(ctx, err) }()
+
+	return ir.repository.GetFF(ctx, key)
+}
+
+func (ir instrumentedRepository) DeleteFF(ctx context.Context, key string) (err error) {
+	ctx, op := telemetry.StartRepository(ctx, ir.backend, instrumentedCollection, "DeleteFF")
+	defer func() { op.End(ctx, err) }()
+
+	return ir.repository.DeleteFF(ctx, key)
+}
+
+func (ir instrumentedRepository) DeleteFFWithEvent(ctx context.Context, key string, event outbox.Entry) (err error) {
+	ctx, op := telemetry.StartRepository(ctx, ir.backend, instrumentedCollection, "DeleteFFWithEvent")
+	defer func() { op.End(ctx, err) }()
+
+	return ir.repository.DeleteFFWithEvent(ctx, key, event)
+}
@@ -8,6 +8,7 @@ import (
 	context "context"
 	reflect "reflect"
 
+	outbox "github.com/IsaacDSC/featureflag/internal/outbox"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -48,6 +49,20 @@ func (mr *MockFeatureFlagRepositoryMockRecorder) DeleteFF(ctx, key interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFF", reflect.TypeOf((*MockFeatureFlagRepository)(nil).DeleteFF), ctx, key)
 }
 
+// DeleteFFWithEvent mocks base method.
+func (m *MockFeatureFlagRepository) DeleteFFWithEvent(ctx context.Context, key string, event outbox.Entry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFFWithEvent", ctx, key, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFFWithEvent indicates an expected call of DeleteFFWithEvent.
+func (mr *MockFeatureFlagRepositoryMockRecorder) DeleteFFWithEvent(ctx, key, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFFWithEvent", reflect.TypeOf((*MockFeatureFlagRepository)(nil).DeleteFFWithEvent), ctx, key, event)
+}
+
 // GetAllFF mocks base method.
 func (m *MockFeatureFlagRepository) GetAllFF(ctx context.Context) (map[string]Entity, error) {
 	m.ctrl.T.Helper()
@@ -91,3 +106,17 @@ func (mr *MockFeatureFlagRepositoryMockRecorder) SaveFF(ctx, input interface{})
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveFF", reflect.TypeOf((*MockFeatureFlagRepository)(nil).SaveFF), ctx, input)
 }
+
+// SaveFFWithEvent mocks base method.
+func (m *MockFeatureFlagRepository) SaveFFWithEvent(ctx context.Context, input Entity, event outbox.Entry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveFFWithEvent", ctx, input, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveFFWithEvent indicates an expected call of SaveFFWithEvent.
+func (mr *MockFeatureFlagRepositoryMockRecorder) SaveFFWithEvent(ctx, input, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveFFWithEvent", reflect.TypeOf((*MockFeatureFlagRepository)(nil).SaveFFWithEvent), ctx, input, event)
+}
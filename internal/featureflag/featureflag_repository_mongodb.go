@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/IsaacDSC/featureflag/internal/changestream"
+	"github.com/IsaacDSC/featureflag/internal/outbox"
 	"github.com/IsaacDSC/featureflag/pkg/errorutils"
 	"github.com/IsaacDSC/featureflag/pkg/mongodb"
 	"go.mongodb.org/mongo-driver/bson"
@@ -14,7 +16,10 @@ import (
 
 type MongoDBRepository struct {
 	collection *mongo.Collection
+	// events holds the events of the deletes, which have no document to be pushed to
+	events     *outbox.MongoDBRepository
 	timeout    time.Duration
+	skipEvents bool
 }
 
 const (
@@ -32,23 +37,61 @@ func NewMongoDBFeatureFlagRepository(database *mongo.Database) (*MongoDBReposito
 
 	return &MongoDBRepository{
 		collection: collection,
+		events:     outbox.NewMongoDBRepository(database.Collection(outbox.Collection)),
 		timeout:    10 * time.Second,
 	}, nil
 }
 
+// SkipEvents makes SaveFFWithEvent save only the change, when the changes are published from the change stream
+func (mr *MongoDBRepository) SkipEvents() *MongoDBRepository {
+	mr.skipEvents = true
+	return mr
+}
+
+// Changes is the change stream source of the collection, without the counters written on evaluation
+func (mr *MongoDBRepository) Changes() changestream.Source {
+	return changestream.NewSource[Entity](mr.collection, "featureflag", outbox.Field, strategies+".qtdcall").
+		WithSkip(func(event changestream.Event) bool {
+			// the active of a flag with strategy is the result of its last evaluation, saved on every call
+			var flag Entity
+			if err := bson.Unmarshal(event.FullDocument, &flag); err != nil {
+				return false
+			}
+
+			return flag.IsUseStrategy() && event.Only(active, strategies+".qtdcall", outbox.Field)
+		})
+}
+
+// Outbox is the store of the events saved with SaveFFWithEvent
+func (mr *MongoDBRepository) Outbox() *outbox.MongoDBRepository {
+	return outbox.NewMongoDBRepository(mr.collection)
+}
+
 func (mr *MongoDBRepository) SaveFF(ctx context.Context, input Entity) error {
+	return mr.save(ctx, input, bson.M{})
+}
+
+// SaveFFWithEvent pushes the event to the outbox of the flag document in the same update
+func (mr *MongoDBRepository) SaveFFWithEvent(ctx context.Context, input Entity, event outbox.Entry) error {
+	if mr.skipEvents {
+		return mr.save(ctx, input, bson.M{})
+	}
+
+	return mr.save(ctx, input, bson.M{"$push": bson.M{outbox.Field: event}})
+}
+
+func (mr *MongoDBRepository) save(ctx context.Context, input Entity, update bson.M) error {
 	ctx, cancel := context.WithTimeout(ctx, mr.timeout)
 	defer cancel()
 
 	filter := bson.M{flagNameIndexModel.String(): input.FlagName}
-	update := bson.M{
-		"$set": bson.M{
-			id:         input.ID,
-			flagName:   input.FlagName,
-			strategies: input.Strategies,
-			active:     input.Active,
-			createdAt:  input.CreatedAt,
-		},
+	update["$set"] = bson.M{
+		id:            input.ID,
+		flagName:      input.FlagName,
+		strategies:    input.Strategies,
+		active:        input.Active,
+		createdAt:     input.CreatedAt,
+		revisionField: input.Revision,
 	}
 
 	opts := options.Update().SetUpsert(true)
@@ -120,3 +163,13 @@ func (mr *MongoDBRepository) DeleteFF(ctx context.Context, key string) error {
 
 	return nil
 }
+
+// DeleteFFWithEvent saves the event in the outbox collection after removing the flag, also with SkipEvents as the
+// change stream can't tell the key of a deleted document. An event is lost if the process stops between both.
+func (mr *MongoDBRepository) DeleteFFWithEvent(ctx context.Context, key string, event outbox.Entry) error {
+	if err := mr.DeleteFF(ctx, key); err != nil {
+		return err
+	}
+
+	return mr.events.Add(ctx, event)
+}
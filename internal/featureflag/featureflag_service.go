@@ -3,62 +3,185 @@ package featureflag
 import (
 	"context"
 	"fmt"
+	"slices"
 
+	"github.com/IsaacDSC/featureflag/internal/outbox"
+	"github.com/IsaacDSC/featureflag/internal/revision"
 	"github.com/IsaacDSC/featureflag/pkg/errorutils"
-	"github.com/IsaacDSC/featureflag/pkg/pubsub"
+	"github.com/IsaacDSC/featureflag/pkg/telemetry"
+	"go.opentelemetry.io/otel/attribute"
 )
 
-type Publisher interface {
-	Publish(ctx context.Context, channel string, msg pubsub.Payload) error
+// Notifier is told when an event was saved to the outbox, so it is published without waiting
+type Notifier interface {
+	Notify()
 }
 
+// revisionResource is the resource of the flags in the revision.Store
+const revisionResource = "featureflag"
+
 type Service struct {
 	repository Adapter
-	pub        Publisher
+	notifier   Notifier
+	revisions  revision.Store
+}
+
+func NewFeatureflagService(repository Adapter, notifier Notifier) *Service {
+	return &Service{repository: repository, notifier: notifier}
 }
 
-func NewFeatureflagService(repository Adapter, pub Publisher) *Service {
-	return &Service{repository: repository, pub: pub}
+// WithRevisions stamps the changes with the revisions of revisions, for the ETag of the flags
+// and GetChanges
+func (ff *Service) WithRevisions(revisions revision.Store) *Service {
+	ff.revisions = revisions
+	return ff
 }
 
-func (ff Service) CreateOrUpdate(ctx context.Context, featureflag Entity) error {
+func (ff Service) CreateOrUpdate(ctx context.Context, featureflag Entity) (err error) {
+	ctx, span := telemetry.Start(ctx, "featureflag.CreateOrUpdate", attribute.String("flag", featureflag.FlagName))
+	defer func() { telemetry.End(span, err) }()
+
 	flag, err := ff.repository.GetFF(ctx, featureflag.FlagName)
 
 	if err != nil {
 		switch err.(type) {
 		case *errorutils.NotFoundError:
-			if err := ff.repository.SaveFF(ctx, featureflag); err != nil {
-				return err
-			}
+			return revision.Write(ctx, ff.revisions, revisionResource, func(rev int64) error {
+				featureflag.Revision = rev
+				return ff.repository.SaveFF(ctx, featureflag)
+			})
 		default:
 			return err
 		}
-
-		return nil
 	}
 
 	flag.Active = featureflag.Active
 
-	if err := ff.repository.SaveFF(ctx, flag); err != nil {
-		return fmt.Errorf("error on save in repository: %w", err)
-	}
+	err = revision.Write(ctx, ff.revisions, revisionResource, func(rev int64) error {
+		flag.Revision = rev
+
+		event, err := outbox.NewEntry("featureflag", flag)
+		if err != nil {
+			return err
+		}
 
-	if err := ff.pub.Publish(ctx, "featureflag", pubsub.NewPayload(flag)); err != nil {
-		return fmt.Errorf("error on publisher event writer feature flag: %w", err)
+		if err := ff.repository.SaveFFWithEvent(ctx, flag, event); err != nil {
+			return fmt.Errorf("error on save in repository: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
+	ff.notifier.Notify()
+
 	return nil
 }
 
-func (ff Service) RemoveFeatureFlag(ctx context.Context, key string) error {
-	return ff.repository.DeleteFF(ctx, key)
+func (ff Service) RemoveFeatureFlag(ctx context.Context, key string) (err error) {
+	ctx, span := telemetry.Start(ctx, "featureflag.RemoveFeatureFlag", attribute.String("flag", key))
+	defer func() { telemetry.End(span, err) }()
+
+	err = revision.Write(ctx, ff.revisions, revisionResource, func(rev int64) error {
+		event, err := outbox.NewEntry(outbox.DeletedChannel("featureflag"), revision.Tombstone{Key: key, Revision: rev})
+		if err != nil {
+			return err
+		}
+
+		if err := ff.repository.DeleteFFWithEvent(ctx, key, event); err != nil {
+			return err
+		}
+
+		if ff.revisions == nil {
+			return nil
+		}
+
+		return ff.revisions.Deleted(ctx, revisionResource, key, rev)
+	})
+	if err != nil {
+		return err
+	}
+
+	ff.notifier.Notify()
+
+	return nil
 }
 
-func (ff Service) GetAllFeatureFlag(ctx context.Context) (map[string]Entity, error) {
+func (ff Service) GetAllFeatureFlag(ctx context.Context) (_ map[string]Entity, err error) {
+	ctx, span := telemetry.Start(ctx, "featureflag.GetAllFeatureFlag")
+	defer func() { telemetry.End(span, err) }()
+
 	return ff.repository.GetAllFF(ctx)
 }
 
-func (ff Service) GetFeatureFlag(ctx context.Context, key string, sessionID string) (Entity, error) {
+// GetAllFeatureFlagRevision returns the flags with the revision read before them, revision.Unknown
+// without revisions
+func (ff Service) GetAllFeatureFlagRevision(ctx context.Context) (_ map[string]Entity, _ int64, err error) {
+	ctx, span := telemetry.Start(ctx, "featureflag.GetAllFeatureFlagRevision")
+	defer func() { telemetry.End(span, err) }()
+
+	current := revision.Unknown
+	if ff.revisions != nil {
+		if current, err = ff.revisions.Current(ctx, revisionResource); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	flags, err := ff.repository.GetAllFF(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return flags, current, nil
+}
+
+// GetChanges returns the flags changed and deleted at the revision since or after it, with the
+// revision to ask next. The keys deleted and created again are only in Changed.
+func (ff Service) GetChanges(ctx context.Context, since int64) (_ Changes, err error) {
+	ctx, span := telemetry.Start(ctx, "featureflag.GetChanges", attribute.Int64("since", since))
+	defer func() { telemetry.End(span, err) }()
+
+	if ff.revisions == nil {
+		return Changes{}, revision.ErrDisabled
+	}
+
+	current, err := ff.revisions.Current(ctx, revisionResource)
+	if err != nil {
+		return Changes{}, err
+	}
+
+	flags, err := ff.repository.GetAllFF(ctx)
+	if err != nil {
+		return Changes{}, err
+	}
+
+	deleted, err := ff.revisions.DeletedSince(ctx, revisionResource, since)
+	if err != nil {
+		return Changes{}, err
+	}
+
+	changes := Changes{Revision: current, Changed: []Entity{}, Deleted: []string{}}
+	for _, flag := range flags {
+		if flag.Revision >= since {
+			changes.Changed = append(changes.Changed, flag)
+		}
+	}
+
+	for _, tombstone := range deleted {
+		if _, ok := flags[tombstone.Key]; !ok && !slices.Contains(changes.Deleted, tombstone.Key) {
+			changes.Deleted = append(changes.Deleted, tombstone.Key)
+		}
+	}
+
+	return changes, nil
+}
+
+func (ff Service) GetFeatureFlag(ctx context.Context, key string, sessionID string) (_ Entity, err error) {
+	ctx, span := telemetry.Start(ctx, "featureflag.GetFeatureFlag", attribute.String("flag", key))
+	defer func() { telemetry.End(span, err) }()
+
 	featureflag, err := ff.repository.GetFF(ctx, key)
 	if err != nil {
 		return Entity{}, err
@@ -73,19 +196,52 @@ func (ff Service) GetFeatureFlag(ctx context.Context, key string, sessionID stri
 	return featureflag, nil
 }
 
-func (ff Service) GetFeatureFlagBySDK(ctx context.Context, key string, sessionID string) (bool, error) {
+func (ff Service) GetFeatureFlagBySDK(ctx context.Context, key string, sessionID string) (_ bool, err error) {
+	ctx, span := telemetry.Start(ctx, "featureflag.GetFeatureFlagBySDK", attribute.String("flag", key))
+	defer func() { telemetry.End(span, err) }()
+
 	featureflag, err := ff.repository.GetFF(ctx, key)
 	if err != nil {
 		return false, err
 	}
 
+	return ff.evaluate(ctx, featureflag, sessionID)
+}
+
+// EvaluateAll returns the status of every flag for sessionID, as GetFeatureFlagBySDK does for one
+func (ff Service) EvaluateAll(ctx context.Context, sessionID string) (_ map[string]bool, err error) {
+	ctx, span := telemetry.Start(ctx, "featureflag.EvaluateAll")
+	defer func() { telemetry.End(span, err) }()
+
+	flags, err := ff.repository.GetAllFF(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	output := make(map[string]bool, len(flags))
+	for key, featureflag := range flags {
+		active, err := ff.evaluate(ctx, featureflag, sessionID)
+		if err != nil {
+			return nil, err
+		}
+
+		output[key] = active
+	}
+
+	return output, nil
+}
+
+func (ff Service) evaluate(ctx context.Context, featureflag Entity, sessionID string) (bool, error) {
 	if featureflag.IsUseStrategy() {
 		if err := ff.repository.SaveFF(ctx, featureflag.SetStrategy(sessionID).SetQtdCall()); err != nil {
 			return false, err
 		}
 
-		return featureflag.Strategies.IsActiveWithStrategy(sessionID), nil
+		active := featureflag.Strategies.IsActiveWithStrategy(sessionID)
+		telemetry.RecordEvaluation(ctx, featureflag.FlagName, active)
+		return active, nil
 	}
 
+	telemetry.RecordEvaluation(ctx, featureflag.FlagName, featureflag.Active)
 	return featureflag.Active, nil
 }
@@ -3,12 +3,17 @@ package featureflag
 import (
 	"context"
 	"errors"
+	"os"
+	"path/filepath"
 	"reflect"
+	"slices"
 	"testing"
 	"time"
 
+	"github.com/IsaacDSC/featureflag/internal/revision"
 	"github.com/IsaacDSC/featureflag/internal/strategy"
 	"github.com/IsaacDSC/featureflag/pkg/errorutils"
+	"github.com/IsaacDSC/featureflag/pkg/telemetry"
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 )
@@ -25,7 +30,7 @@ func TestFeatureflagService_CreateOrUpdate(t *testing.T) {
 
 	control := gomock.NewController(t)
 	repository := NewMockFeatureFlagRepository(control)
-	publisher := NewMockPublisher(control)
+	notifier := NewMockNotifier(control)
 
 	tests := []struct {
 		name    string
@@ -41,8 +46,8 @@ func TestFeatureflagService_CreateOrUpdate(t *testing.T) {
 			args: args{
 				behavior: func(ff Entity) {
 					repository.EXPECT().GetFF(gomock.Any(), gomock.Any()).Return(ff, nil)
-					repository.EXPECT().SaveFF(gomock.Any(), ff).Return(nil)
-					publisher.EXPECT().Publish(gomock.Any(), "featureflag", gomock.Any()).Return(nil)
+					repository.EXPECT().SaveFFWithEvent(gomock.Any(), ff, gomock.Any()).Return(nil)
+					notifier.EXPECT().Notify()
 				},
 				featureflag: Entity{
 					ID:         uuid.New(),
@@ -104,7 +109,7 @@ func TestFeatureflagService_CreateOrUpdate(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			repo := Service{
 				repository: tt.fields.repository,
-				pub:        publisher,
+				notifier:   notifier,
 			}
 			tt.args.behavior(tt.args.featureflag)
 			if err := repo.CreateOrUpdate(context.Background(), tt.args.featureflag); (err != nil) != tt.wantErr {
@@ -117,7 +122,7 @@ func TestFeatureflagService_CreateOrUpdate(t *testing.T) {
 func TestFeatureflagService_GetFeatureFlag(t *testing.T) {
 	control := gomock.NewController(t)
 	repository := NewMockFeatureFlagRepository(control)
-	publisher := NewMockPublisher(control)
+	notifier := NewMockNotifier(control)
 
 	type fields struct {
 		repository Adapter
@@ -256,7 +261,7 @@ func TestFeatureflagService_GetFeatureFlag(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			ff := Service{
 				repository: tt.fields.repository,
-				pub:        publisher,
+				notifier:   notifier,
 			}
 
 			tt.args.behavior(tt.args.key, tt.args.sessionID, tt.want)
@@ -272,3 +277,129 @@ func TestFeatureflagService_GetFeatureFlag(t *testing.T) {
 		})
 	}
 }
+
+func TestFeatureflagService_EvaluateAll(t *testing.T) {
+	control := gomock.NewController(t)
+	repository := NewMockFeatureFlagRepository(control)
+	notifier := NewMockNotifier(control)
+
+	sessionID := "01J2BQ9Y19SHS6F6PMZQCH9Z70"
+	withSession := Entity{
+		FlagName: "with_session",
+		Strategies: strategy.Strategy{
+			WithStrategy: true,
+			SessionsID:   map[string]bool{sessionID: true},
+		},
+	}
+	flags := map[string]Entity{
+		"active":       {FlagName: "active", Active: true},
+		"inactive":     {FlagName: "inactive"},
+		"with_session": withSession,
+	}
+
+	repository.EXPECT().GetAllFF(gomock.Any()).Return(flags, nil)
+	repository.EXPECT().SaveFF(gomock.Any(), gomock.Any()).Return(nil)
+
+	got, err := NewFeatureflagService(repository, notifier).EvaluateAll(context.Background(), sessionID)
+	if err != nil {
+		t.Fatalf("EvaluateAll() error = %v", err)
+	}
+
+	want := map[string]bool{"active": true, "inactive": false, "with_session": true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("EvaluateAll() = %v, want %v", got, want)
+	}
+}
+
+func TestFeatureflagService_GetChanges(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "revisions.json")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatalf("error on create file: %v", err)
+	}
+
+	control := gomock.NewController(t)
+	repository := NewMockFeatureFlagRepository(control)
+	notifier := NewMockNotifier(control)
+	service := NewFeatureflagService(repository, notifier).WithRevisions(revision.NewRepository(path))
+
+	// revision 1, the flag created before the revisions keeps 0
+	repository.EXPECT().GetFF(gomock.Any(), "changed").Return(Entity{FlagName: "changed"}, nil)
+	repository.EXPECT().SaveFFWithEvent(gomock.Any(), Entity{FlagName: "changed", Active: true, Revision: 1}, gomock.Any()).Return(nil)
+	notifier.EXPECT().Notify()
+	if err := service.CreateOrUpdate(ctx, Entity{FlagName: "changed", Active: true}); err != nil {
+		t.Fatalf("CreateOrUpdate() error = %v", err)
+	}
+
+	// revisions 3 and 5
+	repository.EXPECT().DeleteFFWithEvent(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(2)
+	notifier.EXPECT().Notify().Times(2)
+	for _, key := range []string{"deleted", "recreated"} {
+		if err := service.RemoveFeatureFlag(ctx, key); err != nil {
+			t.Fatalf("RemoveFeatureFlag() error = %v", err)
+		}
+	}
+
+	flags := map[string]Entity{
+		"old":       {FlagName: "old"},
+		"changed":   {FlagName: "changed", Active: true, Revision: 1},
+		"recreated": {FlagName: "recreated", Revision: 7},
+	}
+	repository.EXPECT().GetAllFF(gomock.Any()).Return(flags, nil).Times(2)
+
+	changes, err := service.GetChanges(ctx, 1)
+	if err != nil {
+		t.Fatalf("GetChanges() error = %v", err)
+	}
+
+	changed := make([]string, 0, len(changes.Changed))
+	for _, flag := range changes.Changed {
+		changed = append(changed, flag.FlagName)
+	}
+	slices.Sort(changed)
+
+	if changes.Revision != 6 || !reflect.DeepEqual(changed, []string{"changed", "recreated"}) || !reflect.DeepEqual(changes.Deleted, []string{"deleted"}) {
+		t.Errorf("GetChanges(1) = revision %d, changed %v, deleted %v", changes.Revision, changed, changes.Deleted)
+	}
+
+	if changes, err = service.GetChanges(ctx, 6); err != nil || len(changes.Deleted) != 0 || len(changes.Changed) != 1 {
+		t.Errorf("GetChanges(6) = %+v, %v, want only recreated", changes, err)
+	}
+}
+
+func TestFeatureflagService_Telemetry(t *testing.T) {
+	memory := telemetry.NewInMemory()
+	memory.Install()
+
+	control := gomock.NewController(t)
+	repository := NewMockFeatureFlagRepository(control)
+	repository.EXPECT().GetFF(gomock.Any(), "traced").Return(Entity{FlagName: "traced", Active: true}, nil)
+
+	service := NewFeatureflagService(NewInstrumentedRepository(repository, "mongodb"), NewMockNotifier(control))
+	if _, err := service.GetFeatureFlagBySDK(context.Background(), "traced", ""); err != nil {
+		t.Fatalf("GetFeatureFlagBySDK() error = %v", err)
+	}
+
+	spans := memory.Spans()
+	if len(spans) != 2 {
+		t.Fatalf("recorded %d spans, want 2", len(spans))
+	}
+
+	repositorySpan, serviceSpan := spans[0], spans[1]
+	if repositorySpan.Name != "featureflags.GetFF" || serviceSpan.Name != "featureflag.GetFeatureFlagBySDK" {
+		t.Errorf("spans = %s, %s", repositorySpan.Name, serviceSpan.Name)
+	}
+
+	if repositorySpan.Parent.SpanID() != serviceSpan.SpanContext.SpanID() {
+		t.Error("repository span is not a child of the service span")
+	}
+
+	evaluations, err := memory.Sum(context.Background(), "featureflag.evaluations", map[string]string{"flag": "traced", "variant": telemetry.VariantOn})
+	if err != nil {
+		t.Fatalf("Sum() error = %v", err)
+	}
+
+	if evaluations != 1 {
+		t.Errorf("evaluations = %d, want 1", evaluations)
+	}
+}
@@ -1,49 +0,0 @@
-// Code generated by MockGen. DO NOT EDIT.
-// Source: featureflag_service.go
-
-package featureflag
-
-import (
-	context "context"
-	reflect "reflect"
-
-	pubsub "github.com/IsaacDSC/featureflag/pkg/pubsub"
-	gomock "github.com/golang/mock/gomock"
-)
-
-// MockPublisher is a mock of Publisher interface.
-type MockPublisher struct {
-	ctrl     *gomock.Controller
-	recorder *MockPublisherMockRecorder
-}
-
-// MockPublisherMockRecorder is the mock recorder for MockPublisher.
-type MockPublisherMockRecorder struct {
-	mock *MockPublisher
-}
-
-// NewMockPublisher creates a new mock instance.
-func NewMockPublisher(ctrl *gomock.Controller) *MockPublisher {
-	mock := &MockPublisher{ctrl: ctrl}
-	mock.recorder = &MockPublisherMockRecorder{mock}
-	return mock
-}
-
-// EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockPublisher) EXPECT() *MockPublisherMockRecorder {
-	return m.recorder
-}
-
-// Publish mocks base method.
-func (m *MockPublisher) Publish(ctx context.Context, channel string, msg pubsub.Payload) error {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Publish", ctx, channel, msg)
-	ret0, _ := ret[0].(error)
-	return ret0
-}
-
-// Publish indicates an expected call of Publish.
-func (mr *MockPublisherMockRecorder) Publish(ctx, channel, msg interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockPublisher)(nil).Publish), ctx, channel, msg)
-}
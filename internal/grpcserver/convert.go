@@ -0,0 +1,161 @@
+package grpcserver
+
+import (
+	"encoding/json"
+
+	"github.com/IsaacDSC/featureflag/internal/contenthub"
+	"github.com/IsaacDSC/featureflag/internal/featureflag"
+	"github.com/IsaacDSC/featureflag/internal/strategy"
+	featureflagv1 "github.com/IsaacDSC/featureflag/pkg/pb/featureflag/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func flagDtoFromProto(req *featureflagv1.UpsertFlagRequest) featureflag.Dto {
+	return featureflag.Dto{
+		FlagName: req.GetFlagName(),
+		Active:   req.GetActive(),
+		Strategies: strategy.StrategyDto{
+			SessionsID: req.GetStrategy().GetSessionIds(),
+			Percent:    req.GetStrategy().GetPercent(),
+		},
+	}
+}
+
+func flagToProto(flag featureflag.Entity) *featureflagv1.Flag {
+	dto := strategy.StrategyFromDomain(flag.Strategies)
+
+	return &featureflagv1.Flag{
+		Id:       flag.ID.String(),
+		FlagName: flag.FlagName,
+		Active:   flag.Active,
+		Strategy: &featureflagv1.Strategy{
+			SessionIds: dto.SessionsID,
+			Percent:    dto.Percent,
+		},
+		CreatedAt: timestamppb.New(flag.CreatedAt),
+	}
+}
+
+func contentDtoFromProto(req *featureflagv1.UpsertContentRequest) contenthub.Dto {
+	dto := contenthub.Dto{
+		Variable:    req.GetKey(),
+		Value:       req.GetValue(),
+		Description: req.GetDescription(),
+		Active:      req.GetActive(),
+	}
+
+	for _, s := range req.GetSessionStrategy() {
+		dto.SessionsStrategies = append(dto.SessionsStrategies, contenthub.SessionStrategy{
+			SessionID: s.GetSessionId(),
+			Response:  s.GetResponse().AsInterface(),
+			Locales:   localesFromProto(s.GetLocales()),
+		})
+	}
+
+	for _, s := range req.GetBalancerStrategy() {
+		dto.BalancerStrategy = append(dto.BalancerStrategy, contenthub.MultipleStrategy{
+			Weight:   uint(s.GetWeight()),
+			Response: s.GetResponse().AsInterface(),
+			Locales:  localesFromProto(s.GetLocales()),
+		})
+	}
+
+	return dto
+}
+
+func localesFromProto(locales map[string]*structpb.Value) contenthub.Locales {
+	if len(locales) == 0 {
+		return nil
+	}
+
+	output := make(contenthub.Locales, len(locales))
+	for locale, response := range locales {
+		output[locale] = response.AsInterface()
+	}
+
+	return output
+}
+
+func contentToProto(content contenthub.Entity) (*featureflagv1.Content, error) {
+	output := &featureflagv1.Content{
+		Id:          content.ID.String(),
+		Key:         content.Variable,
+		Value:       content.Value,
+		Description: content.Description,
+		Active:      content.Active,
+		CreatedAt:   timestamppb.New(content.CreatedAt),
+	}
+
+	for _, s := range content.SessionsStrategies {
+		response, err := toValue(s.Response)
+		if err != nil {
+			return nil, err
+		}
+
+		locales, err := localesToProto(s.Locales)
+		if err != nil {
+			return nil, err
+		}
+
+		output.SessionStrategy = append(output.SessionStrategy, &featureflagv1.SessionStrategy{
+			SessionId: s.SessionID,
+			Response:  response,
+			Locales:   locales,
+		})
+	}
+
+	for _, s := range content.BalancerStrategy {
+		response, err := toValue(s.Response)
+		if err != nil {
+			return nil, err
+		}
+
+		locales, err := localesToProto(s.Locales)
+		if err != nil {
+			return nil, err
+		}
+
+		output.BalancerStrategy = append(output.BalancerStrategy, &featureflagv1.BalancerStrategy{
+			Weight:   uint32(s.Weight),
+			Response: response,
+			Locales:  locales,
+		})
+	}
+
+	return output, nil
+}
+
+func localesToProto(locales contenthub.Locales) (map[string]*structpb.Value, error) {
+	if len(locales) == 0 {
+		return nil, nil
+	}
+
+	output := make(map[string]*structpb.Value, len(locales))
+	for locale, response := range locales {
+		value, err := toValue(response)
+		if err != nil {
+			return nil, err
+		}
+		output[locale] = value
+	}
+
+	return output, nil
+}
+
+// toValue converts through JSON, responses read from mongodb are bson types structpb.NewValue doesn't know
+func toValue(v any) (*structpb.Value, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "error on convert strategy response: %v", err)
+	}
+
+	value := new(structpb.Value)
+	if err := value.UnmarshalJSON(b); err != nil {
+		return nil, status.Errorf(codes.Internal, "error on convert strategy response: %v", err)
+	}
+
+	return value, nil
+}
@@ -0,0 +1,222 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+
+	"github.com/IsaacDSC/featureflag/internal/contenthub"
+	"github.com/IsaacDSC/featureflag/internal/featureflag"
+	"github.com/IsaacDSC/featureflag/internal/sdknotifier"
+	"github.com/IsaacDSC/featureflag/pkg/errorutils"
+	"github.com/IsaacDSC/featureflag/pkg/middlewares"
+	featureflagv1 "github.com/IsaacDSC/featureflag/pkg/pb/featureflag/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// permissions mirrors the CheckPermission of the HTTP routes, any authorized client may call the methods not listed
+var permissions = map[string]string{
+	featureflagv1.FeatureFlagService_UpsertFlag_FullMethodName:     middlewares.USERNAME_SERVICE,
+	featureflagv1.FeatureFlagService_GetFlag_FullMethodName:        middlewares.USERNAME_SERVICE,
+	featureflagv1.FeatureFlagService_DeleteFlag_FullMethodName:     middlewares.USERNAME_SERVICE,
+	featureflagv1.FeatureFlagService_UpsertContent_FullMethodName:  middlewares.USERNAME_SERVICE,
+	featureflagv1.FeatureFlagService_PublishContent_FullMethodName: middlewares.USERNAME_SERVICE,
+	featureflagv1.FeatureFlagService_GetContent_FullMethodName:     middlewares.USERNAME_SERVICE,
+	featureflagv1.FeatureFlagService_DeleteContent_FullMethodName:  middlewares.USERNAME_SERVICE,
+	featureflagv1.FeatureFlagService_Evaluate_FullMethodName:       middlewares.USERNAME_SDK,
+	featureflagv1.FeatureFlagService_EvaluateAll_FullMethodName:    middlewares.USERNAME_SDK,
+}
+
+type Server struct {
+	featureflagv1.UnimplementedFeatureFlagServiceServer
+
+	flags     *featureflag.Service
+	contents  *contenthub.Service
+	hub       *sdknotifier.Hub
+	snapshots map[string]sdknotifier.Snapshot
+}
+
+func NewServer(flags *featureflag.Service, contents *contenthub.Service, hub *sdknotifier.Hub, snapshots map[string]sdknotifier.Snapshot) *Server {
+	return &Server{flags: flags, contents: contents, hub: hub, snapshots: snapshots}
+}
+
+// New returns a grpc.Server serving server behind the logger and authorization interceptors
+func New(server *Server, opts ...grpc.ServerOption) *grpc.Server {
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(middlewares.UnaryLogger(), middlewares.UnaryAuthorization(permissions)),
+		grpc.ChainStreamInterceptor(middlewares.StreamLogger(), middlewares.StreamAuthorization(permissions)),
+	)
+
+	s := grpc.NewServer(opts...)
+	featureflagv1.RegisterFeatureFlagServiceServer(s, server)
+
+	return s
+}
+
+func (s *Server) UpsertFlag(ctx context.Context, req *featureflagv1.UpsertFlagRequest) (*featureflagv1.UpsertFlagResponse, error) {
+	flag, err := featureflag.ToDomain(flagDtoFromProto(req))
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := s.flags.CreateOrUpdate(ctx, flag); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &featureflagv1.UpsertFlagResponse{}, nil
+}
+
+func (s *Server) GetFlag(ctx context.Context, req *featureflagv1.GetFlagRequest) (*featureflagv1.Flag, error) {
+	if req.GetKey() == "" {
+		return nil, status.Error(codes.InvalidArgument, "required key")
+	}
+
+	flag, err := s.flags.GetFeatureFlag(ctx, req.GetKey(), req.GetSessionId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return flagToProto(flag), nil
+}
+
+func (s *Server) ListFlags(ctx context.Context, _ *featureflagv1.ListFlagsRequest) (*featureflagv1.ListFlagsResponse, error) {
+	flags, err := s.flags.GetAllFeatureFlag(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	output := &featureflagv1.ListFlagsResponse{Flags: make([]*featureflagv1.Flag, 0, len(flags))}
+	for _, flag := range flags {
+		output.Flags = append(output.Flags, flagToProto(flag))
+	}
+
+	return output, nil
+}
+
+func (s *Server) DeleteFlag(ctx context.Context, req *featureflagv1.DeleteFlagRequest) (*featureflagv1.DeleteFlagResponse, error) {
+	if req.GetKey() == "" {
+		return nil, status.Error(codes.InvalidArgument, "required key")
+	}
+
+	if err := s.flags.RemoveFeatureFlag(ctx, req.GetKey()); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &featureflagv1.DeleteFlagResponse{}, nil
+}
+
+func (s *Server) UpsertContent(ctx context.Context, req *featureflagv1.UpsertContentRequest) (*featureflagv1.UpsertContentResponse, error) {
+	dto := contentDtoFromProto(req)
+	content, err := dto.ToDomain()
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := s.contents.SaveDraft(ctx, content); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &featureflagv1.UpsertContentResponse{}, nil
+}
+
+func (s *Server) PublishContent(ctx context.Context, req *featureflagv1.PublishContentRequest) (*featureflagv1.Content, error) {
+	if req.GetKey() == "" {
+		return nil, status.Error(codes.InvalidArgument, "required key")
+	}
+
+	content, err := s.contents.Publish(ctx, req.GetKey())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return contentToProto(content)
+}
+
+func (s *Server) GetContent(ctx context.Context, req *featureflagv1.GetContentRequest) (*featureflagv1.Content, error) {
+	if req.GetKey() == "" {
+		return nil, status.Error(codes.InvalidArgument, "required key")
+	}
+
+	content, err := s.contents.GetContentHub(ctx, req.GetKey())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return contentToProto(content)
+}
+
+func (s *Server) ListContents(ctx context.Context, _ *featureflagv1.ListContentsRequest) (*featureflagv1.ListContentsResponse, error) {
+	contents, err := s.contents.GetAllContentHub(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	output := &featureflagv1.ListContentsResponse{Contents: make([]*featureflagv1.Content, 0, len(contents))}
+	for _, content := range contents {
+		c, err := contentToProto(content)
+		if err != nil {
+			return nil, err
+		}
+		output.Contents = append(output.Contents, c)
+	}
+
+	return output, nil
+}
+
+func (s *Server) DeleteContent(ctx context.Context, req *featureflagv1.DeleteContentRequest) (*featureflagv1.DeleteContentResponse, error) {
+	if req.GetKey() == "" {
+		return nil, status.Error(codes.InvalidArgument, "required key")
+	}
+
+	if err := s.contents.RemoveContentHub(ctx, req.GetKey()); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &featureflagv1.DeleteContentResponse{}, nil
+}
+
+func (s *Server) Evaluate(ctx context.Context, req *featureflagv1.EvaluateRequest) (*featureflagv1.EvaluateResponse, error) {
+	if req.GetKey() == "" {
+		return nil, status.Error(codes.InvalidArgument, "required key")
+	}
+
+	active, err := s.flags.GetFeatureFlagBySDK(ctx, req.GetKey(), req.GetSessionId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &featureflagv1.EvaluateResponse{Key: req.GetKey(), Active: active}, nil
+}
+
+func (s *Server) EvaluateAll(ctx context.Context, req *featureflagv1.EvaluateAllRequest) (*featureflagv1.EvaluateAllResponse, error) {
+	flags, err := s.flags.EvaluateAll(ctx, req.GetSessionId())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &featureflagv1.EvaluateAllResponse{Flags: flags}, nil
+}
+
+func toStatus(err error) error {
+	var notFound *errorutils.NotFoundError
+	if errors.As(err, &notFound) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+
+	var invalid *contenthub.SchemaValidationError
+	if errors.As(err, &invalid) || errors.Is(err, contenthub.ErrInvalidSchema) || errors.Is(err, contenthub.ErrUnknownContentType) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	var missing *contenthub.MissingTranslationsError
+	if errors.As(err, &missing) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if errors.Is(err, contenthub.ErrNoDraft) {
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	return status.Error(codes.Internal, err.Error())
+}
@@ -0,0 +1,238 @@
+package grpcserver
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/IsaacDSC/featureflag/internal/contenthub"
+	"github.com/IsaacDSC/featureflag/internal/env"
+	"github.com/IsaacDSC/featureflag/internal/featureflag"
+	"github.com/IsaacDSC/featureflag/internal/outbox"
+	"github.com/IsaacDSC/featureflag/internal/sdknotifier"
+	"github.com/IsaacDSC/featureflag/pkg/errorutils"
+	featureflagv1 "github.com/IsaacDSC/featureflag/pkg/pb/featureflag/v1"
+	"github.com/IsaacDSC/featureflag/pkg/pubsub"
+	"github.com/golang/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+type fakeSubscriber struct {
+	events chan string
+}
+
+func (s *fakeSubscriber) Listener(ctx context.Context, channel string, fn pubsub.Handler) error {
+	// the deletes are not published in these tests
+	if channel == outbox.DeletedChannel("featureflag") {
+		<-ctx.Done()
+		return nil
+	}
+
+	for {
+		select {
+		case event := <-s.events:
+			fn(pubsub.WithEventID(ctx, "1-0"), []byte(event))
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (s *fakeSubscriber) LastID(ctx context.Context, channel string) (string, error) {
+	return "0-0", nil
+}
+
+func setupServer(t *testing.T, repository featureflag.Adapter, sub *fakeSubscriber) featureflagv1.FeatureFlagServiceClient {
+	t.Helper()
+
+	env.Override(env.Environment{ServiceClientAT: "service", SDKClientAT: "sdk"})
+
+	hub := sdknotifier.NewHub(sub, sdknotifier.HubConfig{ClientBuffer: 10, SlowConsumer: sdknotifier.SlowConsumerDrop, History: 10})
+	flags := featureflag.NewFeatureflagService(repository, nil)
+	snapshots := map[string]sdknotifier.Snapshot{
+		"featureflag": func(ctx context.Context) (any, error) {
+			return []featureflag.Entity{{FlagName: "a"}, {FlagName: "b"}}, nil
+		},
+	}
+
+	server := New(NewServer(flags, contenthub.NewContentHubService(nil, nil), hub, snapshots))
+	lis := bufconn.Listen(1024 * 1024)
+	go server.Serve(lis)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("error on dial: %v", err)
+	}
+
+	t.Cleanup(func() {
+		conn.Close()
+		hub.Close()
+		server.Stop()
+	})
+
+	return featureflagv1.NewFeatureFlagServiceClient(conn)
+}
+
+func withToken(token string) context.Context {
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", token)
+}
+
+func TestServer_Authorization(t *testing.T) {
+	control := gomock.NewController(t)
+	repository := featureflag.NewMockFeatureFlagRepository(control)
+	client := setupServer(t, repository, &fakeSubscriber{events: make(chan string)})
+
+	repository.EXPECT().GetFF(gomock.Any(), "a").Return(featureflag.Entity{FlagName: "a", Active: true}, nil)
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		call func(ctx context.Context) error
+		want codes.Code
+	}{
+		{
+			name: "should be unauthenticated without token",
+			ctx:  context.Background(),
+			call: func(ctx context.Context) error {
+				_, err := client.ListFlags(ctx, &featureflagv1.ListFlagsRequest{})
+				return err
+			},
+			want: codes.Unauthenticated,
+		},
+		{
+			name: "should be denied with unknown token",
+			ctx:  withToken("unknown"),
+			call: func(ctx context.Context) error {
+				_, err := client.ListFlags(ctx, &featureflagv1.ListFlagsRequest{})
+				return err
+			},
+			want: codes.PermissionDenied,
+		},
+		{
+			name: "should be denied when sdk deletes a flag",
+			ctx:  withToken("sdk"),
+			call: func(ctx context.Context) error {
+				_, err := client.DeleteFlag(ctx, &featureflagv1.DeleteFlagRequest{Key: "a"})
+				return err
+			},
+			want: codes.PermissionDenied,
+		},
+		{
+			name: "should be evaluate with sdk token",
+			ctx:  withToken("sdk"),
+			call: func(ctx context.Context) error {
+				output, err := client.Evaluate(ctx, &featureflagv1.EvaluateRequest{Key: "a"})
+				if err == nil && !output.GetActive() {
+					t.Error("expected flag active")
+				}
+				return err
+			},
+			want: codes.OK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := status.Code(tt.call(tt.ctx)); got != tt.want {
+				t.Errorf("code = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServer_UpsertContent(t *testing.T) {
+	control := gomock.NewController(t)
+	repository := contenthub.NewMockContentHubRepository(control)
+	server := NewServer(nil, contenthub.NewContentHubService(repository, nil).WithRequiredLocales([]string{"pt-BR"}), nil, nil)
+
+	strategy := func(locales map[string]*structpb.Value) *featureflagv1.UpsertContentRequest {
+		return &featureflagv1.UpsertContentRequest{
+			Key: "banner",
+			BalancerStrategy: []*featureflagv1.BalancerStrategy{
+				{Weight: 100, Response: structpb.NewStringValue("welcome"), Locales: locales},
+			},
+		}
+	}
+
+	t.Run("should refuse missing translations", func(t *testing.T) {
+		_, err := server.UpsertContent(context.Background(), strategy(nil))
+		if got := status.Code(err); got != codes.InvalidArgument {
+			t.Errorf("code = %v, want %v", got, codes.InvalidArgument)
+		}
+	})
+
+	t.Run("should save a new key as an unpublished draft", func(t *testing.T) {
+		repository.EXPECT().GetContentHub(gomock.Any(), "banner").Return(contenthub.Entity{}, errorutils.NewNotFoundError("contenthub"))
+		repository.EXPECT().SaveContentHub(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, content contenthub.Entity) error {
+			if !content.Unpublished || content.Draft == nil || content.Draft.BalancerStrategy[0].Locales["pt"] != "bem-vindo" {
+				t.Errorf("saved %+v, want an unpublished draft with its translations", content)
+			}
+			return nil
+		})
+
+		_, err := server.UpsertContent(context.Background(), strategy(map[string]*structpb.Value{"pt": structpb.NewStringValue("bem-vindo")}))
+		if err != nil {
+			t.Errorf("UpsertContent() error = %v", err)
+		}
+	})
+
+	t.Run("should not publish without draft", func(t *testing.T) {
+		repository.EXPECT().GetContentHub(gomock.Any(), "banner").Return(contenthub.Entity{Variable: "banner"}, nil)
+
+		_, err := server.PublishContent(context.Background(), &featureflagv1.PublishContentRequest{Key: "banner"})
+		if got := status.Code(err); got != codes.FailedPrecondition {
+			t.Errorf("code = %v, want %v", got, codes.FailedPrecondition)
+		}
+	})
+}
+
+func TestServer_Watch(t *testing.T) {
+	control := gomock.NewController(t)
+	sub := &fakeSubscriber{events: make(chan string)}
+	client := setupServer(t, featureflag.NewMockFeatureFlagRepository(control), sub)
+
+	ctx, cancel := context.WithTimeout(withToken("sdk"), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.Watch(ctx, &featureflagv1.WatchRequest{Resource: "featureflag", Keys: []string{"a"}})
+	if err != nil {
+		t.Fatalf("error on watch: %v", err)
+	}
+
+	snapshot, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("error on receive snapshot: %v", err)
+	}
+
+	var flags []featureflag.Entity
+	if err := json.Unmarshal(snapshot.GetData(), &flags); err != nil {
+		t.Fatalf("error on unmarshal snapshot: %v", err)
+	}
+
+	if snapshot.GetEvent() != snapshotEvent || snapshot.GetId() != "0-0" || len(flags) != 1 || flags[0].FlagName != "a" {
+		t.Fatalf("unexpected snapshot %s %s %s", snapshot.GetId(), snapshot.GetEvent(), snapshot.GetData())
+	}
+
+	sub.events <- `{"flag_name":"b"}`
+	sub.events <- `{"flag_name":"a","active":true}`
+
+	event, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("error on receive event: %v", err)
+	}
+
+	if event.GetId() != "1-0" || string(event.GetData()) != `{"flag_name":"a","active":true}` {
+		t.Errorf("unexpected event %s %s", event.GetId(), event.GetData())
+	}
+}
@@ -0,0 +1,75 @@
+package grpcserver
+
+import (
+	"github.com/IsaacDSC/featureflag/internal/sdknotifier"
+	"github.com/IsaacDSC/featureflag/pkg/ctxlog"
+	featureflagv1 "github.com/IsaacDSC/featureflag/pkg/pb/featureflag/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const snapshotEvent = "snapshot"
+
+// Watch sends the same events as GET /events/{resource}: the events missed since last_event_id
+// or a snapshot, then every change of the resource, both filtered by keys when given
+func (s *Server) Watch(req *featureflagv1.WatchRequest, stream featureflagv1.FeatureFlagService_WatchServer) error {
+	ctx := stream.Context()
+	resource := req.GetResource()
+	if resource == "" {
+		return status.Error(codes.InvalidArgument, "required resource")
+	}
+
+	l := ctxlog.GetLogger(ctx)
+
+	keys := make(map[string]bool, len(req.GetKeys()))
+	for _, key := range req.GetKeys() {
+		keys[key] = true
+	}
+
+	subscription, err := s.hub.Subscribe(ctx, resource, req.GetLastEventId())
+	if err != nil {
+		l.Error("error on subscribe grpc watch", "resource", resource, "error", err)
+		return status.Error(codes.Internal, err.Error())
+	}
+	defer s.hub.Unsubscribe(resource, subscription)
+
+	if subscription.Resumed {
+		for _, event := range subscription.Replay {
+			if err := send(stream, keys, event); err != nil {
+				return err
+			}
+		}
+	} else {
+		b, ok, err := sdknotifier.MarshalSnapshot(ctx, s.snapshots, resource, keys)
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+
+		if ok {
+			if err := stream.Send(&featureflagv1.WatchEvent{Id: subscription.LastID, Event: snapshotEvent, Data: b}); err != nil {
+				return err
+			}
+		}
+	}
+
+	for {
+		select {
+		case event := <-subscription.Events:
+			if err := send(stream, keys, event); err != nil {
+				return err
+			}
+		case <-subscription.Done:
+			return status.Error(codes.Unavailable, "disconnected by the server, watch again with last_event_id")
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func send(stream featureflagv1.FeatureFlagService_WatchServer, keys map[string]bool, event sdknotifier.Event) error {
+	if !sdknotifier.MatchKeys(keys, event.Data) {
+		return nil
+	}
+
+	return stream.Send(&featureflagv1.WatchEvent{Id: event.ID, Event: event.Event, Data: event.Data})
+}
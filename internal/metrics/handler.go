@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/IsaacDSC/featureflag/pkg/middlewares"
+	"github.com/IsaacDSC/featureflag/pkg/telemetry"
+)
+
+const (
+	// AuthNone serves the metrics without authentication, for scrapers inside the private network
+	AuthNone = "none"
+	// AuthBearer requires the header "Authorization: Bearer <Token>", the format of the Prometheus scrape configs
+	AuthBearer = "bearer"
+	// AuthService requires the access token of the services, as the other management routes
+	AuthService = "service"
+)
+
+type Config struct {
+	Auth  string
+	Token string
+}
+
+type Handler struct {
+	routes map[string]func(w http.ResponseWriter, r *http.Request)
+	token  string
+}
+
+func NewHandler(cfg Config) *Handler {
+	handler := &Handler{token: cfg.Token}
+	metrics := telemetry.PrometheusHandler().ServeHTTP
+
+	switch cfg.Auth {
+	case AuthBearer:
+		metrics = handler.bearer(metrics)
+	case AuthService:
+		metrics = middlewares.Authorization(middlewares.CheckPermission(metrics, middlewares.USERNAME_SERVICE))
+	}
+
+	handler.routes = map[string]func(w http.ResponseWriter, r *http.Request){
+		"GET /metrics": metrics,
+	}
+
+	return handler
+}
+
+func (h *Handler) GetRoutes() map[string]func(w http.ResponseWriter, r *http.Request) {
+	return h.routes
+}
+
+func (h *Handler) bearer(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if token != h.token {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		next(w, r)
+	}
+}
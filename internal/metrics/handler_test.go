@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/IsaacDSC/featureflag/internal/env"
+)
+
+func TestHandler_Auth(t *testing.T) {
+	env.Override(env.Environment{ServiceClientAT: "service-token", SDKClientAT: "sdk-token"})
+
+	tests := []struct {
+		name          string
+		cfg           Config
+		authorization string
+		want          int
+	}{
+		{name: "none", cfg: Config{Auth: AuthNone}, want: http.StatusOK},
+		{name: "bearer", cfg: Config{Auth: AuthBearer, Token: "scrape"}, authorization: "Bearer scrape", want: http.StatusOK},
+		{name: "bearer missing", cfg: Config{Auth: AuthBearer, Token: "scrape"}, want: http.StatusUnauthorized},
+		{name: "bearer wrong", cfg: Config{Auth: AuthBearer, Token: "scrape"}, authorization: "Bearer other", want: http.StatusForbidden},
+		{name: "service", cfg: Config{Auth: AuthService}, authorization: "service-token", want: http.StatusOK},
+		{name: "service with sdk token", cfg: Config{Auth: AuthService}, authorization: "sdk-token", want: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/metrics", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+
+			rec := httptest.NewRecorder()
+			NewHandler(tt.cfg).GetRoutes()["GET /metrics"](rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
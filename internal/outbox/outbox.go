@@ -0,0 +1,56 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Field is the field of the mongodb documents that holds their pending events
+const Field = "outbox"
+
+// Collection is the mongodb collection of the events without a document to be pushed to, as the deletes
+const Collection = "outbox"
+
+// DeletedChannel is the channel of the deletes of the entities published on channel
+func DeletedChannel(channel string) string {
+	return channel + ".deleted"
+}
+
+// Entry is an event saved together with the change that produced it, waiting to be published by the Relay
+type Entry struct {
+	ID        string          `json:"id" bson:"id"`
+	Channel   string          `json:"channel" bson:"channel"`
+	Data      json.RawMessage `json:"data" bson:"data"`
+	Attempts  int             `json:"attempts" bson:"attempts"`
+	LastError string          `json:"last_error,omitempty" bson:"last_error,omitempty"`
+	CreatedAt time.Time       `json:"created_at" bson:"created_at"`
+}
+
+func NewEntry(channel string, data any) (Entry, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return Entry{}, fmt.Errorf("error on marshal %s event: %w", channel, err)
+	}
+
+	return Entry{
+		ID:        uuid.NewString(),
+		Channel:   channel,
+		Data:      b,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// Store is where the Relay reads the pending entries from
+type Store interface {
+	// Pending returns up to limit entries, oldest first
+	Pending(ctx context.Context, limit int) ([]Entry, error)
+	Count(ctx context.Context) (int, error)
+	// Delivered removes the entry
+	Delivered(ctx context.Context, id string) error
+	// Failed saves the Attempts and LastError of entry
+	Failed(ctx context.Context, entry Entry) error
+}
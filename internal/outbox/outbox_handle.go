@@ -0,0 +1,40 @@
+package outbox
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/IsaacDSC/featureflag/pkg/middlewares"
+)
+
+type Handler struct {
+	relay *Relay
+}
+
+func NewOutboxHandler(relay *Relay) *Handler {
+	return &Handler{relay: relay}
+}
+
+func (h *Handler) GetRoutes() map[string]func(w http.ResponseWriter, r *http.Request) {
+	return map[string]func(w http.ResponseWriter, r *http.Request){
+		"GET /outbox/stats": middlewares.Authorization(middlewares.CheckPermission(h.stats, middlewares.USERNAME_SERVICE)),
+	}
+}
+
+func (h *Handler) stats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.relay.Stats(r.Context())
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	b, err := json.Marshal(stats)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(b)
+}
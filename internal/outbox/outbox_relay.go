@@ -0,0 +1,210 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/IsaacDSC/featureflag/pkg/ctxlog"
+	"github.com/IsaacDSC/featureflag/pkg/pubsub"
+)
+
+type RelayConfig struct {
+	// Interval between two reads of the stores when nothing notifies a new entry
+	Interval time.Duration
+	// Backoff is the wait after a failed publish, doubled on every failure up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	BatchSize  int
+}
+
+// Stats are the outbox metrics, Lag is the age of the oldest entry not published yet
+type Stats struct {
+	Pending          int        `json:"pending"`
+	OldestPendingAt  *time.Time `json:"oldest_pending_at,omitempty"`
+	LagSeconds       float64    `json:"lag_seconds"`
+	Published        uint64     `json:"published_total"`
+	PublishErrors    uint64     `json:"publish_errors_total"`
+	LastPublishedAt  *time.Time `json:"last_published_at,omitempty"`
+	LastDelaySeconds float64    `json:"last_delay_seconds"`
+}
+
+// Relay publishes the entries of the stores in the order they were created and removes them once published.
+// When publishing fails it stops and retries the same entry with backoff, so an event is never lost or
+// published before an older one, but it can be published more than once (e.g. when two nodes relay the
+// same store, or the removal fails).
+type Relay struct {
+	pub    pubsub.Publisher
+	stores []Store
+	cfg    RelayConfig
+	notify chan struct{}
+
+	published     atomic.Uint64
+	publishErrors atomic.Uint64
+	mu            sync.Mutex
+	lastPublished time.Time
+	lastDelay     time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewRelay(pub pubsub.Publisher, cfg RelayConfig, stores ...Store) *Relay {
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = ctxlog.SetLogger(ctx, ctxlog.NewLogger(ctx).With("component", "outbox"))
+
+	return &Relay{
+		pub:    pub,
+		stores: stores,
+		cfg:    cfg,
+		notify: make(chan struct{}, 1),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+func (r *Relay) Start() {
+	r.wg.Add(1)
+	go r.run()
+}
+
+// Close stops the relay, the entries not published stay in the stores for the next start
+func (r *Relay) Close() {
+	r.cancel()
+	r.wg.Wait()
+}
+
+// Notify wakes the relay after a new entry was saved, instead of waiting for the next Interval
+func (r *Relay) Notify() {
+	select {
+	case r.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (r *Relay) Stats(ctx context.Context) (Stats, error) {
+	var stats Stats
+	for _, store := range r.stores {
+		count, err := store.Count(ctx)
+		if err != nil {
+			return Stats{}, fmt.Errorf("error on count outbox entries: %w", err)
+		}
+		stats.Pending += count
+
+		oldest, err := store.Pending(ctx, 1)
+		if err != nil {
+			return Stats{}, fmt.Errorf("error on get oldest outbox entry: %w", err)
+		}
+
+		if len(oldest) > 0 && (stats.OldestPendingAt == nil || oldest[0].CreatedAt.Before(*stats.OldestPendingAt)) {
+			stats.OldestPendingAt = &oldest[0].CreatedAt
+		}
+	}
+
+	if stats.OldestPendingAt != nil {
+		stats.LagSeconds = time.Since(*stats.OldestPendingAt).Seconds()
+	}
+
+	stats.Published = r.published.Load()
+	stats.PublishErrors = r.publishErrors.Load()
+
+	r.mu.Lock()
+	if !r.lastPublished.IsZero() {
+		lastPublished := r.lastPublished
+		stats.LastPublishedAt = &lastPublished
+		stats.LastDelaySeconds = r.lastDelay.Seconds()
+	}
+	r.mu.Unlock()
+
+	return stats, nil
+}
+
+func (r *Relay) run() {
+	defer r.wg.Done()
+
+	backoff := r.cfg.Backoff
+	for {
+		wait, notify := r.cfg.Interval, r.notify
+		if err := r.relay(); err != nil {
+			log.Printf("outbox relay failed, retrying in %v: %v\n", backoff, err)
+			// new entries do not shorten the backoff, the older ones are published first anyway
+			wait, notify = backoff, nil
+			backoff = min(backoff*2, r.cfg.MaxBackoff)
+		} else {
+			backoff = r.cfg.Backoff
+		}
+
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-notify:
+		case <-time.After(wait):
+		}
+	}
+}
+
+// relay publishes every pending entry, stopping at the first failure to keep the order
+func (r *Relay) relay() error {
+	for _, store := range r.stores {
+		for {
+			if r.ctx.Err() != nil {
+				return nil
+			}
+
+			entries, err := store.Pending(r.ctx, r.cfg.BatchSize)
+			if err != nil {
+				return fmt.Errorf("error on get pending entries: %w", err)
+			}
+
+			for _, entry := range entries {
+				if err := r.publish(store, entry); err != nil {
+					return err
+				}
+			}
+
+			if r.cfg.BatchSize <= 0 || len(entries) < r.cfg.BatchSize {
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *Relay) publish(store Store, entry Entry) error {
+	data, err := pubsub.WithKey(entry.Data, entry.ID)
+	if err != nil {
+		// the payloads of the services are JSON objects, the other ones are published as they are
+		data = entry.Data
+	}
+
+	if err := r.pub.Publish(r.ctx, entry.Channel, pubsub.NewPayload(json.RawMessage(data))); err != nil {
+		r.publishErrors.Add(1)
+
+		entry.Attempts++
+		entry.LastError = err.Error()
+		if err := store.Failed(context.WithoutCancel(r.ctx), entry); err != nil {
+			log.Printf("error on save attempt of outbox entry %s: %v\n", entry.ID, err)
+		}
+
+		return fmt.Errorf("error on publish entry %s on %s: %w", entry.ID, entry.Channel, err)
+	}
+
+	r.published.Add(1)
+
+	r.mu.Lock()
+	r.lastPublished = time.Now()
+	r.lastDelay = r.lastPublished.Sub(entry.CreatedAt)
+	r.mu.Unlock()
+
+	if err := store.Delivered(context.WithoutCancel(r.ctx), entry.ID); err != nil {
+		return fmt.Errorf("error on remove published entry %s: %w", entry.ID, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,195 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/IsaacDSC/featureflag/pkg/pubsub"
+)
+
+type fakePublisher struct {
+	mu        sync.Mutex
+	failures  int
+	published []string
+	keys      []string
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, channel string, msg pubsub.Payload) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.failures > 0 {
+		p.failures--
+		return errors.New("redis unavailable")
+	}
+
+	b, _ := json.Marshal(msg)
+	p.published = append(p.published, channel)
+	p.keys = append(p.keys, pubsub.Key(b))
+	return nil
+}
+
+func (p *fakePublisher) channels() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]string(nil), p.published...)
+}
+
+func setupRelay(t *testing.T, pub *fakePublisher) (*Relay, *Repository) {
+	t.Helper()
+
+	filePath := filepath.Join(t.TempDir(), "outbox.json")
+	if err := os.WriteFile(filePath, nil, 0644); err != nil {
+		t.Fatalf("error on create file: %v", err)
+	}
+
+	repository := NewRepository(filePath)
+	relay := NewRelay(pub, RelayConfig{
+		Interval:   time.Hour,
+		Backoff:    10 * time.Millisecond,
+		MaxBackoff: 20 * time.Millisecond,
+		BatchSize:  2,
+	}, repository)
+
+	return relay, repository
+}
+
+func addEntries(t *testing.T, repository *Repository, channels ...string) {
+	t.Helper()
+
+	for _, channel := range channels {
+		entry, err := NewEntry(channel, map[string]any{"active": true})
+		if err != nil {
+			t.Fatalf("NewEntry() error = %v", err)
+		}
+
+		if err := repository.Add(context.Background(), entry); err != nil {
+			t.Fatalf("Add() error = %v", err)
+		}
+	}
+}
+
+func waitPublished(t *testing.T, pub *fakePublisher, n int) []string {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if channels := pub.channels(); len(channels) >= n {
+			return channels
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	t.Fatalf("timeout waiting %d events, published %v", n, pub.channels())
+	return nil
+}
+
+func TestRelay_PublishInOrder(t *testing.T) {
+	pub := &fakePublisher{}
+	relay, repository := setupRelay(t, pub)
+
+	// pending before the start are published on the first round, in batches
+	addEntries(t, repository, "featureflag", "contenthub", "featureflag")
+	entries, err := repository.Pending(context.Background(), 0)
+	if err != nil {
+		t.Fatalf("Pending() error = %v", err)
+	}
+
+	relay.Start()
+	defer relay.Close()
+
+	want := []string{"featureflag", "contenthub", "featureflag"}
+	got := waitPublished(t, pub, 3)
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("published %v, want %v", got, want)
+		}
+	}
+
+	// the events carry the id of their entry, to tell them apart from the same entry published again
+	pub.mu.Lock()
+	for i, entry := range entries {
+		if pub.keys[i] != entry.ID {
+			t.Errorf("published key %q, want %q", pub.keys[i], entry.ID)
+		}
+	}
+	pub.mu.Unlock()
+
+	// Interval is one hour, only Notify can wake the relay
+	addEntries(t, repository, "contenthub")
+	relay.Notify()
+	waitPublished(t, pub, 4)
+
+	stats, err := relay.Stats(context.Background())
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+
+	if stats.Pending != 0 || stats.Published != 4 || stats.LastPublishedAt == nil || stats.LagSeconds != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestRelay_RetryWithBackoff(t *testing.T) {
+	pub := &fakePublisher{failures: 3}
+	relay, repository := setupRelay(t, pub)
+
+	addEntries(t, repository, "featureflag", "contenthub")
+	relay.Start()
+	defer relay.Close()
+
+	got := waitPublished(t, pub, 2)
+	if got[0] != "featureflag" || got[1] != "contenthub" {
+		t.Errorf("published %v, want the order of the outbox", got)
+	}
+
+	stats, err := relay.Stats(context.Background())
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+
+	if stats.PublishErrors != 3 || stats.Published != 2 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestRelay_StatsLag(t *testing.T) {
+	pub := &fakePublisher{failures: 1 << 30}
+	relay, repository := setupRelay(t, pub)
+
+	addEntries(t, repository, "featureflag", "contenthub")
+	relay.Start()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		entries, _ := repository.Pending(context.Background(), 1)
+		if len(entries) == 1 && entries[0].Attempts > 0 {
+			if entries[0].LastError != "redis unavailable" {
+				t.Errorf("LastError = %q", entries[0].LastError)
+			}
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("timeout waiting failed attempt")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	relay.Close()
+
+	stats, err := relay.Stats(context.Background())
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+
+	if stats.Pending != 2 || stats.OldestPendingAt == nil || stats.LagSeconds <= 0 || stats.Published != 0 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
@@ -0,0 +1,104 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"slices"
+	"sync"
+)
+
+// Repository keeps the pending entries in a json file. The file repositories of the entities
+// cannot write the event atomically with the change, so they call Add right after saving it.
+type Repository struct {
+	filePath string
+	mu       sync.Mutex
+}
+
+func NewRepository(filePath string) *Repository {
+	return &Repository{filePath: filePath}
+}
+
+func (r *Repository) Add(ctx context.Context, entry Entry) error {
+	return r.update(func(entries []Entry) []Entry {
+		return append(entries, entry)
+	})
+}
+
+func (r *Repository) Pending(ctx context.Context, limit int) ([]Entry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entries, err := r.read()
+	if err != nil {
+		return nil, err
+	}
+
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	return entries, nil
+}
+
+func (r *Repository) Count(ctx context.Context) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entries, err := r.read()
+	return len(entries), err
+}
+
+func (r *Repository) Delivered(ctx context.Context, id string) error {
+	return r.update(func(entries []Entry) []Entry {
+		return slices.DeleteFunc(entries, func(e Entry) bool { return e.ID == id })
+	})
+}
+
+func (r *Repository) Failed(ctx context.Context, entry Entry) error {
+	return r.update(func(entries []Entry) []Entry {
+		for i := range entries {
+			if entries[i].ID == entry.ID {
+				entries[i].Attempts = entry.Attempts
+				entries[i].LastError = entry.LastError
+			}
+		}
+		return entries
+	})
+}
+
+// read must be called holding mu, the entries are kept in insertion order
+func (r *Repository) read() ([]Entry, error) {
+	b, err := os.ReadFile(r.filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(b) == 0 {
+		return nil, nil
+	}
+
+	var entries []Entry
+	if err := json.Unmarshal(b, &entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (r *Repository) update(fn func(entries []Entry) []Entry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entries, err := r.read()
+	if err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(fn(entries))
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(r.filePath, b, 0644)
+}
@@ -0,0 +1,126 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// MongoDBRepository reads the entries pushed to the Field array of the documents of a collection.
+// The entity repositories push the event in the same update that saves the change,
+// so both are written atomically without needing a transaction (or a replica set).
+type MongoDBRepository struct {
+	collection *mongo.Collection
+	timeout    time.Duration
+}
+
+// standaloneField marks the documents written by Add, which only hold their entry
+const standaloneField = "standalone"
+
+func NewMongoDBRepository(collection *mongo.Collection) *MongoDBRepository {
+	return &MongoDBRepository{
+		collection: collection,
+		timeout:    10 * time.Second,
+	}
+}
+
+func (mr *MongoDBRepository) pipeline(stages ...bson.M) []bson.M {
+	return append([]bson.M{
+		{"$match": bson.M{Field + ".0": bson.M{"$exists": true}}},
+		{"$unwind": "$" + Field},
+		{"$replaceRoot": bson.M{"newRoot": "$" + Field}},
+	}, stages...)
+}
+
+func (mr *MongoDBRepository) Pending(ctx context.Context, limit int) ([]Entry, error) {
+	ctx, cancel := context.WithTimeout(ctx, mr.timeout)
+	defer cancel()
+
+	stages := []bson.M{{"$sort": bson.M{"created_at": 1}}}
+	if limit > 0 {
+		stages = append(stages, bson.M{"$limit": limit})
+	}
+
+	cursor, err := mr.collection.Aggregate(ctx, mr.pipeline(stages...))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var entries []Entry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func (mr *MongoDBRepository) Count(ctx context.Context) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, mr.timeout)
+	defer cancel()
+
+	cursor, err := mr.collection.Aggregate(ctx, mr.pipeline(bson.M{"$count": "count"}))
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var result []struct {
+		Count int `bson:"count"`
+	}
+	if err := cursor.All(ctx, &result); err != nil {
+		return 0, err
+	}
+
+	if len(result) == 0 {
+		return 0, nil
+	}
+
+	return result[0].Count, nil
+}
+
+// Add saves entry in a document of its own, for the changes that remove their document, as the deletes.
+// The document is removed once the entry is delivered.
+func (mr *MongoDBRepository) Add(ctx context.Context, entry Entry) error {
+	ctx, cancel := context.WithTimeout(ctx, mr.timeout)
+	defer cancel()
+
+	_, err := mr.collection.InsertOne(ctx, bson.M{Field: []Entry{entry}, standaloneField: true})
+	return err
+}
+
+func (mr *MongoDBRepository) Delivered(ctx context.Context, id string) error {
+	ctx, cancel := context.WithTimeout(ctx, mr.timeout)
+	defer cancel()
+
+	result, err := mr.collection.DeleteOne(ctx, bson.M{Field + ".id": id, standaloneField: true})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount > 0 {
+		return nil
+	}
+
+	filter := bson.M{Field + ".id": id}
+	update := bson.M{"$pull": bson.M{Field: bson.M{"id": id}}}
+
+	_, err = mr.collection.UpdateOne(ctx, filter, update)
+	return err
+}
+
+func (mr *MongoDBRepository) Failed(ctx context.Context, entry Entry) error {
+	ctx, cancel := context.WithTimeout(ctx, mr.timeout)
+	defer cancel()
+
+	filter := bson.M{Field + ".id": entry.ID}
+	update := bson.M{"$set": bson.M{
+		Field + ".$.attempts":   entry.Attempts,
+		Field + ".$.last_error": entry.LastError,
+	}}
+
+	_, err := mr.collection.UpdateOne(ctx, filter, update)
+	return err
+}
@@ -0,0 +1,98 @@
+package revision
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var ErrDisabled = errors.New("revisions are disabled")
+
+// ErrResyncRequired is returned by DeletedSince when the tombstones at since were pruned,
+// the reader has to load the full list again
+var ErrResyncRequired = errors.New("revision older than the deleted keys kept, resync required")
+
+// maxTombstones is how many deleted keys are kept per resource, the oldest are pruned
+const maxTombstones = 1000
+
+// Unknown is the revision of the services without Store, which can't tell what changed
+const Unknown int64 = -1
+
+// Tombstone is a key deleted at Revision
+type Tombstone struct {
+	Key      string `json:"key" bson:"key"`
+	Revision int64  `json:"revision" bson:"revision"`
+}
+
+// Store keeps a monotonic revision per resource, increased by every change made by the services,
+// and the keys deleted with the revision of their deletion
+type Store interface {
+	Next(ctx context.Context, resource string) (int64, error)
+	// Current is 0 before the first change
+	Current(ctx context.Context, resource string) (int64, error)
+	Deleted(ctx context.Context, resource, key string, revision int64) error
+	// DeletedSince returns the keys deleted at since or after it, ErrResyncRequired when some of them
+	// may have been pruned
+	DeletedSince(ctx context.Context, resource string, since int64) ([]Tombstone, error)
+}
+
+// Write runs write, which saves its change with the revision given, between two increments of the
+// revision of resource. The readers read Current before the data, so a reader that runs during write
+// returns the revision of the change without seeing it, and its next request (since that revision,
+// or with its ETag) still gets it. Without store, write receives 0, the revision of the entities
+// saved before the revisions.
+func Write(ctx context.Context, store Store, resource string, write func(revision int64) error) error {
+	if store == nil {
+		return write(0)
+	}
+
+	revision, err := store.Next(ctx, resource)
+	if err != nil {
+		return err
+	}
+
+	if err := write(revision); err != nil {
+		return err
+	}
+
+	_, err = store.Next(ctx, resource)
+	return err
+}
+
+// ETag is the entity tag of the full list of a resource at revision
+func ETag(revision int64) string {
+	return strconv.Quote(strconv.FormatInt(revision, 10))
+}
+
+// NotModified reports whether the If-None-Match header of r has the ETag of revision
+func NotModified(r *http.Request, revision int64) bool {
+	if revision == Unknown {
+		return false
+	}
+
+	etag := ETag(revision)
+	for _, value := range strings.Split(r.Header.Get("If-None-Match"), ",") {
+		if value = strings.TrimSpace(value); value == etag || value == "W/"+etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+func deletedSince(s state, since int64) ([]Tombstone, error) {
+	if s.Pruned > 0 && since <= s.Pruned {
+		return nil, ErrResyncRequired
+	}
+
+	var output []Tombstone
+	for _, tombstone := range s.Deleted {
+		if tombstone.Revision >= since {
+			output = append(output, tombstone)
+		}
+	}
+
+	return output, nil
+}
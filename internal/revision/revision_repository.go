@@ -0,0 +1,106 @@
+package revision
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"slices"
+	"sync"
+)
+
+type state struct {
+	Revision int64       `json:"revision" bson:"revision"`
+	Deleted  []Tombstone `json:"deleted" bson:"deleted"`
+	// Pruned is the newest revision of the tombstones pruned, DeletedSince can't answer up to it
+	Pruned int64 `json:"pruned,omitempty" bson:"pruned,omitempty"`
+}
+
+// Repository keeps the revisions of the resources in a json file
+type Repository struct {
+	filePath string
+	mu       sync.Mutex
+}
+
+func NewRepository(filePath string) *Repository {
+	return &Repository{filePath: filePath}
+}
+
+func (r *Repository) Next(ctx context.Context, resource string) (int64, error) {
+	var revision int64
+	err := r.update(resource, func(s *state) {
+		s.Revision++
+		revision = s.Revision
+	})
+
+	return revision, err
+}
+
+func (r *Repository) Current(ctx context.Context, resource string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	states, err := r.read()
+	return states[resource].Revision, err
+}
+
+func (r *Repository) Deleted(ctx context.Context, resource, key string, revision int64) error {
+	return r.update(resource, func(s *state) {
+		s.Deleted = append(s.Deleted, Tombstone{Key: key, Revision: revision})
+		if pruned := len(s.Deleted) - maxTombstones; pruned > 0 {
+			s.Pruned = max(s.Pruned, s.Deleted[pruned-1].Revision)
+			s.Deleted = slices.Clone(s.Deleted[pruned:])
+		}
+	})
+}
+
+func (r *Repository) DeletedSince(ctx context.Context, resource string, since int64) ([]Tombstone, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	states, err := r.read()
+	if err != nil {
+		return nil, err
+	}
+
+	return deletedSince(states[resource], since)
+}
+
+// read must be called holding mu
+func (r *Repository) read() (map[string]state, error) {
+	b, err := os.ReadFile(r.filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make(map[string]state)
+	if len(b) == 0 {
+		return states, nil
+	}
+
+	if err := json.Unmarshal(b, &states); err != nil {
+		return nil, err
+	}
+
+	return states, nil
+}
+
+func (r *Repository) update(resource string, fn func(s *state)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	states, err := r.read()
+	if err != nil {
+		return err
+	}
+
+	s := states[resource]
+	fn(&s)
+	states[resource] = s
+
+	b, err := json.Marshal(states)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(r.filePath, b, 0644)
+}
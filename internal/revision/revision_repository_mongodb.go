@@ -0,0 +1,99 @@
+package revision
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/IsaacDSC/featureflag/pkg/mongodb"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const collectionName = mongodb.CollectionName("revisions")
+
+// MongoDBRepository keeps a document per resource, with its revision and deleted keys
+type MongoDBRepository struct {
+	collection *mongo.Collection
+	timeout    time.Duration
+}
+
+func NewMongoDBRepository(database *mongo.Database) *MongoDBRepository {
+	return &MongoDBRepository{
+		collection: database.Collection(collectionName.String()),
+		timeout:    10 * time.Second,
+	}
+}
+
+func (mr *MongoDBRepository) Next(ctx context.Context, resource string) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, mr.timeout)
+	defer cancel()
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var s state
+	err := mr.collection.FindOneAndUpdate(ctx, bson.M{"_id": resource}, bson.M{"$inc": bson.M{"revision": 1}}, opts).Decode(&s)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.Revision, nil
+}
+
+func (mr *MongoDBRepository) Current(ctx context.Context, resource string) (int64, error) {
+	s, err := mr.get(ctx, resource)
+	return s.Revision, err
+}
+
+func (mr *MongoDBRepository) Deleted(ctx context.Context, resource, key string, revision int64) error {
+	ctx, cancel := context.WithTimeout(ctx, mr.timeout)
+	defer cancel()
+
+	// appends the tombstone and prunes the oldest above maxTombstones, raising pruned to the newest of them,
+	// in one update so concurrent deletes don't lose each other
+	deleted := bson.M{"$concatArrays": bson.A{
+		bson.M{"$ifNull": bson.A{"$deleted", bson.A{}}},
+		bson.A{Tombstone{Key: key, Revision: revision}},
+	}}
+	pruned := bson.M{"$ifNull": bson.A{"$pruned", 0}}
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{"deleted": deleted}}},
+		{{Key: "$set", Value: bson.M{
+			"pruned": bson.M{"$cond": bson.A{
+				bson.M{"$gt": bson.A{bson.M{"$size": "$deleted"}, maxTombstones}},
+				bson.M{"$max": bson.A{pruned, bson.M{"$arrayElemAt": bson.A{
+					"$deleted.revision",
+					bson.M{"$subtract": bson.A{bson.M{"$size": "$deleted"}, maxTombstones + 1}},
+				}}}},
+				pruned,
+			}},
+			"deleted": bson.M{"$slice": bson.A{"$deleted", -maxTombstones}},
+		}}},
+	}
+	_, err := mr.collection.UpdateOne(ctx, bson.M{"_id": resource}, update, options.Update().SetUpsert(true))
+	return err
+}
+
+func (mr *MongoDBRepository) DeletedSince(ctx context.Context, resource string, since int64) ([]Tombstone, error) {
+	s, err := mr.get(ctx, resource)
+	if err != nil {
+		return nil, err
+	}
+
+	return deletedSince(s, since)
+}
+
+// get returns an empty state for the resources never changed
+func (mr *MongoDBRepository) get(ctx context.Context, resource string) (state, error) {
+	ctx, cancel := context.WithTimeout(ctx, mr.timeout)
+	defer cancel()
+
+	var s state
+	err := mr.collection.FindOne(ctx, bson.M{"_id": resource}).Decode(&s)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return state{}, nil
+	}
+
+	return s, err
+}
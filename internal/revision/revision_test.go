@@ -0,0 +1,136 @@
+package revision
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newRepository(t *testing.T) *Repository {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "revisions.json")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatalf("error on create file: %v", err)
+	}
+
+	return NewRepository(path)
+}
+
+func TestWrite(t *testing.T) {
+	ctx := context.Background()
+	repository := newRepository(t)
+
+	var written int64
+	err := Write(ctx, repository, "featureflag", func(revision int64) error {
+		written = revision
+
+		// a reader during the write sees the revision of the change
+		if current, _ := repository.Current(ctx, "featureflag"); current != revision {
+			t.Errorf("Current() during write = %d, want %d", current, revision)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	if current, _ := repository.Current(ctx, "featureflag"); written != 1 || current != 2 {
+		t.Errorf("written %d and Current() = %d, want 1 and 2", written, current)
+	}
+
+	if current, _ := repository.Current(ctx, "contenthub"); current != 0 {
+		t.Errorf("Current() of another resource = %d, want 0", current)
+	}
+
+	failed := errors.New("failed")
+	if err := Write(ctx, repository, "featureflag", func(int64) error { return failed }); !errors.Is(err, failed) {
+		t.Errorf("Write() error = %v, want %v", err, failed)
+	}
+
+	if err := Write(ctx, nil, "featureflag", func(revision int64) error {
+		if revision != 0 {
+			t.Errorf("revision without store = %d, want 0", revision)
+		}
+		return nil
+	}); err != nil {
+		t.Errorf("Write() without store error = %v", err)
+	}
+}
+
+func TestRepository_DeletedSince(t *testing.T) {
+	ctx := context.Background()
+	repository := newRepository(t)
+
+	for i, key := range []string{"a", "b", "c"} {
+		if err := repository.Deleted(ctx, "featureflag", key, int64(i+1)); err != nil {
+			t.Fatalf("Deleted() error = %v", err)
+		}
+	}
+
+	got, err := repository.DeletedSince(ctx, "featureflag", 2)
+	if err != nil {
+		t.Fatalf("DeletedSince() error = %v", err)
+	}
+
+	if len(got) != 2 || got[0].Key != "b" || got[1].Key != "c" {
+		t.Errorf("DeletedSince() = %+v, want b and c", got)
+	}
+}
+
+func TestRepository_DeletedSince_Pruned(t *testing.T) {
+	ctx := context.Background()
+	repository := newRepository(t)
+
+	for i := range maxTombstones + 2 {
+		if err := repository.Deleted(ctx, "featureflag", fmt.Sprint(i), int64(i+1)); err != nil {
+			t.Fatalf("Deleted() error = %v", err)
+		}
+	}
+
+	// the tombstones of revisions 1 and 2 were pruned
+	if _, err := repository.DeletedSince(ctx, "featureflag", 2); !errors.Is(err, ErrResyncRequired) {
+		t.Errorf("DeletedSince(2) error = %v, want %v", err, ErrResyncRequired)
+	}
+
+	got, err := repository.DeletedSince(ctx, "featureflag", 3)
+	if err != nil {
+		t.Fatalf("DeletedSince(3) error = %v", err)
+	}
+
+	if len(got) != maxTombstones || got[0].Revision != 3 {
+		t.Errorf("DeletedSince(3) = %d tombstones from %+v, want %d from revision 3", len(got), got[0], maxTombstones)
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		revision    int64
+		want        bool
+	}{
+		{name: "same revision", ifNoneMatch: `"3"`, revision: 3, want: true},
+		{name: "weak and list", ifNoneMatch: `"1", W/"3"`, revision: 3, want: true},
+		{name: "other revision", ifNoneMatch: `"2"`, revision: 3, want: false},
+		{name: "without header", revision: 3, want: false},
+		{name: "unknown revision", ifNoneMatch: `"-1"`, revision: Unknown, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/featureflags", nil)
+			if tt.ifNoneMatch != "" {
+				r.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+
+			if got := NotModified(r, tt.revision); got != tt.want {
+				t.Errorf("NotModified() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,27 +2,54 @@ package sdknotifier
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/IsaacDSC/featureflag/pkg/ctxlog"
 	"github.com/IsaacDSC/featureflag/pkg/pubsub"
 )
 
+const lastEventIDHeader = "Last-Event-ID"
+
 type Subscriber interface {
-	Listener(ctx context.Context, channel string, fn pubsub.Handler)
+	Listener(ctx context.Context, channel string, fn pubsub.Handler) error
+}
+
+// Replayer is implemented by subscribers that know the id of their newest event,
+// a listener started from it with pubsub.WithLastEventID loses nothing published after it
+type Replayer interface {
+	LastID(ctx context.Context, channel string) (string, error)
+}
+
+// StreamReplayer is implemented by subscribers that retain their events, as redis streams. The clients whose
+// Last-Event-ID is no longer in the history of the hub, as after a restart of the node, resume from them.
+type StreamReplayer interface {
+	// CanReplay reports whether every event after lastEventID is still retained
+	CanReplay(ctx context.Context, channel string, lastEventID string) (bool, error)
+	// Range returns the retained events after afterID up to untilID
+	Range(ctx context.Context, channel string, afterID, untilID string) ([]pubsub.Entry, error)
 }
 
+// Snapshot returns the full state of a resource, sent when the events missed can't be replayed
+type Snapshot func(ctx context.Context) (any, error)
+
 type SdkNotifyHandler struct {
-	routes map[string]func(w http.ResponseWriter, r *http.Request)
-	sub    Subscriber
+	routes    map[string]func(w http.ResponseWriter, r *http.Request)
+	hub       *Hub
+	heartbeat time.Duration
+	snapshots map[string]Snapshot
 }
 
-func NewSdkNotifyHandler(sub Subscriber) *SdkNotifyHandler {
+func NewSdkNotifyHandler(hub *Hub, snapshots map[string]Snapshot) *SdkNotifyHandler {
 	h := new(SdkNotifyHandler)
-	h.sub = sub
+	h.hub = hub
+	h.heartbeat = hub.cfg.Heartbeat
+	h.snapshots = snapshots
 	h.routes = map[string]func(w http.ResponseWriter, r *http.Request){
-		"GET /events/{resource}": h.event,
+		"GET /events/{resource}":             h.event,
+		"GET /events/{resource}/connections": h.connections,
 	}
 
 	return h
@@ -38,25 +65,158 @@ func (h SdkNotifyHandler) event(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Connection", "keep-alive")
 
 	ctx := r.Context()
-	resource := r.PathValue("resource")
-	if resource == "" {
-		resource = "default"
-	}
+	resource := resourceName(r)
 
 	l := ctxlog.GetLogger(ctx)
-	l.Debug("connected sse", "resource", resource)
+	lastEventID := r.Header.Get(lastEventIDHeader)
+	l.Debug("connected sse", "resource", resource, "last_event_id", lastEventID)
 
-	h.sub.Listener(ctx, resource, func(ctx context.Context, msg pubsub.Msg) error {
-		// var body Msg
-		// if err := msg.ToJson(&body); err != nil {
-		// 	return fmt.Errorf("error parser to json: %w", err)
-		// }
-		l.Info("SENT MSG")
+	subscription, err := h.hub.Subscribe(ctx, resource, lastEventID)
+	if err != nil {
+		l.Error("error on subscribe sse", "resource", resource, "error", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	defer h.hub.Unsubscribe(resource, subscription)
 
-		fmt.Fprintf(w, "data: %s\n\n", string(msg))
-		w.(http.Flusher).Flush()
+	if err := h.resume(ctx, w, resource, subscription); err != nil {
+		l.Error("error on resume sse", "resource", resource, "error", err)
+		return
+	}
 
-		return nil
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case event := <-subscription.Events:
+			writeEvent(w, event.ID, event.Event, event.Data)
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			w.(http.Flusher).Flush()
+		case <-subscription.Done:
+			l.Debug("sse client disconnected by hub", "resource", resource)
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (h SdkNotifyHandler) connections(w http.ResponseWriter, r *http.Request) {
+	resource := resourceName(r)
+
+	b, err := json.Marshal(map[string]any{
+		"resource":    resource,
+		"connections": h.hub.Connections(resource),
 	})
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
+
+// resume sends the events missed since Last-Event-ID when the hub still has them,
+// otherwise a snapshot of the resource identified by the newest event id.
+func (h SdkNotifyHandler) resume(ctx context.Context, w http.ResponseWriter, resource string, subscription *Subscription) error {
+	if subscription.Resumed {
+		for _, event := range subscription.Replay {
+			writeEvent(w, event.ID, event.Event, event.Data)
+		}
+		return nil
+	}
+
+	b, ok, err := MarshalSnapshot(ctx, h.snapshots, resource, nil)
+	if err != nil || !ok {
+		return err
+	}
+
+	writeEvent(w, subscription.LastID, "snapshot", b)
+
+	return nil
+}
+
+// MarshalSnapshot returns the snapshot of resource as JSON with only the items matching keys,
+// false when the resource has none
+func MarshalSnapshot(ctx context.Context, snapshots map[string]Snapshot, resource string, keys map[string]bool) ([]byte, bool, error) {
+	snapshot, ok := snapshots[resource]
+	if !ok {
+		return nil, false, nil
+	}
+
+	state, err := snapshot(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("error on get snapshot: %w", err)
+	}
+
+	b, err := json.Marshal(state)
+	if err != nil {
+		return nil, false, fmt.Errorf("error on marshal snapshot: %w", err)
+	}
+
+	if len(keys) == 0 {
+		return b, true, nil
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(b, &items); err != nil {
+		return nil, false, fmt.Errorf("error on filter snapshot: %w", err)
+	}
+
+	filtered := make([]json.RawMessage, 0, len(items))
+	for _, item := range items {
+		if MatchKeys(keys, item) {
+			filtered = append(filtered, item)
+		}
+	}
+
+	if b, err = json.Marshal(filtered); err != nil {
+		return nil, false, fmt.Errorf("error on marshal snapshot: %w", err)
+	}
+
+	return b, true, nil
+}
+
+// MatchKeys reports whether the payload of a flag (flag_name) or content (key) is in keys, always true when keys is empty
+func MatchKeys(keys map[string]bool, data []byte) bool {
+	if len(keys) == 0 {
+		return true
+	}
+
+	var payload struct {
+		FlagName string `json:"flag_name"`
+		Key      string `json:"key"`
+	}
+
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return false
+	}
+
+	return keys[payload.FlagName] || keys[payload.Key]
+}
+
+func resourceName(r *http.Request) string {
+	resource := r.PathValue("resource")
+	if resource == "" {
+		return "default"
+	}
+
+	return resource
+}
+
+func writeEvent(w http.ResponseWriter, id, event string, data []byte) {
+	if id != "" {
+		fmt.Fprintf(w, "id: %s\n", id)
+	}
+
+	if event != "" {
+		fmt.Fprintf(w, "event: %s\n", event)
+	}
 
+	fmt.Fprintf(w, "data: %s\n\n", string(data))
+	w.(http.Flusher).Flush()
 }
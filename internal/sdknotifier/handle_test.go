@@ -0,0 +1,118 @@
+package sdknotifier
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IsaacDSC/featureflag/pkg/ctxlog"
+	"github.com/IsaacDSC/featureflag/sdk/sse"
+)
+
+func TestSdkNotifyHandler_event(t *testing.T) {
+	sub := newFakeSubscriber()
+	hub := NewHub(sub, HubConfig{ClientBuffer: 10, SlowConsumer: SlowConsumerDrop, Heartbeat: 50 * time.Millisecond, History: 10})
+	defer hub.Close()
+
+	snapshots := map[string]Snapshot{
+		"featureflag": func(ctx context.Context) (any, error) {
+			return []string{"snapshot"}, nil
+		},
+	}
+
+	handler := NewSdkNotifyHandler(hub, snapshots)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.SetPathValue("resource", "featureflag")
+		handler.event(w, r.WithContext(ctxlog.SetLogger(r.Context(), slog.Default())))
+	}))
+	defer server.Close()
+
+	connect := func(lastEventID string) (*sse.Reader, func()) {
+		ctx, cancel := context.WithCancel(context.Background())
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("connect error = %v", err)
+		}
+
+		return sse.NewReader(resp.Body), func() {
+			cancel()
+			resp.Body.Close()
+		}
+	}
+
+	next := func(reader *sse.Reader) sse.Event {
+		event, err := reader.Next()
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		return event
+	}
+
+	// primeira conexão recebe snapshot identificado pelo id mais recente
+	first, closeFirst := connect("")
+	if got := next(first); got.Event != "snapshot" || got.ID != "0-0" || got.Data != `["snapshot"]` {
+		t.Errorf("first event = %+v, want snapshot with id 0-0", got)
+	}
+
+	sub.events <- `{"flag_name":"a"}`
+	sub.events <- `{"flag_name":"b"}`
+
+	if got := next(first); got.ID != "1-0" || got.Data != `{"flag_name":"a"}` {
+		t.Errorf("event = %+v, want 1-0", got)
+	}
+	if got := next(first); got.ID != "2-0" {
+		t.Errorf("event = %+v, want 2-0", got)
+	}
+	closeFirst()
+
+	// reconexão com id conhecido recebe apenas os eventos perdidos
+	resumed, closeResumed := connect("1-0")
+	defer closeResumed()
+	if got := next(resumed); got.Event != "" || got.ID != "2-0" || got.Data != `{"flag_name":"b"}` {
+		t.Errorf("replayed event = %+v, want 2-0", got)
+	}
+
+	// reconexão com id desconhecido recebe snapshot
+	unknown, closeUnknown := connect("99-0")
+	defer closeUnknown()
+	if got := next(unknown); got.Event != "snapshot" || got.ID != "2-0" {
+		t.Errorf("event = %+v, want snapshot with id 2-0", got)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for hub.Connections("featureflag") != 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := hub.Connections("featureflag"); got != 2 {
+		t.Errorf("Connections() = %d, want 2", got)
+	}
+}
+
+func TestSdkNotifyHandler_heartbeat(t *testing.T) {
+	hub := NewHub(newFakeSubscriber(), HubConfig{ClientBuffer: 10, SlowConsumer: SlowConsumerDrop, Heartbeat: 20 * time.Millisecond, History: 10})
+	defer hub.Close()
+
+	handler := NewSdkNotifyHandler(hub, nil)
+
+	ctx, cancel := context.WithTimeout(ctxlog.SetLogger(context.Background(), slog.Default()), 100*time.Millisecond)
+	defer cancel()
+
+	req := httptest.NewRequest(http.MethodGet, "/events/featureflag", nil).WithContext(ctx)
+	req.SetPathValue("resource", "featureflag")
+	rec := httptest.NewRecorder()
+
+	handler.event(rec, req)
+
+	if !strings.Contains(rec.Body.String(), ": heartbeat\n\n") {
+		t.Errorf("event() body = %q, want heartbeat comments", rec.Body.String())
+	}
+}
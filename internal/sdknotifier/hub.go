@@ -0,0 +1,386 @@
+package sdknotifier
+
+import (
+	"context"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/IsaacDSC/featureflag/internal/outbox"
+	"github.com/IsaacDSC/featureflag/pkg/pubsub"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	SlowConsumerDrop       = "drop"
+	SlowConsumerDisconnect = "disconnect"
+)
+
+const (
+	listenerMinBackoff = time.Second
+	listenerMaxBackoff = 30 * time.Second
+	defaultHeartbeat   = 15 * time.Second
+)
+
+type HubConfig struct {
+	// ClientBuffer is how many events may be queued for a client before the slow consumer policy applies
+	ClientBuffer int
+	// SlowConsumer is SlowConsumerDrop (the event is discarded for that client)
+	// or SlowConsumerDisconnect (the client is closed and resumes with Last-Event-ID)
+	SlowConsumer string
+	// Heartbeat is the interval between SSE comments sent to keep idle connections open, 15s when not positive
+	Heartbeat time.Duration
+	// History is how many events per resource are kept to resume clients with Last-Event-ID
+	History int
+}
+
+// DeletedEvent is the Event of the deletes, whose Data has the key deleted
+const DeletedEvent = "deleted"
+
+// deletedIDPrefix tells the ids of the deletes apart from the ids of the changes, as both come from
+// different channels
+const deletedIDPrefix = "deleted:"
+
+type Event struct {
+	ID string
+	// Event is empty for the changes and DeletedEvent for the deletes
+	Event string
+	Data  []byte
+}
+
+type client struct {
+	events chan Event
+	done   chan struct{}
+}
+
+// Subscription is a client registered on a resource of the hub
+type Subscription struct {
+	Events <-chan Event
+	// Done is closed when the hub disconnects the client
+	Done <-chan struct{}
+	// Replay holds the events after the Last-Event-ID of the client, to be sent before Events
+	Replay []Event
+	// Resumed is false when Last-Event-ID was empty or is neither in the history nor retained by the
+	// subscriber, then the client needs a snapshot with LastID as its id
+	Resumed bool
+	LastID  string
+
+	client *client
+}
+
+type resource struct {
+	clients map[*client]struct{}
+	// startID is the id right before the first event of history
+	startID string
+	history []Event
+	// changeID is the id of the newest change, the position of the listener of the changes
+	changeID string
+	// deletedID is the id of the newest delete in its channel, the position of the listener of the deletes
+	deletedID string
+}
+
+// Hub keeps one Listener per resource for the whole process and fans the events out to
+// the SSE clients registered on it
+type Hub struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	sub    Subscriber
+	cfg    HubConfig
+
+	mu        sync.Mutex
+	resources map[string]*resource
+	wg        sync.WaitGroup
+	metrics   metric.Registration
+}
+
+func NewHub(sub Subscriber, cfg HubConfig) *Hub {
+	// the tickers of the handlers panic with a zero interval
+	if cfg.Heartbeat <= 0 {
+		cfg.Heartbeat = defaultHeartbeat
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	h := &Hub{
+		ctx:       ctx,
+		cancel:    cancel,
+		sub:       sub,
+		cfg:       cfg,
+		resources: make(map[string]*resource),
+	}
+	h.registerMetrics()
+
+	return h
+}
+
+// Subscribe registers a client on name, starting the resource listener on the first call.
+func (h *Hub) Subscribe(ctx context.Context, name string, lastEventID string) (*Subscription, error) {
+	h.mu.Lock()
+	res, ok := h.resources[name]
+	h.mu.Unlock()
+
+	if !ok {
+		var err error
+		if res, err = h.start(ctx, name); err != nil {
+			return nil, err
+		}
+	}
+
+	c := &client{
+		events: make(chan Event, h.cfg.ClientBuffer),
+		done:   make(chan struct{}),
+	}
+
+	h.mu.Lock()
+
+	subscription := &Subscription{
+		Events: c.events,
+		Done:   c.done,
+		LastID: res.lastID(),
+		client: c,
+	}
+
+	if lastEventID != "" {
+		subscription.Replay, subscription.Resumed = res.after(lastEventID)
+	}
+	deletedID := res.deletedID
+
+	res.clients[c] = struct{}{}
+	h.mu.Unlock()
+
+	// the client is registered, the events after LastID reach it by Events while the older ones are read
+	if lastEventID != "" && !subscription.Resumed {
+		subscription.Replay, subscription.Resumed = h.replay(ctx, name, lastEventID, subscription.LastID, deletedID)
+	}
+
+	return subscription, nil
+}
+
+// replay reads the changes after lastEventID up to untilID and the deletes up to untilDeletedID from the
+// subscriber, false when it doesn't retain them
+func (h *Hub) replay(ctx context.Context, name string, lastEventID, untilID, untilDeletedID string) ([]Event, bool) {
+	replayer, ok := h.sub.(StreamReplayer)
+	if !ok {
+		return nil, false
+	}
+
+	if ok, err := replayer.CanReplay(ctx, name, lastEventID); err != nil || !ok {
+		if err != nil {
+			log.Printf("error on check replay of %s from %s: %v\n", name, lastEventID, err)
+		}
+		return nil, false
+	}
+
+	entries, err := replayer.Range(ctx, name, lastEventID, untilID)
+	if err != nil {
+		log.Printf("error on replay %s from %s: %v\n", name, lastEventID, err)
+		return nil, false
+	}
+
+	// the ids of the stream of the deletes have the same clock as the ones of the changes
+	deletes, err := replayer.Range(ctx, outbox.DeletedChannel(name), lastEventID, untilDeletedID)
+	if err != nil {
+		log.Printf("error on replay the deletes of %s from %s: %v\n", name, lastEventID, err)
+		return nil, false
+	}
+
+	events := make([]Event, 0, len(entries)+len(deletes))
+	for _, entry := range entries {
+		events = append(events, Event{ID: entry.ID, Data: entry.Msg})
+	}
+
+	for _, entry := range deletes {
+		events = append(events, Event{ID: entry.ID, Event: DeletedEvent, Data: entry.Msg})
+	}
+
+	slices.SortStableFunc(events, func(a, b Event) int {
+		cmp, _ := pubsub.CompareEventID(a.ID, b.ID)
+		return cmp
+	})
+
+	for i := range events {
+		if events[i].Event == DeletedEvent {
+			events[i].ID = deletedIDPrefix + events[i].ID
+		}
+	}
+
+	return events, true
+}
+
+// Unsubscribe removes the client, it is safe to call after the hub disconnected it
+func (h *Hub) Unsubscribe(name string, subscription *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	res, ok := h.resources[name]
+	if !ok {
+		return
+	}
+
+	if _, ok := res.clients[subscription.client]; ok {
+		delete(res.clients, subscription.client)
+		close(subscription.client.done)
+	}
+}
+
+// Connections returns how many clients are registered on name
+func (h *Hub) Connections(name string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if res, ok := h.resources[name]; ok {
+		return len(res.clients)
+	}
+
+	return 0
+}
+
+// Close stops every listener and disconnects all clients
+func (h *Hub) Close() {
+	h.cancel()
+	h.wg.Wait()
+
+	if h.metrics != nil {
+		h.metrics.Unregister()
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, res := range h.resources {
+		for c := range res.clients {
+			delete(res.clients, c)
+			close(c.done)
+		}
+	}
+}
+
+func (h *Hub) start(ctx context.Context, name string) (*resource, error) {
+	startID, deletedID := "", ""
+	if replayer, ok := h.sub.(Replayer); ok {
+		id, err := replayer.LastID(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		startID = id
+
+		if deletedID, err = replayer.LastID(ctx, outbox.DeletedChannel(name)); err != nil {
+			return nil, err
+		}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// another client may have started it meanwhile
+	if res, ok := h.resources[name]; ok {
+		return res, nil
+	}
+
+	res := &resource{
+		clients:   make(map[*client]struct{}),
+		startID:   startID,
+		changeID:  startID,
+		deletedID: deletedID,
+	}
+	h.resources[name] = res
+
+	h.wg.Add(2)
+	go h.listen(name, name, "", startID)
+	go h.listen(name, outbox.DeletedChannel(name), DeletedEvent, deletedID)
+
+	return res, nil
+}
+
+// listen keeps the listener of channel running until the hub is closed, restarting it from the last
+// event received when it fails, and broadcasts its messages to the clients of name as event
+func (h *Hub) listen(name, channel, event string, lastID string) {
+	defer h.wg.Done()
+
+	backoff := listenerMinBackoff
+	for {
+		ctx := h.ctx
+		if lastID != "" {
+			ctx = pubsub.WithLastEventID(ctx, lastID)
+		}
+
+		err := h.sub.Listener(ctx, channel, func(ctx context.Context, msg pubsub.Msg) error {
+			lastID = pubsub.EventID(ctx)
+			backoff = listenerMinBackoff
+
+			h.broadcast(name, Event{ID: lastID, Event: event, Data: msg})
+			return nil
+		})
+
+		if h.ctx.Err() != nil {
+			return
+		}
+
+		log.Printf("listener of %s stopped, restarting in %v: %v\n", channel, backoff, err)
+
+		select {
+		case <-h.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, listenerMaxBackoff)
+	}
+}
+
+func (h *Hub) broadcast(name string, event Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	res := h.resources[name]
+	if event.Event == DeletedEvent {
+		res.deletedID = event.ID
+		event.ID = deletedIDPrefix + event.ID
+	} else {
+		res.changeID = event.ID
+	}
+	res.append(event, h.cfg.History)
+
+	for c := range res.clients {
+		select {
+		case c.events <- event:
+		default:
+			if h.cfg.SlowConsumer == SlowConsumerDisconnect {
+				log.Printf("disconnecting slow sse client of %s\n", name)
+				delete(res.clients, c)
+				close(c.done)
+				continue
+			}
+
+			log.Printf("dropping event %s for slow sse client of %s\n", event.ID, name)
+		}
+	}
+}
+
+// lastID is the id of the newest change, the clients resumed from it get the deletes after it from history
+func (r *resource) lastID() string {
+	return r.changeID
+}
+
+func (r *resource) append(event Event, limit int) {
+	r.history = append(r.history, event)
+	if len(r.history) > limit {
+		r.startID = r.history[0].ID
+		r.history = slices.Delete(r.history, 0, 1)
+	}
+}
+
+// after returns the events after id, false when id is unknown or was evicted from history
+func (r *resource) after(id string) ([]Event, bool) {
+	if id == r.startID {
+		return slices.Clone(r.history), true
+	}
+
+	for i := range r.history {
+		if r.history[i].ID == id {
+			return slices.Clone(r.history[i+1:]), true
+		}
+	}
+
+	return nil, false
+}
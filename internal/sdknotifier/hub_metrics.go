@@ -0,0 +1,32 @@
+package sdknotifier
+
+import (
+	"context"
+
+	"github.com/IsaacDSC/featureflag/pkg/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+var connectionsGauge, _ = telemetry.Meter().Int64ObservableGauge("featureflag.sse.connections",
+	metric.WithDescription("Clients connected to the events of each resource, by SSE, WebSocket or gRPC"),
+	metric.WithUnit("{connection}"))
+
+// registerMetrics reports the connections of the hub until Close
+func (h *Hub) registerMetrics() {
+	registration, err := telemetry.Meter().RegisterCallback(h.observe, connectionsGauge)
+	if err == nil {
+		h.metrics = registration
+	}
+}
+
+func (h *Hub) observe(ctx context.Context, o metric.Observer) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for name, res := range h.resources {
+		o.ObserveInt64(connectionsGauge, int64(len(res.clients)), metric.WithAttributes(attribute.String("resource", name)))
+	}
+
+	return nil
+}
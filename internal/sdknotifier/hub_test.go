@@ -0,0 +1,388 @@
+package sdknotifier
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/IsaacDSC/featureflag/internal/outbox"
+	"github.com/IsaacDSC/featureflag/pkg/pubsub"
+	"github.com/IsaacDSC/featureflag/pkg/telemetry"
+)
+
+type fakeSubscriber struct {
+	events   chan string
+	deleted  chan string
+	listened chan string
+}
+
+func newFakeSubscriber() *fakeSubscriber {
+	return &fakeSubscriber{
+		events:   make(chan string),
+		deleted:  make(chan string),
+		listened: make(chan string, 10),
+	}
+}
+
+func (s *fakeSubscriber) Listener(ctx context.Context, channel string, fn pubsub.Handler) error {
+	events := s.events
+	if channel == outbox.DeletedChannel("featureflag") {
+		events = s.deleted
+	} else {
+		s.listened <- pubsub.LastEventID(ctx)
+	}
+
+	var seq int
+	for {
+		select {
+		case event := <-events:
+			seq++
+			fn(pubsub.WithEventID(ctx, fmt.Sprintf("%d-0", seq)), []byte(event))
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (s *fakeSubscriber) LastID(ctx context.Context, channel string) (string, error) {
+	return "0-0", nil
+}
+
+func receive(t *testing.T, subscription *Subscription) Event {
+	t.Helper()
+
+	select {
+	case event := <-subscription.Events:
+		return event
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting event")
+		return Event{}
+	}
+}
+
+func TestHub_Broadcast(t *testing.T) {
+	sub := newFakeSubscriber()
+	hub := NewHub(sub, HubConfig{ClientBuffer: 10, SlowConsumer: SlowConsumerDrop, History: 10})
+	defer hub.Close()
+
+	ctx := context.Background()
+	first, err := hub.Subscribe(ctx, "featureflag", "")
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	second, err := hub.Subscribe(ctx, "featureflag", "")
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	if got := <-sub.listened; got != "0-0" {
+		t.Errorf("listener started from %q, want %q", got, "0-0")
+	}
+
+	if got := hub.Connections("featureflag"); got != 2 {
+		t.Errorf("Connections() = %d, want 2", got)
+	}
+
+	sub.events <- `{"flag_name":"a"}`
+
+	for _, subscription := range []*Subscription{first, second} {
+		if got := receive(t, subscription); got.ID != "1-0" || string(got.Data) != `{"flag_name":"a"}` {
+			t.Errorf("received %+v, want event 1-0", got)
+		}
+	}
+
+	hub.Unsubscribe("featureflag", first)
+	if got := hub.Connections("featureflag"); got != 1 {
+		t.Errorf("Connections() after Unsubscribe = %d, want 1", got)
+	}
+
+	select {
+	case <-sub.listened:
+		t.Error("a second listener was started for the same resource")
+	default:
+	}
+}
+
+func TestHub_Broadcast_Deleted(t *testing.T) {
+	sub := newFakeSubscriber()
+	hub := NewHub(sub, HubConfig{ClientBuffer: 10, SlowConsumer: SlowConsumerDrop, History: 10})
+	defer hub.Close()
+
+	ctx := context.Background()
+	subscription, err := hub.Subscribe(ctx, "featureflag", "")
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	sub.events <- `{"flag_name":"a"}`
+	receive(t, subscription)
+
+	sub.deleted <- `{"key":"a"}`
+	got := receive(t, subscription)
+	if got.ID != "deleted:1-0" || got.Event != DeletedEvent || string(got.Data) != `{"key":"a"}` {
+		t.Errorf("received %+v, want the delete of a", got)
+	}
+
+	// the delete is resumed from history while LastID stays the one of the changes
+	resumed, err := hub.Subscribe(ctx, "featureflag", "1-0")
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	defer hub.Unsubscribe("featureflag", resumed)
+
+	if !resumed.Resumed || len(resumed.Replay) != 1 || resumed.Replay[0].ID != "deleted:1-0" {
+		t.Errorf("Resumed = %v with %+v, want the delete of a", resumed.Resumed, resumed.Replay)
+	}
+
+	if resumed.LastID != "1-0" {
+		t.Errorf("LastID = %q, want %q", resumed.LastID, "1-0")
+	}
+}
+
+func TestNewHub_Heartbeat(t *testing.T) {
+	hub := NewHub(newFakeSubscriber(), HubConfig{ClientBuffer: 10, History: 10})
+	defer hub.Close()
+
+	if hub.cfg.Heartbeat != defaultHeartbeat {
+		t.Errorf("Heartbeat = %v, want %v", hub.cfg.Heartbeat, defaultHeartbeat)
+	}
+}
+
+func TestHub_Resume(t *testing.T) {
+	sub := newFakeSubscriber()
+	hub := NewHub(sub, HubConfig{ClientBuffer: 10, SlowConsumer: SlowConsumerDrop, History: 2})
+	defer hub.Close()
+
+	ctx := context.Background()
+	watcher, err := hub.Subscribe(ctx, "featureflag", "")
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	for _, event := range []string{"a", "b", "c"} {
+		sub.events <- event
+		receive(t, watcher)
+	}
+
+	tests := []struct {
+		name        string
+		lastEventID string
+		wantResumed bool
+		wantReplay  []string
+	}{
+		{name: "id in history", lastEventID: "2-0", wantResumed: true, wantReplay: []string{"c"}},
+		{name: "newest id", lastEventID: "3-0", wantResumed: true, wantReplay: []string{}},
+		{name: "id right before history", lastEventID: "1-0", wantResumed: true, wantReplay: []string{"b", "c"}},
+		{name: "evicted id", lastEventID: "0-0", wantResumed: false},
+		{name: "unknown id", lastEventID: "99-0", wantResumed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subscription, err := hub.Subscribe(ctx, "featureflag", tt.lastEventID)
+			if err != nil {
+				t.Fatalf("Subscribe() error = %v", err)
+			}
+			defer hub.Unsubscribe("featureflag", subscription)
+
+			if subscription.Resumed != tt.wantResumed {
+				t.Fatalf("Resumed = %v, want %v", subscription.Resumed, tt.wantResumed)
+			}
+
+			if subscription.LastID != "3-0" {
+				t.Errorf("LastID = %q, want %q", subscription.LastID, "3-0")
+			}
+
+			if !tt.wantResumed {
+				return
+			}
+
+			if len(subscription.Replay) != len(tt.wantReplay) {
+				t.Fatalf("Replay has %d events, want %d", len(subscription.Replay), len(tt.wantReplay))
+			}
+
+			for i, want := range tt.wantReplay {
+				if string(subscription.Replay[i].Data) != want {
+					t.Errorf("Replay[%d] = %s, want %s", i, subscription.Replay[i].Data, want)
+				}
+			}
+		})
+	}
+}
+
+// streamSubscriber retains the events after 0-0 as a redis stream trimmed to its oldest ones
+type streamSubscriber struct {
+	*fakeSubscriber
+	retained []pubsub.Entry
+	deletes  []pubsub.Entry
+}
+
+func (s *streamSubscriber) LastID(ctx context.Context, channel string) (string, error) {
+	if channel == outbox.DeletedChannel("featureflag") && len(s.deletes) > 0 {
+		return s.deletes[len(s.deletes)-1].ID, nil
+	}
+
+	return "0-0", nil
+}
+
+func (s *streamSubscriber) CanReplay(ctx context.Context, channel string, lastEventID string) (bool, error) {
+	cmp, err := pubsub.CompareEventID(lastEventID, s.retained[0].ID)
+	return err == nil && cmp >= 0, nil
+}
+
+func (s *streamSubscriber) Range(ctx context.Context, channel string, afterID, untilID string) ([]pubsub.Entry, error) {
+	retained := s.retained
+	if channel == outbox.DeletedChannel("featureflag") {
+		retained = s.deletes
+	}
+
+	var entries []pubsub.Entry
+	for _, entry := range retained {
+		after, _ := pubsub.CompareEventID(entry.ID, afterID)
+		until, _ := pubsub.CompareEventID(entry.ID, untilID)
+		if after > 0 && until <= 0 {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
+
+func TestHub_Resume_Stream(t *testing.T) {
+	sub := &streamSubscriber{
+		fakeSubscriber: newFakeSubscriber(),
+		retained:       []pubsub.Entry{{ID: "1-0", Msg: []byte("a")}, {ID: "2-0", Msg: []byte("b")}, {ID: "3-0", Msg: []byte("c")}},
+		deletes:        []pubsub.Entry{{ID: "2-5", Msg: []byte(`{"key":"x"}`)}},
+	}
+	hub := NewHub(sub, HubConfig{ClientBuffer: 10, SlowConsumer: SlowConsumerDrop, History: 1})
+	defer hub.Close()
+
+	ctx := context.Background()
+	watcher, err := hub.Subscribe(ctx, "featureflag", "")
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	for _, event := range []string{"a", "b", "c"} {
+		sub.events <- event
+		receive(t, watcher)
+	}
+
+	// 1-0 was evicted from the history of the hub but is retained by the stream
+	subscription, err := hub.Subscribe(ctx, "featureflag", "1-0")
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	defer hub.Unsubscribe("featureflag", subscription)
+
+	// the delete retained by its own stream is merged between the changes by its id
+	want := []Event{
+		{ID: "2-0", Data: []byte("b")},
+		{ID: "deleted:2-5", Event: DeletedEvent, Data: []byte(`{"key":"x"}`)},
+		{ID: "3-0", Data: []byte("c")},
+	}
+	if !subscription.Resumed || len(subscription.Replay) != len(want) {
+		t.Fatalf("Resumed = %v with %d events, want the %d events after 1-0", subscription.Resumed, len(subscription.Replay), len(want))
+	}
+
+	for i := range want {
+		got := subscription.Replay[i]
+		if got.ID != want[i].ID || got.Event != want[i].Event || string(got.Data) != string(want[i].Data) {
+			t.Errorf("Replay[%d] = %s %s %s, want %s %s %s", i, got.ID, got.Event, got.Data, want[i].ID, want[i].Event, want[i].Data)
+		}
+	}
+
+	trimmed, err := hub.Subscribe(ctx, "featureflag", "0-5")
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	defer hub.Unsubscribe("featureflag", trimmed)
+
+	if trimmed.Resumed {
+		t.Error("Resumed = true from an id trimmed from the stream")
+	}
+}
+
+func TestHub_SlowConsumer(t *testing.T) {
+	tests := []struct {
+		name           string
+		policy         string
+		wantDisconnect bool
+	}{
+		{name: "drop keeps the client", policy: SlowConsumerDrop, wantDisconnect: false},
+		{name: "disconnect closes the client", policy: SlowConsumerDisconnect, wantDisconnect: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := newFakeSubscriber()
+			hub := NewHub(sub, HubConfig{ClientBuffer: 1, SlowConsumer: tt.policy, History: 10})
+			defer hub.Close()
+
+			slow, err := hub.Subscribe(context.Background(), "featureflag", "")
+			if err != nil {
+				t.Fatalf("Subscribe() error = %v", err)
+			}
+
+			sub.events <- "a"
+			sub.events <- "b"
+			// garante que o segundo evento foi processado pelo hub
+			sub.events <- "c"
+
+			select {
+			case <-slow.Done:
+				if !tt.wantDisconnect {
+					t.Error("slow client was disconnected with drop policy")
+				}
+			default:
+				if tt.wantDisconnect {
+					t.Error("slow client was not disconnected")
+				}
+			}
+
+			if got := receive(t, slow); string(got.Data) != "a" {
+				t.Errorf("received %s, want the buffered event a", got.Data)
+			}
+
+			wantConnections := 1
+			if tt.wantDisconnect {
+				wantConnections = 0
+			}
+
+			if got := hub.Connections("featureflag"); got != wantConnections {
+				t.Errorf("Connections() = %d, want %d", got, wantConnections)
+			}
+
+			// Unsubscribe depois de desconectado não deve causar panic
+			hub.Unsubscribe("featureflag", slow)
+		})
+	}
+}
+
+func TestHub_Metrics(t *testing.T) {
+	memory := telemetry.NewInMemory()
+	memory.Install()
+
+	hub := NewHub(newFakeSubscriber(), HubConfig{ClientBuffer: 10, SlowConsumer: SlowConsumerDrop, History: 10})
+	defer hub.Close()
+
+	ctx := context.Background()
+	for _, name := range []string{"featureflag", "featureflag", "contenthub"} {
+		if _, err := hub.Subscribe(ctx, name, ""); err != nil {
+			t.Fatalf("Subscribe() error = %v", err)
+		}
+	}
+
+	for resource, want := range map[string]int64{"featureflag": 2, "contenthub": 1} {
+		got, err := memory.Sum(ctx, "featureflag.sse.connections", map[string]string{"resource": resource})
+		if err != nil {
+			t.Fatalf("Sum() error = %v", err)
+		}
+
+		if got != want {
+			t.Errorf("connections of %s = %d, want %d", resource, got, want)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package sdknotifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/IsaacDSC/featureflag/pkg/ctxlog"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	wsCommandSubscribe   = "subscribe"
+	wsCommandUnsubscribe = "unsubscribe"
+	wsWriteTimeout       = 10 * time.Second
+)
+
+// wsMessage is sent by the server, Event is empty for change events, "deleted" for deletes and "snapshot" for snapshots
+type wsMessage struct {
+	ID    string          `json:"id,omitempty"`
+	Event string          `json:"event,omitempty"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// wsCommand is sent by the client to choose which keys it receives, all keys when none was subscribed
+type wsCommand struct {
+	Type string   `json:"type"`
+	Keys []string `json:"keys"`
+}
+
+type WebSocketHandler struct {
+	routes    map[string]func(w http.ResponseWriter, r *http.Request)
+	hub       *Hub
+	heartbeat time.Duration
+	snapshots map[string]Snapshot
+	upgrader  websocket.Upgrader
+}
+
+func NewWebSocketHandler(hub *Hub, snapshots map[string]Snapshot) *WebSocketHandler {
+	h := new(WebSocketHandler)
+	h.hub = hub
+	h.heartbeat = hub.cfg.Heartbeat
+	h.snapshots = snapshots
+	h.routes = map[string]func(w http.ResponseWriter, r *http.Request){
+		"GET /ws/{resource}": h.connect,
+	}
+
+	return h
+}
+
+func (h *WebSocketHandler) GetRoutes() map[string]func(w http.ResponseWriter, r *http.Request) {
+	return h.routes
+}
+
+// connect upgrades the request and sends the same events as the SSE endpoint.
+// Browsers can't set headers on websocket requests, so Last-Event-ID and the initial keys
+// are also accepted as the last_event_id and keys (comma separated) query params.
+func (h *WebSocketHandler) connect(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	resource := resourceName(r)
+	l := ctxlog.GetLogger(ctx)
+
+	lastEventID := r.Header.Get(lastEventIDHeader)
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("last_event_id")
+	}
+
+	keys := make(map[string]bool)
+	if query := r.URL.Query().Get("keys"); query != "" {
+		for _, key := range strings.Split(query, ",") {
+			keys[key] = true
+		}
+	}
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		l.Error("error on upgrade websocket", "resource", resource, "error", err)
+		return
+	}
+	defer conn.Close()
+
+	l.Debug("connected websocket", "resource", resource, "last_event_id", lastEventID)
+
+	subscription, err := h.hub.Subscribe(ctx, resource, lastEventID)
+	if err != nil {
+		l.Error("error on subscribe websocket", "resource", resource, "error", err)
+		return
+	}
+	defer h.hub.Unsubscribe(resource, subscription)
+
+	if err := h.resume(ctx, conn, resource, subscription, keys); err != nil {
+		l.Error("error on resume websocket", "resource", resource, "error", err)
+		return
+	}
+
+	commands := make(chan wsCommand)
+	closed := make(chan struct{})
+	go h.read(conn, commands, closed)
+
+	ping := time.NewTicker(h.heartbeat)
+	defer ping.Stop()
+
+	for {
+		select {
+		case event := <-subscription.Events:
+			if !MatchKeys(keys, event.Data) {
+				continue
+			}
+
+			if err := write(conn, wsMessage{ID: event.ID, Event: event.Event, Data: event.Data}); err != nil {
+				l.Debug("error on write websocket", "resource", resource, "error", err)
+				return
+			}
+		case command := <-commands:
+			for _, key := range command.Keys {
+				switch command.Type {
+				case wsCommandSubscribe:
+					keys[key] = true
+				case wsCommandUnsubscribe:
+					delete(keys, key)
+				}
+			}
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout)); err != nil {
+				l.Debug("error on ping websocket", "resource", resource, "error", err)
+				return
+			}
+		case <-closed:
+			return
+		case <-subscription.Done:
+			l.Debug("websocket client disconnected by hub", "resource", resource)
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// read parses the client commands until the connection fails or no pong arrives in two heartbeats
+func (h *WebSocketHandler) read(conn *websocket.Conn, commands chan<- wsCommand, closed chan<- struct{}) {
+	defer close(closed)
+
+	pongWait := 2 * h.heartbeat
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, b, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var command wsCommand
+		if err := json.Unmarshal(b, &command); err != nil {
+			continue
+		}
+
+		select {
+		case commands <- command:
+		case <-time.After(pongWait):
+			return
+		}
+	}
+}
+
+func (h *WebSocketHandler) resume(ctx context.Context, conn *websocket.Conn, resource string, subscription *Subscription, keys map[string]bool) error {
+	if subscription.Resumed {
+		for _, event := range subscription.Replay {
+			if !MatchKeys(keys, event.Data) {
+				continue
+			}
+
+			if err := write(conn, wsMessage{ID: event.ID, Event: event.Event, Data: event.Data}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	b, ok, err := MarshalSnapshot(ctx, h.snapshots, resource, keys)
+	if err != nil || !ok {
+		return err
+	}
+
+	return write(conn, wsMessage{ID: subscription.LastID, Event: "snapshot", Data: b})
+}
+
+func write(conn *websocket.Conn, msg wsMessage) error {
+	conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+	return conn.WriteJSON(msg)
+}
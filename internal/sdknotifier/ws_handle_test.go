@@ -0,0 +1,101 @@
+package sdknotifier
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IsaacDSC/featureflag/pkg/ctxlog"
+	"github.com/gorilla/websocket"
+)
+
+func TestWebSocketHandler_connect(t *testing.T) {
+	sub := newFakeSubscriber()
+	hub := NewHub(sub, HubConfig{ClientBuffer: 10, SlowConsumer: SlowConsumerDrop, Heartbeat: 50 * time.Millisecond, History: 10})
+	defer hub.Close()
+
+	snapshots := map[string]Snapshot{
+		"featureflag": func(ctx context.Context) (any, error) {
+			return []map[string]any{{"flag_name": "a"}, {"flag_name": "b"}}, nil
+		},
+	}
+
+	handler := NewWebSocketHandler(hub, snapshots)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.SetPathValue("resource", "featureflag")
+		handler.connect(w, r.WithContext(ctxlog.SetLogger(r.Context(), slog.Default())))
+	}))
+	defer server.Close()
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/ws/featureflag?keys=a"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer conn.Close()
+
+	pinged := make(chan struct{}, 1)
+	conn.SetPingHandler(func(data string) error {
+		select {
+		case pinged <- struct{}{}:
+		default:
+		}
+		return conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+	})
+
+	read := func() wsMessage {
+		t.Helper()
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		var msg wsMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("ReadJSON() error = %v", err)
+		}
+		return msg
+	}
+
+	// snapshot inicial filtrado pelas keys da query
+	snapshot := read()
+	if snapshot.Event != "snapshot" || snapshot.ID != "0-0" {
+		t.Errorf("first message = %+v, want snapshot with id 0-0", snapshot)
+	}
+
+	var items []map[string]any
+	if err := json.Unmarshal(snapshot.Data, &items); err != nil || len(items) != 1 || items[0]["flag_name"] != "a" {
+		t.Errorf("snapshot data = %s, want only flag a", snapshot.Data)
+	}
+
+	sub.events <- `{"flag_name":"b"}`
+	sub.events <- `{"flag_name":"a"}`
+
+	if got := read(); got.ID != "2-0" || string(got.Data) != `{"flag_name":"a"}` {
+		t.Errorf("message = %+v, want only event of flag a", got)
+	}
+
+	if err := conn.WriteJSON(wsCommand{Type: wsCommandSubscribe, Keys: []string{"b"}}); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+	if err := conn.WriteJSON(wsCommand{Type: wsCommandUnsubscribe, Keys: []string{"a"}}); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+
+	// aguarda os comandos serem processados antes de publicar
+	time.Sleep(50 * time.Millisecond)
+
+	sub.events <- `{"flag_name":"a"}`
+	sub.events <- `{"flag_name":"b"}`
+
+	if got := read(); got.ID != "4-0" || string(got.Data) != `{"flag_name":"b"}` {
+		t.Errorf("message = %+v, want only event of flag b", got)
+	}
+
+	select {
+	case <-pinged:
+	case <-time.After(time.Second):
+		t.Error("no ping received from server")
+	}
+}
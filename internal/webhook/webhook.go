@@ -0,0 +1,123 @@
+package webhook
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	EventActivated   = "activated"
+	EventDeactivated = "deactivated"
+	EventDeleted     = "deleted"
+)
+
+const (
+	ResourceFeatureFlag = "featureflag"
+	ResourceContentHub  = "contenthub"
+)
+
+var (
+	events    = []string{EventActivated, EventDeactivated, EventDeleted}
+	resources = []string{ResourceFeatureFlag, ResourceContentHub}
+)
+
+// Entity is an endpoint notified of the changes matching its filters, an empty filter matches everything
+type Entity struct {
+	ID        uuid.UUID `json:"id" bson:"id"`
+	URL       string    `json:"url" bson:"url"`
+	Secret    string    `json:"secret" bson:"secret"`
+	Events    []string  `json:"events" bson:"events"`
+	Resources []string  `json:"resources" bson:"resources"`
+	Active    bool      `json:"active" bson:"active"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+}
+
+func (w Entity) Match(resource, event string) bool {
+	if !w.Active {
+		return false
+	}
+
+	if len(w.Resources) > 0 && !slices.Contains(w.Resources, resource) {
+		return false
+	}
+
+	return len(w.Events) == 0 || slices.Contains(w.Events, event)
+}
+
+// Event is the body POSTed to the webhooks
+type Event struct {
+	ID        string          `json:"id" bson:"id"`
+	Event     string          `json:"event" bson:"event"`
+	Resource  string          `json:"resource" bson:"resource"`
+	Data      json.RawMessage `json:"data" bson:"data"`
+	Timestamp time.Time       `json:"timestamp" bson:"timestamp"`
+}
+
+// NewEvent builds the event of a change published on resource, activated or deactivated by the active field of data
+func NewEvent(id, resource string, data []byte) (Event, error) {
+	var payload struct {
+		Active bool `json:"active"`
+	}
+
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return Event{}, err
+	}
+
+	event := EventDeactivated
+	if payload.Active {
+		event = EventActivated
+	}
+
+	return Event{
+		ID:        id,
+		Event:     event,
+		Resource:  resource,
+		Data:      data,
+		Timestamp: time.Now(),
+	}, nil
+}
+
+// NewDeletedEvent builds the event of a delete published on the deleted channel of resource
+func NewDeletedEvent(id, resource string, data []byte) (Event, error) {
+	if !json.Valid(data) {
+		return Event{}, fmt.Errorf("invalid %s deleted event", resource)
+	}
+
+	return Event{
+		ID:        id,
+		Event:     EventDeleted,
+		Resource:  resource,
+		Data:      data,
+		Timestamp: time.Now(),
+	}, nil
+}
+
+// Delivery is one attempt to deliver an event to a webhook
+type Delivery struct {
+	ID         uuid.UUID `json:"id" bson:"id"`
+	WebhookID  uuid.UUID `json:"webhook_id" bson:"webhook_id"`
+	EventID    string    `json:"event_id" bson:"event_id"`
+	Event      string    `json:"event" bson:"event"`
+	Resource   string    `json:"resource" bson:"resource"`
+	Attempt    int       `json:"attempt" bson:"attempt"`
+	StatusCode int       `json:"status_code,omitempty" bson:"status_code"`
+	Error      string    `json:"error,omitempty" bson:"error"`
+	Success    bool      `json:"success" bson:"success"`
+	DurationMs int64     `json:"duration_ms" bson:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at" bson:"created_at"`
+}
+
+// DeadLetter is an event that could not be delivered to a webhook after every attempt
+type DeadLetter struct {
+	ID        uuid.UUID `json:"id" bson:"id"`
+	WebhookID uuid.UUID `json:"webhook_id" bson:"webhook_id"`
+	URL       string    `json:"url" bson:"url"`
+	Event     Event     `json:"event" bson:"event"`
+	Attempts  int       `json:"attempts" bson:"attempts"`
+	LastError string    `json:"last_error" bson:"last_error"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+}
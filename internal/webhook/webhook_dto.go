@@ -0,0 +1,63 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Dto struct {
+	ID        uuid.UUID `json:"id"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"secret,omitempty"`
+	Events    []string  `json:"events"`
+	Resources []string  `json:"resources"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (d Dto) ToDomain() (Entity, error) {
+	u, err := url.Parse(strings.TrimSpace(d.URL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return Entity{}, errors.New("url is required and must be http or https")
+	}
+
+	for _, event := range d.Events {
+		if !slices.Contains(events, event) {
+			return Entity{}, fmt.Errorf("invalid event %q, expected one of %v", event, events)
+		}
+	}
+
+	for _, resource := range d.Resources {
+		if !slices.Contains(resources, resource) {
+			return Entity{}, fmt.Errorf("invalid resource %q, expected one of %v", resource, resources)
+		}
+	}
+
+	return Entity{
+		ID:        uuid.New(),
+		URL:       u.String(),
+		Secret:    strings.TrimSpace(d.Secret),
+		Events:    d.Events,
+		Resources: d.Resources,
+		Active:    d.Active,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// FromDomain never returns the secret
+func FromDomain(webhook Entity) Dto {
+	return Dto{
+		ID:        webhook.ID,
+		URL:       webhook.URL,
+		Events:    webhook.Events,
+		Resources: webhook.Resources,
+		Active:    webhook.Active,
+		CreatedAt: webhook.CreatedAt,
+	}
+}
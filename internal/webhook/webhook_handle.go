@@ -0,0 +1,186 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/IsaacDSC/featureflag/pkg/errorutils"
+	"github.com/IsaacDSC/featureflag/pkg/middlewares"
+)
+
+type Handler struct {
+	routes  map[string]func(w http.ResponseWriter, r *http.Request)
+	service *Service
+}
+
+const webhookPrefix = "/webhook"
+
+func NewWebhookHandler(service *Service) *Handler {
+	handler := new(Handler)
+	handler.service = service
+
+	routes := map[string]http.HandlerFunc{
+		fmt.Sprintf("POST %s", webhookPrefix):                handler.create,
+		fmt.Sprintf("PATCH %s/{id}", webhookPrefix):          handler.update,
+		fmt.Sprintf("DELETE %s/{id}", webhookPrefix):         handler.delete,
+		fmt.Sprintf("GET %ss", webhookPrefix):                handler.getAll,
+		fmt.Sprintf("GET %s/{id}", webhookPrefix):            handler.get,
+		fmt.Sprintf("GET %s/{id}/deliveries", webhookPrefix): handler.deliveries,
+		fmt.Sprintf("GET %ss/dead-letters", webhookPrefix):   handler.deadLetters,
+	}
+
+	handler.routes = make(map[string]func(w http.ResponseWriter, r *http.Request), len(routes))
+	for path, route := range routes {
+		handler.routes[path] = middlewares.Authorization(middlewares.CheckPermission(route, middlewares.USERNAME_SERVICE))
+	}
+
+	return handler
+}
+
+func (h *Handler) GetRoutes() map[string]func(w http.ResponseWriter, r *http.Request) {
+	return h.routes
+}
+
+func (h *Handler) create(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var payload Dto
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("error on decode body"))
+		return
+	}
+
+	webhook, err := payload.ToDomain()
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	if err := h.service.Create(r.Context(), webhook); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, FromDomain(webhook))
+}
+
+func (h *Handler) update(w http.ResponseWriter, r *http.Request) {
+	defer r.Body.Close()
+
+	var payload Dto
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte("error on decode body"))
+		return
+	}
+
+	input, err := payload.ToDomain()
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	webhook, err := h.service.Update(r.Context(), r.PathValue("id"), input)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, FromDomain(webhook))
+}
+
+func (h *Handler) delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.Remove(r.Context(), r.PathValue("id")); err != nil {
+		writeError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *Handler) get(w http.ResponseWriter, r *http.Request) {
+	webhook, err := h.service.Get(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, FromDomain(webhook))
+}
+
+func (h *Handler) getAll(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := h.service.GetAll(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	result := make([]Dto, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		result = append(result, FromDomain(webhook))
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+func (h *Handler) deliveries(w http.ResponseWriter, r *http.Request) {
+	deliveries, err := h.service.Deliveries(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if deliveries == nil {
+		deliveries = []Delivery{}
+	}
+
+	writeJSON(w, http.StatusOK, deliveries)
+}
+
+func (h *Handler) deadLetters(w http.ResponseWriter, r *http.Request) {
+	deadLetters, err := h.service.DeadLetters(r.Context())
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	if deadLetters == nil {
+		deadLetters = []DeadLetter{}
+	}
+
+	writeJSON(w, http.StatusOK, deadLetters)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(b)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrSecretRequired) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(err.Error()))
+		return
+	}
+
+	switch err.(type) {
+	case *errorutils.NotFoundError:
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("webhook not found"))
+	default:
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte(err.Error()))
+	}
+}
@@ -0,0 +1,29 @@
+package webhook
+
+import (
+	"context"
+
+	"github.com/IsaacDSC/featureflag/pkg/pubsub"
+)
+
+type Adapter interface {
+	SaveWebhook(ctx context.Context, input Entity) error
+	GetWebhook(ctx context.Context, id string) (Entity, error)
+	GetAllWebhooks(ctx context.Context) (map[string]Entity, error)
+	DeleteWebhook(ctx context.Context, id string) error
+
+	// SaveDelivery appends to the log of the webhook, only the newest maxDeliveries are kept
+	SaveDelivery(ctx context.Context, input Delivery) error
+	GetDeliveries(ctx context.Context, webhookID string) ([]Delivery, error)
+
+	SaveDeadLetter(ctx context.Context, input DeadLetter) error
+	GetDeadLetters(ctx context.Context) ([]DeadLetter, error)
+
+	// ClaimEvent records key for claimRetention, false when another call already claimed it,
+	// so an event received by every node is delivered by one of them
+	ClaimEvent(ctx context.Context, key string) (bool, error)
+}
+
+type Subscriber interface {
+	Listener(ctx context.Context, channel string, fn pubsub.Handler) error
+}
@@ -0,0 +1,200 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/IsaacDSC/featureflag/pkg/errorutils"
+)
+
+const (
+	maxDeliveries  = 100
+	maxDeadLetters = 1000
+	// claimRetention is how long an event is remembered as delivered, longer than the same event can take
+	// to reach every node or be published again by the outbox relay
+	claimRetention = time.Hour
+)
+
+type fileData struct {
+	Webhooks    map[string]Entity     `json:"webhooks"`
+	Deliveries  map[string][]Delivery `json:"deliveries"`
+	DeadLetters []DeadLetter          `json:"dead_letters"`
+	Claims      map[string]time.Time  `json:"claims,omitempty"`
+}
+
+// Repository keeps everything in one json file, the deliveries are written concurrently by the worker
+type Repository struct {
+	filePath string
+	mu       sync.Mutex
+}
+
+func NewWebhookRepository(filePath string) *Repository {
+	return &Repository{filePath: filePath}
+}
+
+func (r *Repository) SaveWebhook(ctx context.Context, input Entity) error {
+	return r.update(func(data *fileData) {
+		data.Webhooks[input.ID.String()] = input
+	})
+}
+
+func (r *Repository) GetWebhook(ctx context.Context, id string) (Entity, error) {
+	data, err := r.read()
+	if err != nil {
+		return Entity{}, err
+	}
+
+	if output, ok := data.Webhooks[id]; ok {
+		return output, nil
+	}
+
+	return Entity{}, errorutils.NewNotFoundError("webhook")
+}
+
+func (r *Repository) GetAllWebhooks(ctx context.Context) (map[string]Entity, error) {
+	data, err := r.read()
+	if err != nil {
+		return map[string]Entity{}, err
+	}
+
+	return data.Webhooks, nil
+}
+
+func (r *Repository) DeleteWebhook(ctx context.Context, id string) error {
+	var found bool
+	if err := r.update(func(data *fileData) {
+		_, found = data.Webhooks[id]
+		delete(data.Webhooks, id)
+		delete(data.Deliveries, id)
+	}); err != nil {
+		return err
+	}
+
+	if !found {
+		return errorutils.NewNotFoundError("webhook")
+	}
+
+	return nil
+}
+
+func (r *Repository) SaveDelivery(ctx context.Context, input Delivery) error {
+	return r.update(func(data *fileData) {
+		id := input.WebhookID.String()
+		deliveries := append(data.Deliveries[id], input)
+		if len(deliveries) > maxDeliveries {
+			deliveries = slices.Delete(deliveries, 0, len(deliveries)-maxDeliveries)
+		}
+		data.Deliveries[id] = deliveries
+	})
+}
+
+func (r *Repository) GetDeliveries(ctx context.Context, webhookID string) ([]Delivery, error) {
+	data, err := r.read()
+	if err != nil {
+		return nil, err
+	}
+
+	return data.Deliveries[webhookID], nil
+}
+
+func (r *Repository) SaveDeadLetter(ctx context.Context, input DeadLetter) error {
+	return r.update(func(data *fileData) {
+		data.DeadLetters = append(data.DeadLetters, input)
+		if len(data.DeadLetters) > maxDeadLetters {
+			data.DeadLetters = slices.Delete(data.DeadLetters, 0, len(data.DeadLetters)-maxDeadLetters)
+		}
+	})
+}
+
+func (r *Repository) GetDeadLetters(ctx context.Context) ([]DeadLetter, error) {
+	data, err := r.read()
+	if err != nil {
+		return nil, err
+	}
+
+	return data.DeadLetters, nil
+}
+
+func (r *Repository) ClaimEvent(ctx context.Context, key string) (bool, error) {
+	var claimed bool
+	err := r.update(func(data *fileData) {
+		now := time.Now()
+		for claim, at := range data.Claims {
+			if now.Sub(at) > claimRetention {
+				delete(data.Claims, claim)
+			}
+		}
+
+		if _, ok := data.Claims[key]; ok {
+			return
+		}
+
+		if data.Claims == nil {
+			data.Claims = map[string]time.Time{}
+		}
+		data.Claims[key] = now
+		claimed = true
+	})
+
+	return claimed, err
+}
+
+func (r *Repository) read() (fileData, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.load()
+}
+
+func (r *Repository) update(fn func(data *fileData)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data, err := r.load()
+	if err != nil {
+		return err
+	}
+
+	fn(&data)
+
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(r.filePath, b, 0644)
+}
+
+func (r *Repository) load() (fileData, error) {
+	data := fileData{
+		Webhooks:   map[string]Entity{},
+		Deliveries: map[string][]Delivery{},
+	}
+
+	b, err := os.ReadFile(r.filePath)
+	if err != nil {
+		return data, err
+	}
+
+	if len(b) == 0 {
+		return data, nil
+	}
+
+	if err := json.Unmarshal(b, &data); err != nil {
+		return data, err
+	}
+
+	if data.Webhooks == nil {
+		data.Webhooks = map[string]Entity{}
+	}
+
+	if data.Deliveries == nil {
+		data.Deliveries = map[string][]Delivery{}
+	}
+
+	return data, nil
+}
@@ -0,0 +1,229 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/IsaacDSC/featureflag/pkg/errorutils"
+	"github.com/IsaacDSC/featureflag/pkg/mongodb"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MongoDBRepository struct {
+	webhooks    *mongo.Collection
+	deliveries  *mongo.Collection
+	deadLetters *mongo.Collection
+	claims      *mongo.Collection
+	timeout     time.Duration
+}
+
+const (
+	collectionName            = mongodb.CollectionName("webhooks")
+	deliveriesCollectionName  = mongodb.CollectionName("webhook_deliveries")
+	deadLettersCollectionName = mongodb.CollectionName("webhook_dead_letters")
+	claimsCollectionName      = mongodb.CollectionName("webhook_claims")
+	idIndexModel              = mongodb.IndexModel("id")
+	webhookIDField            = "webhook_id"
+	createdAtField            = "created_at"
+)
+
+func NewMongoDBWebhookRepository(database *mongo.Database) (*MongoDBRepository, error) {
+	collection := database.Collection(collectionName.String())
+
+	if err := mongodb.CreateUniqueIndex(collection, idIndexModel); err != nil {
+		return nil, fmt.Errorf("error on create index: %w", err)
+	}
+
+	claims := database.Collection(claimsCollectionName.String())
+	if err := mongodb.CreateTTLIndex(claims, createdAtField, claimRetention); err != nil {
+		return nil, fmt.Errorf("error on create claims index: %w", err)
+	}
+
+	return &MongoDBRepository{
+		webhooks:    collection,
+		deliveries:  database.Collection(deliveriesCollectionName.String()),
+		deadLetters: database.Collection(deadLettersCollectionName.String()),
+		claims:      claims,
+		timeout:     10 * time.Second,
+	}, nil
+}
+
+func (mr *MongoDBRepository) SaveWebhook(ctx context.Context, input Entity) error {
+	ctx, cancel := context.WithTimeout(ctx, mr.timeout)
+	defer cancel()
+
+	filter := bson.M{idIndexModel.String(): input.ID}
+	opts := options.Replace().SetUpsert(true)
+
+	_, err := mr.webhooks.ReplaceOne(ctx, filter, input, opts)
+	return err
+}
+
+func (mr *MongoDBRepository) GetWebhook(ctx context.Context, id string) (Entity, error) {
+	ctx, cancel := context.WithTimeout(ctx, mr.timeout)
+	defer cancel()
+
+	var entity Entity
+	if err := mr.webhooks.FindOne(ctx, bson.M{idIndexModel.String(): parseID(id)}).Decode(&entity); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return Entity{}, errorutils.NewNotFoundError("webhook")
+		}
+		return Entity{}, err
+	}
+
+	return entity, nil
+}
+
+func (mr *MongoDBRepository) GetAllWebhooks(ctx context.Context) (map[string]Entity, error) {
+	ctx, cancel := context.WithTimeout(ctx, mr.timeout)
+	defer cancel()
+
+	cursor, err := mr.webhooks.Find(ctx, bson.M{})
+	if err != nil {
+		return map[string]Entity{}, err
+	}
+	defer cursor.Close(ctx)
+
+	result := make(map[string]Entity)
+	for cursor.Next(ctx) {
+		var entity Entity
+		if err := cursor.Decode(&entity); err != nil {
+			return map[string]Entity{}, err
+		}
+		result[entity.ID.String()] = entity
+	}
+
+	if err := cursor.Err(); err != nil {
+		return map[string]Entity{}, err
+	}
+
+	return result, nil
+}
+
+func (mr *MongoDBRepository) DeleteWebhook(ctx context.Context, id string) error {
+	ctx, cancel := context.WithTimeout(ctx, mr.timeout)
+	defer cancel()
+
+	result, err := mr.webhooks.DeleteOne(ctx, bson.M{idIndexModel.String(): parseID(id)})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return errorutils.NewNotFoundError("webhook")
+	}
+
+	_, err = mr.deliveries.DeleteMany(ctx, bson.M{webhookIDField: parseID(id)})
+	return err
+}
+
+func (mr *MongoDBRepository) SaveDelivery(ctx context.Context, input Delivery) error {
+	ctx, cancel := context.WithTimeout(ctx, mr.timeout)
+	defer cancel()
+
+	if _, err := mr.deliveries.InsertOne(ctx, input); err != nil {
+		return err
+	}
+
+	// trims the log to the newest maxDeliveries of the webhook
+	opts := options.FindOne().
+		SetSort(bson.D{{Key: createdAtField, Value: -1}}).
+		SetSkip(maxDeliveries)
+
+	var oldest Delivery
+	err := mr.deliveries.FindOne(ctx, bson.M{webhookIDField: input.WebhookID}, opts).Decode(&oldest)
+	if err == mongo.ErrNoDocuments {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	_, err = mr.deliveries.DeleteMany(ctx, bson.M{
+		webhookIDField: input.WebhookID,
+		createdAtField: bson.M{"$lte": oldest.CreatedAt},
+	})
+
+	return err
+}
+
+func (mr *MongoDBRepository) GetDeliveries(ctx context.Context, webhookID string) ([]Delivery, error) {
+	ctx, cancel := context.WithTimeout(ctx, mr.timeout)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: createdAtField, Value: -1}}).SetLimit(maxDeliveries)
+	cursor, err := mr.deliveries.Find(ctx, bson.M{webhookIDField: parseID(webhookID)}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var result []Delivery
+	if err := cursor.All(ctx, &result); err != nil {
+		return nil, err
+	}
+
+	// oldest first, as the json file repository
+	slices.Reverse(result)
+
+	return result, nil
+}
+
+func (mr *MongoDBRepository) SaveDeadLetter(ctx context.Context, input DeadLetter) error {
+	ctx, cancel := context.WithTimeout(ctx, mr.timeout)
+	defer cancel()
+
+	_, err := mr.deadLetters.InsertOne(ctx, input)
+	return err
+}
+
+func (mr *MongoDBRepository) GetDeadLetters(ctx context.Context) ([]DeadLetter, error) {
+	ctx, cancel := context.WithTimeout(ctx, mr.timeout)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.D{{Key: createdAtField, Value: -1}}).SetLimit(maxDeadLetters)
+	cursor, err := mr.deadLetters.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var result []DeadLetter
+	if err := cursor.All(ctx, &result); err != nil {
+		return nil, err
+	}
+
+	slices.Reverse(result)
+
+	return result, nil
+}
+
+// ClaimEvent inserts key as the _id of a claim, removed by the ttl index after claimRetention
+func (mr *MongoDBRepository) ClaimEvent(ctx context.Context, key string) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, mr.timeout)
+	defer cancel()
+
+	_, err := mr.claims.InsertOne(ctx, bson.M{"_id": key, createdAtField: time.Now()})
+	if mongo.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// parseID returns the uuid as stored by the driver, the raw string when it isn't a valid uuid
+func parseID(id string) any {
+	if parsed, err := uuid.Parse(id); err == nil {
+		return parsed
+	}
+
+	return id
+}
@@ -0,0 +1,87 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var ErrSecretRequired = errors.New("secret is required")
+
+type Service struct {
+	repository Adapter
+}
+
+func NewWebhookService(repository Adapter) *Service {
+	return &Service{repository: repository}
+}
+
+func (s Service) Create(ctx context.Context, webhook Entity) error {
+	if webhook.Secret == "" {
+		return ErrSecretRequired
+	}
+
+	return s.repository.SaveWebhook(ctx, webhook)
+}
+
+// Update replaces the webhook id keeping its creation date, and its secret when input has none
+func (s Service) Update(ctx context.Context, id string, input Entity) (Entity, error) {
+	webhook, err := s.repository.GetWebhook(ctx, id)
+	if err != nil {
+		return Entity{}, err
+	}
+
+	input.ID = webhook.ID
+	input.CreatedAt = webhook.CreatedAt
+	if input.Secret == "" {
+		input.Secret = webhook.Secret
+	}
+
+	if err := s.repository.SaveWebhook(ctx, input); err != nil {
+		return Entity{}, fmt.Errorf("error on save webhook: %w", err)
+	}
+
+	return input, nil
+}
+
+func (s Service) Remove(ctx context.Context, id string) error {
+	return s.repository.DeleteWebhook(ctx, id)
+}
+
+func (s Service) Get(ctx context.Context, id string) (Entity, error) {
+	return s.repository.GetWebhook(ctx, id)
+}
+
+func (s Service) GetAll(ctx context.Context) (map[string]Entity, error) {
+	return s.repository.GetAllWebhooks(ctx)
+}
+
+// Deliveries returns the delivery log of the webhook, oldest first
+func (s Service) Deliveries(ctx context.Context, id string) ([]Delivery, error) {
+	if _, err := s.repository.GetWebhook(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return s.repository.GetDeliveries(ctx, id)
+}
+
+func (s Service) DeadLetters(ctx context.Context) ([]DeadLetter, error) {
+	return s.repository.GetDeadLetters(ctx)
+}
+
+// Matching returns the webhooks subscribed to event of resource
+func (s Service) Matching(ctx context.Context, resource, event string) ([]Entity, error) {
+	webhooks, err := s.repository.GetAllWebhooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var output []Entity
+	for _, webhook := range webhooks {
+		if webhook.Match(resource, event) {
+			output = append(output, webhook)
+		}
+	}
+
+	return output, nil
+}
@@ -0,0 +1,251 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/IsaacDSC/featureflag/internal/outbox"
+	"github.com/IsaacDSC/featureflag/pkg/pubsub"
+	"github.com/google/uuid"
+)
+
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+const (
+	listenerMinBackoff = time.Second
+	listenerMaxBackoff = 30 * time.Second
+)
+
+type WorkerConfig struct {
+	// MaxAttempts is how many times an event is sent before going to the dead letters
+	MaxAttempts int
+	// Backoff is the wait before the first retry, doubled on every retry up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// Timeout of each request
+	Timeout time.Duration
+}
+
+// Worker listens to the change events of every resource and delivers them to the webhooks subscribed
+type Worker struct {
+	sub     Subscriber
+	service *Service
+	client  *http.Client
+	cfg     WorkerConfig
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewWorker(sub Subscriber, service *Service, cfg WorkerConfig) *Worker {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Worker{
+		sub:     sub,
+		service: service,
+		client:  &http.Client{Timeout: cfg.Timeout},
+		cfg:     cfg,
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+}
+
+// Start listens to the changes of every resource, and to their deletes published on the outbox.DeletedChannel
+func (w *Worker) Start() {
+	for _, resource := range resources {
+		w.wg.Add(2)
+		go w.listen(resource, resource, NewEvent)
+		go w.listen(resource, outbox.DeletedChannel(resource), NewDeletedEvent)
+	}
+}
+
+// Close stops listening and waits the deliveries in progress, the ones still retrying go to the dead letters
+func (w *Worker) Close() {
+	w.cancel()
+	w.wg.Wait()
+}
+
+// Sign returns the value of SignatureHeader for body
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (w *Worker) listen(resource, channel string, newEvent func(id, resource string, data []byte) (Event, error)) {
+	defer w.wg.Done()
+
+	backoff := listenerMinBackoff
+	for {
+		err := w.sub.Listener(w.ctx, channel, func(ctx context.Context, msg pubsub.Msg) error {
+			backoff = listenerMinBackoff
+			w.dispatch(resource, pubsub.EventID(ctx), msg, newEvent)
+			return nil
+		})
+
+		if w.ctx.Err() != nil {
+			return
+		}
+
+		log.Printf("webhook listener of %s stopped, restarting in %v: %v\n", channel, backoff, err)
+
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff = min(backoff*2, listenerMaxBackoff)
+	}
+}
+
+func (w *Worker) dispatch(resource, eventID string, data []byte, newEvent func(id, resource string, data []byte) (Event, error)) {
+	event, err := newEvent(eventID, resource, data)
+	if err != nil {
+		log.Printf("error on decode %s event %s: %v\n", resource, eventID, err)
+		return
+	}
+
+	webhooks, err := w.service.Matching(w.ctx, resource, event.Event)
+	if err != nil {
+		log.Printf("error on get webhooks of %s event %s: %v\n", resource, eventID, err)
+		return
+	}
+
+	if len(webhooks) == 0 {
+		return
+	}
+
+	// every node receives the event, and the outbox relay may publish it again, the claim keeps one
+	// delivery. The events without the key of their change can't be told apart, they are all delivered.
+	if key := pubsub.Key(data); key != "" {
+		claimed, err := w.service.repository.ClaimEvent(w.ctx, resource+"/"+key)
+		if err != nil {
+			log.Printf("error on claim %s event %s, delivering it: %v\n", resource, eventID, err)
+		} else if !claimed {
+			return
+		}
+	}
+
+	for _, webhook := range webhooks {
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			w.deliver(webhook, event)
+		}()
+	}
+}
+
+// deliver sends the event until the webhook answers 2xx, waiting the backoff between attempts
+func (w *Worker) deliver(webhook Entity, event Event) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("error on marshal event %s: %v\n", event.ID, err)
+		return
+	}
+
+	// the logs are saved even when the worker is stopping
+	ctx := context.WithoutCancel(w.ctx)
+	backoff := w.cfg.Backoff
+
+	var delivery Delivery
+	for attempt := 1; ; attempt++ {
+		delivery = w.send(webhook, event, body, attempt)
+		if err := w.service.repository.SaveDelivery(ctx, delivery); err != nil {
+			log.Printf("error on save delivery of webhook %s: %v\n", webhook.ID, err)
+		}
+
+		if delivery.Success {
+			return
+		}
+
+		if attempt >= w.cfg.MaxAttempts {
+			break
+		}
+
+		if !w.wait(backoff) {
+			delivery.Error = fmt.Sprintf("worker stopped before retry: %s", delivery.Error)
+			break
+		}
+
+		backoff = min(backoff*2, w.cfg.MaxBackoff)
+	}
+
+	deadLetter := DeadLetter{
+		ID:        uuid.New(),
+		WebhookID: webhook.ID,
+		URL:       webhook.URL,
+		Event:     event,
+		Attempts:  delivery.Attempt,
+		LastError: delivery.Error,
+		CreatedAt: time.Now(),
+	}
+
+	if err := w.service.repository.SaveDeadLetter(ctx, deadLetter); err != nil {
+		log.Printf("error on save dead letter of webhook %s: %v\n", webhook.ID, err)
+	}
+}
+
+// wait returns false when the worker was closed before d
+func (w *Worker) wait(d time.Duration) bool {
+	select {
+	case <-w.ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
+
+func (w *Worker) send(webhook Entity, event Event, body []byte, attempt int) Delivery {
+	delivery := Delivery{
+		ID:        uuid.New(),
+		WebhookID: webhook.ID,
+		EventID:   event.ID,
+		Event:     event.Event,
+		Resource:  event.Resource,
+		Attempt:   attempt,
+		CreatedAt: time.Now(),
+	}
+
+	req, err := http.NewRequestWithContext(w.ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(webhook.Secret, body))
+	req.Header.Set(EventHeader, event.Event)
+	req.Header.Set(DeliveryHeader, delivery.ID.String())
+
+	resp, err := w.client.Do(req)
+	delivery.DurationMs = time.Since(delivery.CreatedAt).Milliseconds()
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	delivery.StatusCode = resp.StatusCode
+	delivery.Success = resp.StatusCode >= 200 && resp.StatusCode < 300
+	if !delivery.Success {
+		delivery.Error = fmt.Sprintf("unexpected status %d", resp.StatusCode)
+	}
+
+	return delivery
+}
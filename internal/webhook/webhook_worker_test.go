@@ -0,0 +1,277 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/IsaacDSC/featureflag/internal/outbox"
+	"github.com/IsaacDSC/featureflag/pkg/pubsub"
+	"github.com/google/uuid"
+)
+
+type fakeSubscriber struct {
+	events map[string]chan string
+}
+
+func newFakeSubscriber() *fakeSubscriber {
+	return &fakeSubscriber{events: map[string]chan string{
+		ResourceFeatureFlag:                        make(chan string),
+		ResourceContentHub:                         make(chan string),
+		outbox.DeletedChannel(ResourceFeatureFlag): make(chan string),
+		outbox.DeletedChannel(ResourceContentHub):  make(chan string),
+	}}
+}
+
+func (s *fakeSubscriber) Listener(ctx context.Context, channel string, fn pubsub.Handler) error {
+	for {
+		select {
+		case event := <-s.events[channel]:
+			fn(pubsub.WithEventID(ctx, "1-0"), []byte(event))
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func setupWorker(t *testing.T, webhooks ...Entity) (*Worker, *fakeSubscriber, *Repository) {
+	t.Helper()
+
+	filePath := filepath.Join(t.TempDir(), "webhooks.json")
+	if err := os.WriteFile(filePath, nil, 0644); err != nil {
+		t.Fatalf("error on create file: %v", err)
+	}
+
+	repository := NewWebhookRepository(filePath)
+	for _, webhook := range webhooks {
+		if err := repository.SaveWebhook(context.Background(), webhook); err != nil {
+			t.Fatalf("error on save webhook: %v", err)
+		}
+	}
+
+	sub := newFakeSubscriber()
+	worker := NewWorker(sub, NewWebhookService(repository), WorkerConfig{
+		MaxAttempts: 3,
+		Backoff:     10 * time.Millisecond,
+		MaxBackoff:  20 * time.Millisecond,
+		Timeout:     time.Second,
+	})
+	worker.Start()
+	t.Cleanup(worker.Close)
+
+	return worker, sub, repository
+}
+
+func newWebhook(url string, resources ...string) Entity {
+	return Entity{ID: uuid.New(), URL: url, Secret: "secret", Resources: resources, Active: true}
+}
+
+func waitDeliveries(t *testing.T, repository *Repository, webhookID uuid.UUID, n int) []Delivery {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		deliveries, err := repository.GetDeliveries(context.Background(), webhookID.String())
+		if err != nil {
+			t.Fatalf("error on get deliveries: %v", err)
+		}
+
+		if len(deliveries) >= n {
+			return deliveries
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("timeout waiting %d deliveries", n)
+	return nil
+}
+
+func TestWorker_SignedDelivery(t *testing.T) {
+	received := make(chan *http.Request, 1)
+	bodies := make(chan []byte, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		received <- r
+		bodies <- b
+	}))
+	defer receiver.Close()
+
+	called := atomic.Bool{}
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called.Store(true)
+	}))
+	defer other.Close()
+
+	webhook := newWebhook(receiver.URL, ResourceFeatureFlag)
+	_, sub, repository := setupWorker(t, webhook, newWebhook(other.URL, ResourceContentHub))
+
+	sub.events[ResourceFeatureFlag] <- `{"flag_name":"a","active":true}`
+
+	var r *http.Request
+	select {
+	case r = <-received:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting webhook")
+	}
+	body := <-bodies
+
+	if got, want := r.Header.Get(SignatureHeader), Sign("secret", body); got != want {
+		t.Errorf("signature = %s, want %s", got, want)
+	}
+
+	var event Event
+	if err := json.Unmarshal(body, &event); err != nil {
+		t.Fatalf("error on unmarshal event: %v", err)
+	}
+
+	if event.ID != "1-0" || event.Event != EventActivated || event.Resource != ResourceFeatureFlag || string(event.Data) != `{"flag_name":"a","active":true}` {
+		t.Errorf("unexpected event %+v", event)
+	}
+
+	deliveries := waitDeliveries(t, repository, webhook.ID, 1)
+	if !deliveries[0].Success || deliveries[0].StatusCode != http.StatusOK {
+		t.Errorf("unexpected delivery %+v", deliveries[0])
+	}
+
+	if called.Load() {
+		t.Error("webhook of another resource was called")
+	}
+}
+
+func TestWorker_Deleted(t *testing.T) {
+	bodies := make(chan []byte, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		bodies <- b
+	}))
+	defer receiver.Close()
+
+	webhook := newWebhook(receiver.URL)
+	webhook.Events = []string{EventDeleted}
+
+	_, sub, _ := setupWorker(t, webhook)
+
+	sub.events[outbox.DeletedChannel(ResourceContentHub)] <- `{"key":"a","revision":4}`
+
+	var body []byte
+	select {
+	case body = <-bodies:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting webhook")
+	}
+
+	var event Event
+	if err := json.Unmarshal(body, &event); err != nil {
+		t.Fatalf("error on unmarshal event: %v", err)
+	}
+
+	if event.Event != EventDeleted || event.Resource != ResourceContentHub || string(event.Data) != `{"key":"a","revision":4}` {
+		t.Errorf("unexpected event %+v", event)
+	}
+}
+
+func TestWorker_DeliveredOnce(t *testing.T) {
+	var calls atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+	}))
+	defer receiver.Close()
+
+	webhook := newWebhook(receiver.URL)
+	_, sub, repository := setupWorker(t, webhook)
+
+	// a second node sharing the repository receives the same events
+	other := newFakeSubscriber()
+	worker := NewWorker(other, NewWebhookService(repository), WorkerConfig{MaxAttempts: 1, Timeout: time.Second})
+	worker.Start()
+	t.Cleanup(worker.Close)
+
+	event := `{"flag_name":"a","active":true,"event_key":"entry-1"}`
+	sub.events[ResourceFeatureFlag] <- event
+	other.events[ResourceFeatureFlag] <- event
+	// published again by the outbox relay
+	sub.events[ResourceFeatureFlag] <- event
+
+	waitDeliveries(t, repository, webhook.ID, 1)
+	time.Sleep(50 * time.Millisecond)
+
+	if got := calls.Load(); got != 1 {
+		t.Errorf("webhook called %d times, want 1", got)
+	}
+
+	// the same content saved again is another change
+	sub.events[ResourceFeatureFlag] <- `{"flag_name":"a","active":true,"event_key":"entry-2"}`
+	waitDeliveries(t, repository, webhook.ID, 2)
+}
+
+func TestWorker_Retry(t *testing.T) {
+	var calls atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer receiver.Close()
+
+	webhook := newWebhook(receiver.URL)
+	_, sub, repository := setupWorker(t, webhook)
+
+	sub.events[ResourceContentHub] <- `{"key":"a","active":false}`
+
+	deliveries := waitDeliveries(t, repository, webhook.ID, 3)
+	for i, delivery := range deliveries {
+		if delivery.Attempt != i+1 || delivery.Event != EventDeactivated {
+			t.Errorf("unexpected delivery %+v", delivery)
+		}
+	}
+
+	if deliveries[0].Success || deliveries[0].StatusCode != http.StatusInternalServerError || !deliveries[2].Success {
+		t.Errorf("expected two failures then success, got %+v", deliveries)
+	}
+
+	deadLetters, _ := repository.GetDeadLetters(context.Background())
+	if len(deadLetters) != 0 {
+		t.Errorf("unexpected dead letters %+v", deadLetters)
+	}
+}
+
+func TestWorker_DeadLetter(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer receiver.Close()
+
+	webhook := newWebhook(receiver.URL)
+	_, sub, repository := setupWorker(t, webhook)
+
+	sub.events[ResourceFeatureFlag] <- `{"flag_name":"a","active":true}`
+
+	waitDeliveries(t, repository, webhook.ID, 3)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		deadLetters, err := repository.GetDeadLetters(context.Background())
+		if err != nil {
+			t.Fatalf("error on get dead letters: %v", err)
+		}
+
+		if len(deadLetters) == 1 {
+			if deadLetters[0].WebhookID != webhook.ID || deadLetters[0].Attempts != 3 || deadLetters[0].Event.ID != "1-0" {
+				t.Errorf("unexpected dead letter %+v", deadLetters[0])
+			}
+			return
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatal("timeout waiting dead letter")
+}
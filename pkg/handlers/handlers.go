@@ -1,17 +1,22 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/IsaacDSC/featureflag/cmd/containers"
 	"github.com/IsaacDSC/featureflag/internal/auth"
 	"github.com/IsaacDSC/featureflag/internal/contenthub"
+	"github.com/IsaacDSC/featureflag/internal/env"
 	"github.com/IsaacDSC/featureflag/internal/featureflag"
 	"github.com/IsaacDSC/featureflag/internal/health"
+	"github.com/IsaacDSC/featureflag/internal/metrics"
+	"github.com/IsaacDSC/featureflag/internal/outbox"
 	"github.com/IsaacDSC/featureflag/internal/sdknotifier"
+	"github.com/IsaacDSC/featureflag/internal/webhook"
 )
 
-func NewHandlers(services containers.ServiceContainer, sub sdknotifier.Subscriber) map[string]func(w http.ResponseWriter, r *http.Request) {
+func NewHandlers(services containers.ServiceContainer, hub *sdknotifier.Hub) map[string]func(w http.ResponseWriter, r *http.Request) {
 	output := make(map[string]func(w http.ResponseWriter, r *http.Request))
 
 	for k, v := range health.NewHandler().GetRoutes() {
@@ -30,9 +35,78 @@ func NewHandlers(services containers.ServiceContainer, sub sdknotifier.Subscribe
 		output[k] = v
 	}
 
-	for k, v := range sdknotifier.NewSdkNotifyHandler(sub).GetRoutes() {
+	for k, v := range webhook.NewWebhookHandler(services.WebhookService).GetRoutes() {
+		output[k] = v
+	}
+
+	for k, v := range outbox.NewOutboxHandler(services.OutboxRelay).GetRoutes() {
+		output[k] = v
+	}
+
+	metricsConfig := metrics.Config{Auth: metrics.AuthNone}
+	if environment := env.Get(); environment != nil {
+		metricsConfig = metrics.Config{Auth: environment.MetricsAuth, Token: environment.MetricsToken}
+	}
+
+	for k, v := range metrics.NewHandler(metricsConfig).GetRoutes() {
+		output[k] = v
+	}
+
+	snapshots := NewSnapshots(services)
+
+	for k, v := range sdknotifier.NewSdkNotifyHandler(hub, snapshots).GetRoutes() {
+		output[k] = v
+	}
+
+	for k, v := range sdknotifier.NewWebSocketHandler(hub, snapshots).GetRoutes() {
 		output[k] = v
 	}
 
 	return output
 }
+
+// NewSnapshots returns the full state of each resource, sent to real time clients that can't resume
+func NewSnapshots(services containers.ServiceContainer) map[string]sdknotifier.Snapshot {
+	return map[string]sdknotifier.Snapshot{
+		"featureflag": func(ctx context.Context) (any, error) {
+			flags, err := services.FeatureFlagService.GetAllFeatureFlag(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			output := make([]featureflag.Entity, 0, len(flags))
+			for _, flag := range flags {
+				output = append(output, flag)
+			}
+
+			return output, nil
+		},
+		"contenthub": func(ctx context.Context) (any, error) {
+			contents, err := services.ContentHubService.GetAllContentHub(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			output := make([]contenthub.Entity, 0, len(contents))
+			for _, content := range contents {
+				output = append(output, content)
+			}
+
+			return output, nil
+		},
+	}
+}
+
+// NewEntryCounters returns the number of entries of each resource, observed by the featureflag.entries gauge
+func NewEntryCounters(services containers.ServiceContainer) map[string]func(ctx context.Context) (int, error) {
+	return map[string]func(ctx context.Context) (int, error){
+		"featureflag": func(ctx context.Context) (int, error) {
+			flags, err := services.FeatureFlagService.GetAllFeatureFlag(ctx)
+			return len(flags), err
+		},
+		"contenthub": func(ctx context.Context) (int, error) {
+			contents, err := services.ContentHubService.GetAllContentHub(ctx)
+			return len(contents), err
+		},
+	}
+}
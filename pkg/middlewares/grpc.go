@@ -0,0 +1,114 @@
+package middlewares
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/IsaacDSC/featureflag/pkg/ctxlog"
+	"github.com/IsaacDSC/featureflag/pkg/ctxutils"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+const authorizationMetadata = "authorization"
+
+// UnaryAuthorization is Authorization and CheckPermission for unary gRPC calls,
+// permissions maps the full method name to the client allowed to call it, any client may call the methods not in it
+func UnaryAuthorization(permissions map[string]string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authorize(ctx, info.FullMethod, permissions)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuthorization is UnaryAuthorization for streaming gRPC calls
+func StreamAuthorization(permissions map[string]string) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authorize(ss.Context(), info.FullMethod, permissions)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &wrappedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// UnaryLogger is Logger for unary gRPC calls
+func UnaryLogger() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		ctx, logger := withLogger(ctx, info.FullMethod)
+
+		resp, err := handler(ctx, req)
+
+		logger.Info("gRPC Request",
+			"code", status.Code(err).String(),
+			"duration_ms", time.Since(start).Milliseconds(),
+		)
+
+		return resp, err
+	}
+}
+
+// StreamLogger is Logger for streaming gRPC calls, logged when the stream ends
+func StreamLogger() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		ctx, logger := withLogger(ss.Context(), info.FullMethod)
+
+		err := handler(srv, &wrappedStream{ServerStream: ss, ctx: ctx})
+
+		logger.Info("gRPC Stream",
+			"code", status.Code(err).String(),
+			"duration_ms", time.Since(start).Milliseconds(),
+		)
+
+		return err
+	}
+}
+
+func withLogger(ctx context.Context, method string) (context.Context, *slog.Logger) {
+	logger := ctxlog.NewLogger(ctx).With("method", method)
+	if p, ok := peer.FromContext(ctx); ok {
+		logger = logger.With("remote_addr", p.Addr.String())
+	}
+
+	return ctxlog.SetLogger(ctx, logger), logger
+}
+
+func authorize(ctx context.Context, method string, permissions map[string]string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get(authorizationMetadata)
+	if len(values) == 0 || values[0] == "" {
+		return nil, status.Error(codes.Unauthenticated, "authorization is required")
+	}
+
+	clientName, err := getClientPermission(values[0])
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	if permission, ok := permissions[method]; ok && permission != clientName {
+		return nil, status.Error(codes.PermissionDenied, "client not allowed")
+	}
+
+	return ctxutils.SetContext(ctx, KEY, clientName), nil
+}
+
+// wrappedStream replaces the context of a stream with the one enriched by the interceptors
+type wrappedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *wrappedStream) Context() context.Context {
+	return s.ctx
+}
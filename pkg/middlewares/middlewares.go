@@ -13,26 +13,47 @@ import (
 	"github.com/IsaacDSC/featureflag/pkg/authutils"
 	"github.com/IsaacDSC/featureflag/pkg/ctxlog"
 	"github.com/IsaacDSC/featureflag/pkg/ctxutils"
+	"github.com/IsaacDSC/featureflag/pkg/telemetry"
 )
 
 const (
 	KEY              = "client"
 	USERNAME_SERVICE = "SERVICE_CLIENT"
 	USERNAME_SDK     = "SDK_CLIENT"
+	USERNAME_PREVIEW = "PREVIEW_CLIENT"
 )
 
 func getClientPermission(key string) (string, error) {
 	cfg := env.Get()
-	if client, ok := map[string]string{
+	clients := map[string]string{
 		cfg.ServiceClientAT: USERNAME_SERVICE,
 		cfg.SDKClientAT:     USERNAME_SDK,
-	}[key]; ok {
+	}
+
+	// the preview token is optional, without it there are no previews
+	if cfg.PreviewClientAT != "" {
+		clients[cfg.PreviewClientAT] = USERNAME_PREVIEW
+	}
+
+	if client, ok := clients[key]; ok {
 		return client, nil
 	}
 
 	return "", errors.New("client not found")
 }
 
+// IsClient reports whether the Authorization header of r is the token of client, for the routes
+// open to every client whose response depends on it
+func IsClient(r *http.Request, client string) bool {
+	authorization := r.Header.Get("Authorization")
+	if authorization == "" {
+		return false
+	}
+
+	name, err := getClientPermission(authorization)
+	return err == nil && name == client
+}
+
 func CheckPermission(h http.HandlerFunc, permission string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		key := ctxutils.GetValueCtx(r.Context(), KEY)
@@ -102,13 +123,23 @@ func newResponseWriter(w http.ResponseWriter) *responseWriter {
 	}
 }
 
+// newStatusWriter only captures the status code, for the middlewares that don't log the body
+func newStatusWriter(w http.ResponseWriter) *responseWriter {
+	return &responseWriter{
+		ResponseWriter: w,
+		statusCode:     http.StatusOK,
+	}
+}
+
 func (rw *responseWriter) WriteHeader(code int) {
 	rw.statusCode = code
 	rw.ResponseWriter.WriteHeader(code)
 }
 
 func (rw *responseWriter) Write(b []byte) (int, error) {
-	rw.body.Write(b)
+	if rw.body != nil {
+		rw.body.Write(b)
+	}
 	return rw.ResponseWriter.Write(b)
 }
 
@@ -135,8 +166,9 @@ func (rw *responseWriter) Push(target string, opts *http.PushOptions) error {
 	return errors.New("http.Pusher not supported")
 }
 
-// Logger middleware adiciona logger ao contexto e loga todas as responses
-func Logger(h http.HandlerFunc) http.HandlerFunc {
+// Logger middleware adiciona logger ao contexto, loga todas as responses e registra
+// a duração por route, o pattern com que o handler foi registrado
+func Logger(route string, h http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 
@@ -174,6 +206,7 @@ func Logger(h http.HandlerFunc) http.HandlerFunc {
 
 		// Calcula duração
 		duration := time.Since(start)
+		telemetry.RecordRequest(ctx, route, rw.statusCode, duration)
 
 		// Loga a response
 		logger.Info("HTTP Request",
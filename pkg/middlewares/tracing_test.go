@@ -0,0 +1,57 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/IsaacDSC/featureflag/pkg/telemetry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracing(t *testing.T) {
+	memory := telemetry.NewInMemory()
+	memory.Install()
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	handler := Tracing("GET /featureflag/{key}", func(w http.ResponseWriter, r *http.Request) {
+		if !trace.SpanFromContext(r.Context()).SpanContext().IsValid() {
+			t.Error("handler context without span")
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/featureflag/checkout", nil)
+	r.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	handler(httptest.NewRecorder(), r)
+
+	spans := memory.Spans()
+	if len(spans) != 1 {
+		t.Fatalf("recorded %d spans, want 1", len(spans))
+	}
+
+	span := spans[0]
+	if span.Name != "GET /featureflag/{key}" || span.SpanKind != trace.SpanKindServer {
+		t.Errorf("span = %s (%s), want the server span of the route", span.Name, span.SpanKind)
+	}
+
+	if span.SpanContext.TraceID().String() != traceID {
+		t.Errorf("trace id = %s, want the one of traceparent", span.SpanContext.TraceID())
+	}
+
+	if span.Status.Code != codes.Error {
+		t.Errorf("status = %v, want error", span.Status.Code)
+	}
+
+	want := attribute.Int("http.response.status_code", http.StatusInternalServerError)
+	found := false
+	for _, attr := range span.Attributes {
+		found = found || attr == want
+	}
+	if !found {
+		t.Errorf("attributes %v without %v", span.Attributes, want)
+	}
+}
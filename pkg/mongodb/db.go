@@ -34,3 +34,17 @@ func CreateUniqueIndex(collection *mongo.Collection, indexModel IndexModel) erro
 	_, err := collection.Indexes().CreateOne(ctx, idxModel)
 	return err
 }
+
+// CreateTTLIndex creates an index removing the documents once indexModel is older than ttl, with timeout of 2 seconds
+func CreateTTLIndex(collection *mongo.Collection, indexModel IndexModel, ttl time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	idxModel := mongo.IndexModel{
+		Keys:    bson.M{indexModel.String(): 1},
+		Options: options.Index().SetExpireAfterSeconds(int32(ttl.Seconds())),
+	}
+
+	_, err := collection.Indexes().CreateOne(ctx, idxModel)
+	return err
+}
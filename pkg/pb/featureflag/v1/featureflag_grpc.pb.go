@@ -0,0 +1,556 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v4.25.3
+// source: featureflag/v1/featureflag.proto
+
+package featureflagv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	FeatureFlagService_UpsertFlag_FullMethodName     = "/featureflag.v1.FeatureFlagService/UpsertFlag"
+	FeatureFlagService_GetFlag_FullMethodName        = "/featureflag.v1.FeatureFlagService/GetFlag"
+	FeatureFlagService_ListFlags_FullMethodName      = "/featureflag.v1.FeatureFlagService/ListFlags"
+	FeatureFlagService_DeleteFlag_FullMethodName     = "/featureflag.v1.FeatureFlagService/DeleteFlag"
+	FeatureFlagService_UpsertContent_FullMethodName  = "/featureflag.v1.FeatureFlagService/UpsertContent"
+	FeatureFlagService_PublishContent_FullMethodName = "/featureflag.v1.FeatureFlagService/PublishContent"
+	FeatureFlagService_GetContent_FullMethodName     = "/featureflag.v1.FeatureFlagService/GetContent"
+	FeatureFlagService_ListContents_FullMethodName   = "/featureflag.v1.FeatureFlagService/ListContents"
+	FeatureFlagService_DeleteContent_FullMethodName  = "/featureflag.v1.FeatureFlagService/DeleteContent"
+	FeatureFlagService_Evaluate_FullMethodName       = "/featureflag.v1.FeatureFlagService/Evaluate"
+	FeatureFlagService_EvaluateAll_FullMethodName    = "/featureflag.v1.FeatureFlagService/EvaluateAll"
+	FeatureFlagService_Watch_FullMethodName          = "/featureflag.v1.FeatureFlagService/Watch"
+)
+
+// FeatureFlagServiceClient is the client API for FeatureFlagService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type FeatureFlagServiceClient interface {
+	UpsertFlag(ctx context.Context, in *UpsertFlagRequest, opts ...grpc.CallOption) (*UpsertFlagResponse, error)
+	GetFlag(ctx context.Context, in *GetFlagRequest, opts ...grpc.CallOption) (*Flag, error)
+	ListFlags(ctx context.Context, in *ListFlagsRequest, opts ...grpc.CallOption) (*ListFlagsResponse, error)
+	DeleteFlag(ctx context.Context, in *DeleteFlagRequest, opts ...grpc.CallOption) (*DeleteFlagResponse, error)
+	// UpsertContent saves the draft of a content, the SDKs receive it on PublishContent
+	UpsertContent(ctx context.Context, in *UpsertContentRequest, opts ...grpc.CallOption) (*UpsertContentResponse, error)
+	// PublishContent promotes the draft of a content, FAILED_PRECONDITION when it has none
+	PublishContent(ctx context.Context, in *PublishContentRequest, opts ...grpc.CallOption) (*Content, error)
+	GetContent(ctx context.Context, in *GetContentRequest, opts ...grpc.CallOption) (*Content, error)
+	ListContents(ctx context.Context, in *ListContentsRequest, opts ...grpc.CallOption) (*ListContentsResponse, error)
+	DeleteContent(ctx context.Context, in *DeleteContentRequest, opts ...grpc.CallOption) (*DeleteContentResponse, error)
+	// Evaluate returns whether a flag is active for the session, applying its strategy
+	Evaluate(ctx context.Context, in *EvaluateRequest, opts ...grpc.CallOption) (*EvaluateResponse, error)
+	// EvaluateAll evaluates every flag for the session
+	EvaluateAll(ctx context.Context, in *EvaluateAllRequest, opts ...grpc.CallOption) (*EvaluateAllResponse, error)
+	// Watch streams the changes of a resource ("featureflag" or "contenthub"),
+	// the same events sent by GET /events/{resource}
+	Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (FeatureFlagService_WatchClient, error)
+}
+
+type featureFlagServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewFeatureFlagServiceClient(cc grpc.ClientConnInterface) FeatureFlagServiceClient {
+	return &featureFlagServiceClient{cc}
+}
+
+func (c *featureFlagServiceClient) UpsertFlag(ctx context.Context, in *UpsertFlagRequest, opts ...grpc.CallOption) (*UpsertFlagResponse, error) {
+	out := new(UpsertFlagResponse)
+	err := c.cc.Invoke(ctx, FeatureFlagService_UpsertFlag_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *featureFlagServiceClient) GetFlag(ctx context.Context, in *GetFlagRequest, opts ...grpc.CallOption) (*Flag, error) {
+	out := new(Flag)
+	err := c.cc.Invoke(ctx, FeatureFlagService_GetFlag_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *featureFlagServiceClient) ListFlags(ctx context.Context, in *ListFlagsRequest, opts ...grpc.CallOption) (*ListFlagsResponse, error) {
+	out := new(ListFlagsResponse)
+	err := c.cc.Invoke(ctx, FeatureFlagService_ListFlags_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *featureFlagServiceClient) DeleteFlag(ctx context.Context, in *DeleteFlagRequest, opts ...grpc.CallOption) (*DeleteFlagResponse, error) {
+	out := new(DeleteFlagResponse)
+	err := c.cc.Invoke(ctx, FeatureFlagService_DeleteFlag_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *featureFlagServiceClient) UpsertContent(ctx context.Context, in *UpsertContentRequest, opts ...grpc.CallOption) (*UpsertContentResponse, error) {
+	out := new(UpsertContentResponse)
+	err := c.cc.Invoke(ctx, FeatureFlagService_UpsertContent_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *featureFlagServiceClient) PublishContent(ctx context.Context, in *PublishContentRequest, opts ...grpc.CallOption) (*Content, error) {
+	out := new(Content)
+	err := c.cc.Invoke(ctx, FeatureFlagService_PublishContent_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *featureFlagServiceClient) GetContent(ctx context.Context, in *GetContentRequest, opts ...grpc.CallOption) (*Content, error) {
+	out := new(Content)
+	err := c.cc.Invoke(ctx, FeatureFlagService_GetContent_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *featureFlagServiceClient) ListContents(ctx context.Context, in *ListContentsRequest, opts ...grpc.CallOption) (*ListContentsResponse, error) {
+	out := new(ListContentsResponse)
+	err := c.cc.Invoke(ctx, FeatureFlagService_ListContents_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *featureFlagServiceClient) DeleteContent(ctx context.Context, in *DeleteContentRequest, opts ...grpc.CallOption) (*DeleteContentResponse, error) {
+	out := new(DeleteContentResponse)
+	err := c.cc.Invoke(ctx, FeatureFlagService_DeleteContent_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *featureFlagServiceClient) Evaluate(ctx context.Context, in *EvaluateRequest, opts ...grpc.CallOption) (*EvaluateResponse, error) {
+	out := new(EvaluateResponse)
+	err := c.cc.Invoke(ctx, FeatureFlagService_Evaluate_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *featureFlagServiceClient) EvaluateAll(ctx context.Context, in *EvaluateAllRequest, opts ...grpc.CallOption) (*EvaluateAllResponse, error) {
+	out := new(EvaluateAllResponse)
+	err := c.cc.Invoke(ctx, FeatureFlagService_EvaluateAll_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *featureFlagServiceClient) Watch(ctx context.Context, in *WatchRequest, opts ...grpc.CallOption) (FeatureFlagService_WatchClient, error) {
+	stream, err := c.cc.NewStream(ctx, &FeatureFlagService_ServiceDesc.Streams[0], FeatureFlagService_Watch_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &featureFlagServiceWatchClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type FeatureFlagService_WatchClient interface {
+	Recv() (*WatchEvent, error)
+	grpc.ClientStream
+}
+
+type featureFlagServiceWatchClient struct {
+	grpc.ClientStream
+}
+
+func (x *featureFlagServiceWatchClient) Recv() (*WatchEvent, error) {
+	m := new(WatchEvent)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// FeatureFlagServiceServer is the server API for FeatureFlagService service.
+// All implementations must embed UnimplementedFeatureFlagServiceServer
+// for forward compatibility
+type FeatureFlagServiceServer interface {
+	UpsertFlag(context.Context, *UpsertFlagRequest) (*UpsertFlagResponse, error)
+	GetFlag(context.Context, *GetFlagRequest) (*Flag, error)
+	ListFlags(context.Context, *ListFlagsRequest) (*ListFlagsResponse, error)
+	DeleteFlag(context.Context, *DeleteFlagRequest) (*DeleteFlagResponse, error)
+	// UpsertContent saves the draft of a content, the SDKs receive it on PublishContent
+	UpsertContent(context.Context, *UpsertContentRequest) (*UpsertContentResponse, error)
+	// PublishContent promotes the draft of a content, FAILED_PRECONDITION when it has none
+	PublishContent(context.Context, *PublishContentRequest) (*Content, error)
+	GetContent(context.Context, *GetContentRequest) (*Content, error)
+	ListContents(context.Context, *ListContentsRequest) (*ListContentsResponse, error)
+	DeleteContent(context.Context, *DeleteContentRequest) (*DeleteContentResponse, error)
+	// Evaluate returns whether a flag is active for the session, applying its strategy
+	Evaluate(context.Context, *EvaluateRequest) (*EvaluateResponse, error)
+	// EvaluateAll evaluates every flag for the session
+	EvaluateAll(context.Context, *EvaluateAllRequest) (*EvaluateAllResponse, error)
+	// Watch streams the changes of a resource ("featureflag" or "contenthub"),
+	// the same events sent by GET /events/{resource}
+	Watch(*WatchRequest, FeatureFlagService_WatchServer) error
+	mustEmbedUnimplementedFeatureFlagServiceServer()
+}
+
+// UnimplementedFeatureFlagServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedFeatureFlagServiceServer struct {
+}
+
+func (UnimplementedFeatureFlagServiceServer) UpsertFlag(context.Context, *UpsertFlagRequest) (*UpsertFlagResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpsertFlag not implemented")
+}
+func (UnimplementedFeatureFlagServiceServer) GetFlag(context.Context, *GetFlagRequest) (*Flag, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetFlag not implemented")
+}
+func (UnimplementedFeatureFlagServiceServer) ListFlags(context.Context, *ListFlagsRequest) (*ListFlagsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFlags not implemented")
+}
+func (UnimplementedFeatureFlagServiceServer) DeleteFlag(context.Context, *DeleteFlagRequest) (*DeleteFlagResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteFlag not implemented")
+}
+func (UnimplementedFeatureFlagServiceServer) UpsertContent(context.Context, *UpsertContentRequest) (*UpsertContentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpsertContent not implemented")
+}
+func (UnimplementedFeatureFlagServiceServer) PublishContent(context.Context, *PublishContentRequest) (*Content, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PublishContent not implemented")
+}
+func (UnimplementedFeatureFlagServiceServer) GetContent(context.Context, *GetContentRequest) (*Content, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetContent not implemented")
+}
+func (UnimplementedFeatureFlagServiceServer) ListContents(context.Context, *ListContentsRequest) (*ListContentsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListContents not implemented")
+}
+func (UnimplementedFeatureFlagServiceServer) DeleteContent(context.Context, *DeleteContentRequest) (*DeleteContentResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteContent not implemented")
+}
+func (UnimplementedFeatureFlagServiceServer) Evaluate(context.Context, *EvaluateRequest) (*EvaluateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Evaluate not implemented")
+}
+func (UnimplementedFeatureFlagServiceServer) EvaluateAll(context.Context, *EvaluateAllRequest) (*EvaluateAllResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EvaluateAll not implemented")
+}
+func (UnimplementedFeatureFlagServiceServer) Watch(*WatchRequest, FeatureFlagService_WatchServer) error {
+	return status.Errorf(codes.Unimplemented, "method Watch not implemented")
+}
+func (UnimplementedFeatureFlagServiceServer) mustEmbedUnimplementedFeatureFlagServiceServer() {}
+
+// UnsafeFeatureFlagServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to FeatureFlagServiceServer will
+// result in compilation errors.
+type UnsafeFeatureFlagServiceServer interface {
+	mustEmbedUnimplementedFeatureFlagServiceServer()
+}
+
+func RegisterFeatureFlagServiceServer(s grpc.ServiceRegistrar, srv FeatureFlagServiceServer) {
+	s.RegisterService(&FeatureFlagService_ServiceDesc, srv)
+}
+
+func _FeatureFlagService_UpsertFlag_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpsertFlagRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FeatureFlagServiceServer).UpsertFlag(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FeatureFlagService_UpsertFlag_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FeatureFlagServiceServer).UpsertFlag(ctx, req.(*UpsertFlagRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FeatureFlagService_GetFlag_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetFlagRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FeatureFlagServiceServer).GetFlag(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FeatureFlagService_GetFlag_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FeatureFlagServiceServer).GetFlag(ctx, req.(*GetFlagRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FeatureFlagService_ListFlags_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListFlagsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FeatureFlagServiceServer).ListFlags(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FeatureFlagService_ListFlags_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FeatureFlagServiceServer).ListFlags(ctx, req.(*ListFlagsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FeatureFlagService_DeleteFlag_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteFlagRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FeatureFlagServiceServer).DeleteFlag(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FeatureFlagService_DeleteFlag_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FeatureFlagServiceServer).DeleteFlag(ctx, req.(*DeleteFlagRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FeatureFlagService_UpsertContent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpsertContentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FeatureFlagServiceServer).UpsertContent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FeatureFlagService_UpsertContent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FeatureFlagServiceServer).UpsertContent(ctx, req.(*UpsertContentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FeatureFlagService_PublishContent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PublishContentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FeatureFlagServiceServer).PublishContent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FeatureFlagService_PublishContent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FeatureFlagServiceServer).PublishContent(ctx, req.(*PublishContentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FeatureFlagService_GetContent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetContentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FeatureFlagServiceServer).GetContent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FeatureFlagService_GetContent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FeatureFlagServiceServer).GetContent(ctx, req.(*GetContentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FeatureFlagService_ListContents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListContentsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FeatureFlagServiceServer).ListContents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FeatureFlagService_ListContents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FeatureFlagServiceServer).ListContents(ctx, req.(*ListContentsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FeatureFlagService_DeleteContent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteContentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FeatureFlagServiceServer).DeleteContent(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FeatureFlagService_DeleteContent_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FeatureFlagServiceServer).DeleteContent(ctx, req.(*DeleteContentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FeatureFlagService_Evaluate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EvaluateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FeatureFlagServiceServer).Evaluate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FeatureFlagService_Evaluate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FeatureFlagServiceServer).Evaluate(ctx, req.(*EvaluateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FeatureFlagService_EvaluateAll_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EvaluateAllRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FeatureFlagServiceServer).EvaluateAll(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FeatureFlagService_EvaluateAll_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FeatureFlagServiceServer).EvaluateAll(ctx, req.(*EvaluateAllRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FeatureFlagService_Watch_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FeatureFlagServiceServer).Watch(m, &featureFlagServiceWatchServer{stream})
+}
+
+type FeatureFlagService_WatchServer interface {
+	Send(*WatchEvent) error
+	grpc.ServerStream
+}
+
+type featureFlagServiceWatchServer struct {
+	grpc.ServerStream
+}
+
+func (x *featureFlagServiceWatchServer) Send(m *WatchEvent) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// FeatureFlagService_ServiceDesc is the grpc.ServiceDesc for FeatureFlagService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var FeatureFlagService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "featureflag.v1.FeatureFlagService",
+	HandlerType: (*FeatureFlagServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "UpsertFlag",
+			Handler:    _FeatureFlagService_UpsertFlag_Handler,
+		},
+		{
+			MethodName: "GetFlag",
+			Handler:    _FeatureFlagService_GetFlag_Handler,
+		},
+		{
+			MethodName: "ListFlags",
+			Handler:    _FeatureFlagService_ListFlags_Handler,
+		},
+		{
+			MethodName: "DeleteFlag",
+			Handler:    _FeatureFlagService_DeleteFlag_Handler,
+		},
+		{
+			MethodName: "UpsertContent",
+			Handler:    _FeatureFlagService_UpsertContent_Handler,
+		},
+		{
+			MethodName: "PublishContent",
+			Handler:    _FeatureFlagService_PublishContent_Handler,
+		},
+		{
+			MethodName: "GetContent",
+			Handler:    _FeatureFlagService_GetContent_Handler,
+		},
+		{
+			MethodName: "ListContents",
+			Handler:    _FeatureFlagService_ListContents_Handler,
+		},
+		{
+			MethodName: "DeleteContent",
+			Handler:    _FeatureFlagService_DeleteContent_Handler,
+		},
+		{
+			MethodName: "Evaluate",
+			Handler:    _FeatureFlagService_Evaluate_Handler,
+		},
+		{
+			MethodName: "EvaluateAll",
+			Handler:    _FeatureFlagService_EvaluateAll_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Watch",
+			Handler:       _FeatureFlagService_Watch_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "featureflag/v1/featureflag.proto",
+}
@@ -0,0 +1,124 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// KeyField is the field added to the JSON object payloads with the key of their change, given by the source
+// of the change (the id of the outbox entry, the resume token of the change stream). Unlike the event id,
+// it is the same on every node and when the change is published again.
+const KeyField = "event_key"
+
+// WithKey returns data, a JSON object, with key in its KeyField
+func WithKey(data []byte, key string) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("error on add event key: %w", err)
+	}
+
+	b, err := json.Marshal(key)
+	if err != nil {
+		return nil, err
+	}
+
+	fields[KeyField] = b
+	return json.Marshal(fields)
+}
+
+// Key returns the KeyField of msg, empty when it has none
+func Key(msg Msg) string {
+	var payload struct {
+		Key string `json:"event_key"`
+	}
+
+	if err := json.Unmarshal(msg, &payload); err != nil {
+		return ""
+	}
+
+	return payload.Key
+}
+
+type ctxKeyEventID struct{}
+
+type ctxKeyLastEventID struct{}
+
+// WithEventID attaches the id of the event being delivered to the handler context
+func WithEventID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ctxKeyEventID{}, id)
+}
+
+// EventID returns the id of the event being delivered, empty when the backend has no ids
+func EventID(ctx context.Context) string {
+	id, _ := ctx.Value(ctxKeyEventID{}).(string)
+	return id
+}
+
+// WithLastEventID asks the subscriber to replay every event after id before listening for new ones
+func WithLastEventID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, ctxKeyLastEventID{}, id)
+}
+
+func LastEventID(ctx context.Context) string {
+	id, _ := ctx.Value(ctxKeyLastEventID{}).(string)
+	return id
+}
+
+var eventSeq atomic.Uint64
+
+// newEventID generates an id in the same "<ms>-<seq>" format used by redis streams,
+// for backends that do not assign ids to their events
+func newEventID() string {
+	return fmt.Sprintf("%d-%d", time.Now().UnixMilli(), eventSeq.Add(1))
+}
+
+func channelName(channel string) string {
+	return fmt.Sprintf("events.fanout.%s", channel)
+}
+
+// CompareEventID compares two "<ms>-<seq>" event ids, returning -1, 0 or 1.
+// It returns an error when one of them is not in that format.
+func CompareEventID(a, b string) (int, error) {
+	aMs, aSeq, err := parseEventID(a)
+	if err != nil {
+		return 0, err
+	}
+
+	bMs, bSeq, err := parseEventID(b)
+	if err != nil {
+		return 0, err
+	}
+
+	switch {
+	case aMs < bMs || (aMs == bMs && aSeq < bSeq):
+		return -1, nil
+	case aMs == bMs && aSeq == bSeq:
+		return 0, nil
+	default:
+		return 1, nil
+	}
+}
+
+func parseEventID(id string) (uint64, uint64, error) {
+	ms, seq, ok := strings.Cut(id, "-")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid event id %q", id)
+	}
+
+	msValue, err := strconv.ParseUint(ms, 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid event id %q: %w", id, err)
+	}
+
+	seqValue, err := strconv.ParseUint(seq, 10, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid event id %q: %w", id, err)
+	}
+
+	return msValue, seqValue, nil
+}
@@ -0,0 +1,171 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/IsaacDSC/featureflag/pkg/ctxlog"
+)
+
+const memoryListenerBuffer = 256
+
+var ErrSlowListener = errors.New("listener too slow, events dropped")
+
+type memoryEvent struct {
+	id   string
+	data []byte
+}
+
+type memoryTopic struct {
+	history   []memoryEvent
+	listeners map[chan memoryEvent]struct{}
+}
+
+// Memory delivers the events inside the process through channels, for running a single
+// node without redis. It implements both Publisher and Subscriber with the same events.fanout.*
+// channels, and keeps the last maxLen events of each one so a listener started with
+// WithLastEventID replays what it missed, like the stream backend.
+type Memory struct {
+	mu     sync.Mutex
+	maxLen int
+	topics map[string]*memoryTopic
+}
+
+func NewMemory(maxLen int) *Memory {
+	return &Memory{
+		maxLen: maxLen,
+		topics: make(map[string]*memoryTopic),
+	}
+}
+
+// topic must be called holding mu
+func (m *Memory) topic(channel string) *memoryTopic {
+	name := channelName(channel)
+	t, ok := m.topics[name]
+	if !ok {
+		t = &memoryTopic{listeners: make(map[chan memoryEvent]struct{})}
+		m.topics[name] = t
+	}
+
+	return t
+}
+
+func (m *Memory) Publish(ctx context.Context, channel string, msg Payload) error {
+	l := ctxlog.GetLogger(ctx)
+	b, err := json.Marshal(msg.data)
+	if err != nil {
+		l.Error("marshal payload", "error", err)
+		return fmt.Errorf("marshal payload: %v", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t := m.topic(channel)
+	event := memoryEvent{id: newEventID(), data: b}
+
+	if m.maxLen > 0 {
+		t.history = append(t.history, event)
+		if len(t.history) > m.maxLen {
+			t.history = t.history[len(t.history)-m.maxLen:]
+		}
+	}
+
+	// a listener that cannot keep up is closed instead of blocking the publisher,
+	// it returns ErrSlowListener and can restart from the last id it delivered
+	for ch := range t.listeners {
+		select {
+		case ch <- event:
+		default:
+			delete(t.listeners, ch)
+			close(ch)
+		}
+	}
+
+	l.Debug("publish msg", "channel", channel, "msg", msg.data)
+
+	return nil
+}
+
+// Listener calls fn for every event published on channel until ctx is done. With WithLastEventID
+// it first replays the retained events after that id, nothing is replayed when the id is unknown.
+func (m *Memory) Listener(ctx context.Context, channel string, fn Handler) error {
+	ch := make(chan memoryEvent, memoryListenerBuffer)
+
+	m.mu.Lock()
+	t := m.topic(channel)
+	replay := eventsAfter(t.history, LastEventID(ctx))
+	t.listeners[ch] = struct{}{}
+	m.mu.Unlock()
+
+	defer func() {
+		m.mu.Lock()
+		if _, ok := t.listeners[ch]; ok {
+			delete(t.listeners, ch)
+			close(ch)
+		}
+		m.mu.Unlock()
+	}()
+
+	log.Printf("listening on memory channel %q", channel)
+	for _, event := range replay {
+		if ctx.Err() != nil {
+			return nil
+		}
+		m.handle(ctx, channel, event, fn)
+	}
+
+	for {
+		select {
+		case event, ok := <-ch:
+			if !ok {
+				return ErrSlowListener
+			}
+			m.handle(ctx, channel, event, fn)
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func (m *Memory) handle(ctx context.Context, channel string, event memoryEvent, fn Handler) {
+	if err := fn(WithEventID(ctx, event.id), event.data); err != nil {
+		log.Printf("error processing channel %s: %v\n", channel, err)
+	}
+}
+
+// LastID returns the id of the newest retained event of channel
+func (m *Memory) LastID(ctx context.Context, channel string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t := m.topic(channel)
+	if len(t.history) == 0 {
+		return streamFirstID, nil
+	}
+
+	return t.history[len(t.history)-1].id, nil
+}
+
+// eventsAfter returns a copy of the events after id, streamFirstID means the whole history
+func eventsAfter(history []memoryEvent, id string) []memoryEvent {
+	if id == "" {
+		return nil
+	}
+
+	if id == streamFirstID {
+		return append([]memoryEvent(nil), history...)
+	}
+
+	for i := range history {
+		if history[i].id == id {
+			return append([]memoryEvent(nil), history[i+1:]...)
+		}
+	}
+
+	return nil
+}
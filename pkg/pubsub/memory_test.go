@@ -0,0 +1,180 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/IsaacDSC/featureflag/pkg/ctxlog"
+)
+
+type received struct {
+	id    string
+	value string
+}
+
+func listen(t *testing.T, ctx context.Context, m *Memory, channel string) (<-chan received, <-chan error) {
+	t.Helper()
+
+	out := make(chan received, 10)
+	errs := make(chan error, 1)
+	go func() {
+		errs <- m.Listener(ctx, channel, func(ctx context.Context, msg Msg) error {
+			var value string
+			if err := msg.ToJson(&value); err != nil {
+				return err
+			}
+			out <- received{id: EventID(ctx), value: value}
+			return nil
+		})
+	}()
+
+	return out, errs
+}
+
+func next(t *testing.T, ch <-chan received) received {
+	t.Helper()
+
+	select {
+	case r := <-ch:
+		return r
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting event")
+		return received{}
+	}
+}
+
+// waitListeners waits until n listeners are registered on channel
+func waitListeners(t *testing.T, m *Memory, channel string, n int) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		m.mu.Lock()
+		count := len(m.topic(channel).listeners)
+		m.mu.Unlock()
+
+		if count == n {
+			return
+		}
+
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	t.Fatalf("timeout waiting %d listeners", n)
+}
+
+func TestMemory_FanOut(t *testing.T) {
+	ctx := ctxlog.SetLogger(context.Background(), slog.Default())
+	m := NewMemory(10)
+
+	listenCtx, cancel := context.WithCancel(context.Background())
+	first, _ := listen(t, listenCtx, m, "featureflag")
+	second, _ := listen(t, listenCtx, m, "featureflag")
+	other, otherErrs := listen(t, listenCtx, m, "contenthub")
+	waitListeners(t, m, "featureflag", 2)
+	waitListeners(t, m, "contenthub", 1)
+
+	if err := m.Publish(ctx, "featureflag", NewPayload("a")); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	a, b := next(t, first), next(t, second)
+	if a.value != "a" || b.value != "a" {
+		t.Errorf("Listener() received %q and %q, want a", a.value, b.value)
+	}
+
+	if a.id == "" || a.id != b.id {
+		t.Errorf("Listener() ids = %q and %q, want the same non empty id", a.id, b.id)
+	}
+
+	select {
+	case r := <-other:
+		t.Errorf("listener of another channel received %+v", r)
+	default:
+	}
+
+	cancel()
+	if err := <-otherErrs; err != nil {
+		t.Errorf("Listener() error = %v after ctx done, want nil", err)
+	}
+}
+
+func TestMemory_Replay(t *testing.T) {
+	ctx := ctxlog.SetLogger(context.Background(), slog.Default())
+	m := NewMemory(2)
+
+	if id, _ := m.LastID(ctx, "featureflag"); id != streamFirstID {
+		t.Errorf("LastID() = %q on empty channel, want %q", id, streamFirstID)
+	}
+
+	var ids []string
+	for _, msg := range []string{"first", "second", "third"} {
+		if err := m.Publish(ctx, "featureflag", NewPayload(msg)); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+		id, _ := m.LastID(ctx, "featureflag")
+		ids = append(ids, id)
+	}
+
+	listenCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// only the last two events are retained
+	all, _ := listen(t, WithLastEventID(listenCtx, streamFirstID), m, "featureflag")
+	for i, want := range []string{"second", "third"} {
+		if got := next(t, all); got.value != want || got.id != ids[i+1] {
+			t.Errorf("replay received %+v, want %s with id %s", got, want, ids[i+1])
+		}
+	}
+
+	after, _ := listen(t, WithLastEventID(listenCtx, ids[1]), m, "featureflag")
+	if got := next(t, after); got.value != "third" {
+		t.Errorf("replay after %s received %q, want third", ids[1], got.value)
+	}
+
+	fresh, _ := listen(t, listenCtx, m, "featureflag")
+	waitListeners(t, m, "featureflag", 3)
+
+	if err := m.Publish(ctx, "featureflag", NewPayload("fourth")); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	if got := next(t, fresh); got.value != "fourth" {
+		t.Errorf("new listener received %q, want fourth", got.value)
+	}
+}
+
+func TestMemory_SlowListener(t *testing.T) {
+	ctx := ctxlog.SetLogger(context.Background(), slog.Default())
+	m := NewMemory(0)
+
+	block := make(chan struct{})
+	errs := make(chan error, 1)
+	go func() {
+		errs <- m.Listener(context.Background(), "featureflag", func(ctx context.Context, msg Msg) error {
+			<-block
+			return nil
+		})
+	}()
+	waitListeners(t, m, "featureflag", 1)
+
+	// one event is held by the handler and memoryListenerBuffer fill the channel
+	for i := 0; i <= memoryListenerBuffer+1; i++ {
+		if err := m.Publish(ctx, "featureflag", NewPayload(i)); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+	}
+	close(block)
+
+	select {
+	case err := <-errs:
+		if !errors.Is(err, ErrSlowListener) {
+			t.Errorf("Listener() error = %v, want %v", err, ErrSlowListener)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("slow listener was not closed")
+	}
+}
@@ -0,0 +1,31 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Publisher sends msg to every Listener of channel
+type Publisher interface {
+	Publish(ctx context.Context, channel string, msg Payload) error
+}
+
+// Subscriber calls fn for every message published on channel until ctx is done,
+// the id of each event is in the handler context (see EventID)
+type Subscriber interface {
+	Listener(ctx context.Context, channel string, fn Handler) error
+}
+
+type Msg []byte
+
+func (m Msg) ToJson(value any) error {
+	return json.Unmarshal(m, value)
+}
+
+type Handler func(ctx context.Context, msg Msg) error
+
+// Entry is a message retained by a backend with its event id
+type Entry struct {
+	ID  string
+	Msg Msg
+}
@@ -0,0 +1,83 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/IsaacDSC/featureflag/pkg/ctxlog"
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisPublisher sends the events with PUBLISH, only the subscribers connected at that moment receive them
+type RedisPublisher struct {
+	rdb *redis.Client
+}
+
+func NewRedisPublisher(rdb *redis.Client) RedisPublisher {
+	return RedisPublisher{
+		rdb: rdb,
+	}
+}
+
+const (
+	publishMaxAttempts = 3
+	publishBackoff     = 100 * time.Millisecond
+)
+
+type Payload struct {
+	data any
+}
+
+func NewPayload(msg any) Payload {
+	return Payload{data: msg}
+}
+
+// MarshalJSON encodes the data of the payload, as published
+func (p Payload) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.data)
+}
+
+// Publish tries publishMaxAttempts times, doubling the wait between the attempts,
+// and returns the last error when all of them fail. PUBLISH has no fields for the trace context,
+// so the consumer spans start new traces.
+func (p RedisPublisher) Publish(ctx context.Context, channel string, msg Payload) (err error) {
+	ctx, span := startPublish(ctx, "redis", channel)
+	defer func() { endPublish(ctx, span, channel, err) }()
+
+	l := ctxlog.GetLogger(ctx)
+	b, err := json.Marshal(msg.data)
+	if err != nil {
+		l.Error("marshal payload", "error", err)
+		return fmt.Errorf("marshal payload: %v", err)
+	}
+
+	backoff := publishBackoff
+	for attempt := 1; ; attempt++ {
+		err = p.rdb.Publish(ctx, channelName(channel), b).Err()
+		if err == nil {
+			break
+		}
+
+		if attempt == publishMaxAttempts {
+			l.Error("publish event with error", "channel", channel, "attempts", attempt, "error", err)
+			return fmt.Errorf("publish event with error after %d attempts: %w", attempt, err)
+		}
+
+		log.Printf("error on publisher %s, attempt %d: %v\n", channel, attempt, err)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("publish event canceled after %d attempts: %w", attempt, err)
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+	}
+
+	l.Debug("publish msg", "channel", channel, "msg", msg.data)
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/IsaacDSC/featureflag/pkg/telemetry"
+	"github.com/redis/go-redis/v9"
+)
+
+type RedisSubscriber struct {
+	rdb *redis.Client
+}
+
+func NewRedisSubscriber(rdb *redis.Client) RedisSubscriber {
+	return RedisSubscriber{
+		rdb: rdb,
+	}
+}
+
+// Listener subscribes to the channel and calls fn for every message until ctx is done.
+// It returns an error when the subscription fails or is closed by redis.
+func (s RedisSubscriber) Listener(ctx context.Context, channel string, fn Handler) error {
+	sub := s.rdb.Subscribe(ctx, channelName(channel))
+	defer sub.Close()
+
+	if _, err := sub.Receive(ctx); err != nil {
+		return fmt.Errorf("subscribe receive failed: %w", err)
+	}
+
+	ch := sub.Channel()
+
+	log.Printf("listening on channel %q", channel)
+	for {
+		select {
+		case msg := <-ch:
+			if msg == nil {
+				return errors.New("subscription closed")
+			}
+
+			id := newEventID()
+			spanCtx, span := startProcess(ctx, "redis", channel, id)
+			err := fn(WithEventID(spanCtx, id), []byte(msg.Payload))
+			telemetry.End(span, err)
+			if err != nil {
+				log.Printf("error processing channel %s: %v\n", channel, err)
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// LastID returns a fresh id, PUBLISH keeps no history to resume from
+func (s RedisSubscriber) LastID(ctx context.Context, channel string) (string, error) {
+	return newEventID(), nil
+}
@@ -0,0 +1,57 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/IsaacDSC/featureflag/pkg/ctxlog"
+	"github.com/redis/go-redis/v9"
+)
+
+const streamDataField = "data"
+
+// StreamPublisher writes events to a redis stream (XADD) instead of PUBLISH,
+// so subscribers that were offline can read what they missed while it is retained.
+type StreamPublisher struct {
+	rdb    *redis.Client
+	maxLen int64
+}
+
+func NewStreamPublisher(rdb *redis.Client, maxLen int64) StreamPublisher {
+	return StreamPublisher{
+		rdb:    rdb,
+		maxLen: maxLen,
+	}
+}
+
+// Publish adds the trace context of ctx to the fields of the entry, next to the data
+func (p StreamPublisher) Publish(ctx context.Context, channel string, msg Payload) (err error) {
+	ctx, span := startPublish(ctx, "redis", channel)
+	defer func() { endPublish(ctx, span, channel, err) }()
+
+	l := ctxlog.GetLogger(ctx)
+	b, err := json.Marshal(msg.data)
+	if err != nil {
+		l.Error("marshal payload", "error", err)
+		return fmt.Errorf("marshal payload: %v", err)
+	}
+
+	values := map[string]any{streamDataField: b}
+	injectFields(ctx, values)
+
+	id, err := p.rdb.XAdd(ctx, &redis.XAddArgs{
+		Stream: channelName(channel),
+		MaxLen: p.maxLen,
+		Approx: true,
+		Values: values,
+	}).Result()
+	if err != nil {
+		l.Error("publish event on stream with error", "channel", channel, "error", err)
+		return fmt.Errorf("publish event on stream with error: %v", err)
+	}
+
+	l.Debug("publish msg on stream", "channel", channel, "id", id, "msg", msg.data)
+
+	return nil
+}
@@ -0,0 +1,151 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/IsaacDSC/featureflag/pkg/telemetry"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	streamReadBlock = 5 * time.Second
+	streamReadCount = 100
+	streamFirstID   = "0-0"
+)
+
+// StreamSubscriber reads events from a redis stream (XREAD). Every message is delivered
+// with its stream id in the handler context (see EventID), and a listener started with
+// WithLastEventID replays the retained events after that id before following new ones,
+// so a listener restarted from the last id it delivered loses nothing.
+type StreamSubscriber struct {
+	rdb *redis.Client
+}
+
+func NewStreamSubscriber(rdb *redis.Client) StreamSubscriber {
+	return StreamSubscriber{
+		rdb: rdb,
+	}
+}
+
+func (s StreamSubscriber) Listener(ctx context.Context, channel string, fn Handler) error {
+	stream := channelName(channel)
+
+	lastID := LastEventID(ctx)
+	if lastID == "" {
+		id, err := s.lastStreamID(ctx, stream)
+		if err != nil {
+			return fmt.Errorf("error on read last id of stream %s: %w", stream, err)
+		}
+		lastID = id
+	}
+
+	log.Printf("listening on stream %q from id %s", channel, lastID)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+
+		streams, err := s.rdb.XRead(ctx, &redis.XReadArgs{
+			Streams: []string{stream, lastID},
+			Count:   streamReadCount,
+			Block:   streamReadBlock,
+		}).Result()
+		if err != nil {
+			if errors.Is(err, redis.Nil) {
+				continue
+			}
+
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return fmt.Errorf("error on read stream %s: %w", stream, err)
+		}
+
+		for _, st := range streams {
+			for _, msg := range st.Messages {
+				lastID = msg.ID
+
+				data, ok := msg.Values[streamDataField].(string)
+				if !ok {
+					log.Printf("message %s on stream %s without %s field\n", msg.ID, stream, streamDataField)
+					continue
+				}
+
+				spanCtx, span := startProcess(extractFields(ctx, msg.Values), "redis", channel, msg.ID)
+				err := fn(WithEventID(spanCtx, msg.ID), []byte(data))
+				telemetry.End(span, err)
+				if err != nil {
+					log.Printf("error processing channel %s: %v\n", channel, err)
+				}
+			}
+		}
+	}
+}
+
+func (s StreamSubscriber) LastID(ctx context.Context, channel string) (string, error) {
+	return s.lastStreamID(ctx, channelName(channel))
+}
+
+// CanReplay reports whether every event after lastEventID is still retained in the stream,
+// that is false when MAXLEN already trimmed entries newer than it.
+func (s StreamSubscriber) CanReplay(ctx context.Context, channel string, lastEventID string) (bool, error) {
+	msgs, err := s.rdb.XRangeN(ctx, channelName(channel), "-", "+", 1).Result()
+	if err != nil {
+		return false, err
+	}
+
+	if len(msgs) == 0 {
+		return false, nil
+	}
+
+	cmp, err := CompareEventID(lastEventID, msgs[0].ID)
+	if err != nil {
+		return false, nil
+	}
+
+	return cmp >= 0, nil
+}
+
+// Range returns the retained events after afterID up to untilID, with their stream ids
+func (s StreamSubscriber) Range(ctx context.Context, channel string, afterID, untilID string) ([]Entry, error) {
+	stream := channelName(channel)
+	msgs, err := s.rdb.XRange(ctx, stream, "("+afterID, untilID).Result()
+	if err != nil {
+		return nil, fmt.Errorf("error on read range of stream %s: %w", stream, err)
+	}
+
+	entries := make([]Entry, 0, len(msgs))
+	for _, msg := range msgs {
+		data, ok := msg.Values[streamDataField].(string)
+		if !ok {
+			log.Printf("message %s on stream %s without %s field\n", msg.ID, stream, streamDataField)
+			continue
+		}
+
+		entries = append(entries, Entry{ID: msg.ID, Msg: []byte(data)})
+	}
+
+	return entries, nil
+}
+
+// lastStreamID returns the id of the newest entry, so a fresh listener only receives new events
+// without the race of reading with "$" between two XREAD calls.
+func (s StreamSubscriber) lastStreamID(ctx context.Context, stream string) (string, error) {
+	msgs, err := s.rdb.XRevRangeN(ctx, stream, "+", "-", 1).Result()
+	if err != nil {
+		return "", err
+	}
+
+	if len(msgs) == 0 {
+		return streamFirstID, nil
+	}
+
+	return msgs[0].ID, nil
+}
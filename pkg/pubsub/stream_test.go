@@ -0,0 +1,244 @@
+package pubsub
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/IsaacDSC/featureflag/pkg/ctxlog"
+	"github.com/IsaacDSC/featureflag/pkg/telemetry"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// setupTestRedis retorna nil se o Redis não estiver disponível
+func setupTestRedis(t *testing.T) *redis.Client {
+	addr := os.Getenv("REDIS_ADDR")
+	if addr == "" {
+		addr = "localhost:6379"
+	}
+
+	rdb := redis.NewClient(&redis.Options{Addr: addr})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := rdb.Ping(ctx).Err(); err != nil {
+		rdb.Close()
+		t.Skip("Redis não disponível:", err)
+		return nil
+	}
+
+	return rdb
+}
+
+func TestStream_PublishAndReplay(t *testing.T) {
+	rdb := setupTestRedis(t)
+	if rdb == nil {
+		return
+	}
+	defer rdb.Close()
+
+	channel := "test_" + uuid.NewString()
+	defer rdb.Del(context.Background(), channelName(channel))
+
+	ctx := ctxlog.SetLogger(context.Background(), slog.Default())
+	pub := NewStreamPublisher(rdb, 100)
+	sub := NewStreamSubscriber(rdb)
+
+	for _, msg := range []string{"first", "second", "third"} {
+		if err := pub.Publish(ctx, channel, NewPayload(msg)); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+	}
+
+	received := make(chan string, 3)
+	ids := make(chan string, 3)
+	listenCtx, cancel := context.WithTimeout(WithLastEventID(context.Background(), streamFirstID), 10*time.Second)
+	defer cancel()
+
+	go sub.Listener(listenCtx, channel, func(ctx context.Context, msg Msg) error {
+		var value string
+		if err := msg.ToJson(&value); err != nil {
+			return err
+		}
+		ids <- EventID(ctx)
+		received <- value
+		return nil
+	})
+
+	var firstID string
+	for i, want := range []string{"first", "second", "third"} {
+		select {
+		case got := <-received:
+			id := <-ids
+			if got != want {
+				t.Errorf("Listener() received %q, want %q", got, want)
+			}
+			if id == "" {
+				t.Error("Listener() delivered event without id")
+			}
+			if i == 0 {
+				firstID = id
+			}
+		case <-listenCtx.Done():
+			t.Fatal("timeout waiting replayed events")
+		}
+	}
+	cancel()
+
+	// Reconectar a partir do primeiro id deve entregar apenas os eventos seguintes
+	resumeCtx, resumeCancel := context.WithTimeout(WithLastEventID(context.Background(), firstID), 10*time.Second)
+	defer resumeCancel()
+
+	resumed := make(chan string, 2)
+	go sub.Listener(resumeCtx, channel, func(ctx context.Context, msg Msg) error {
+		var value string
+		if err := msg.ToJson(&value); err != nil {
+			return err
+		}
+		resumed <- value
+		return nil
+	})
+
+	for _, want := range []string{"second", "third"} {
+		select {
+		case got := <-resumed:
+			if got != want {
+				t.Errorf("Listener() resumed %q, want %q", got, want)
+			}
+		case <-resumeCtx.Done():
+			t.Fatal("timeout waiting resumed events")
+		}
+	}
+}
+
+func TestStream_CanReplay(t *testing.T) {
+	rdb := setupTestRedis(t)
+	if rdb == nil {
+		return
+	}
+	defer rdb.Close()
+
+	channel := "test_" + uuid.NewString()
+	defer rdb.Del(context.Background(), channelName(channel))
+
+	ctx := ctxlog.SetLogger(context.Background(), slog.Default())
+	sub := NewStreamSubscriber(rdb)
+
+	if ok, err := sub.CanReplay(ctx, channel, "1-0"); err != nil || ok {
+		t.Errorf("CanReplay() on empty stream = %v, %v, want false", ok, err)
+	}
+
+	// MAXLEN exato para que o trim seja determinístico no teste
+	var ids []string
+	for i := 0; i < 5; i++ {
+		id, err := rdb.XAdd(ctx, &redis.XAddArgs{
+			Stream: channelName(channel),
+			MaxLen: 3,
+			Values: map[string]any{streamDataField: strconv.Itoa(i)},
+		}).Result()
+		if err != nil {
+			t.Fatalf("XAdd() error = %v", err)
+		}
+		ids = append(ids, id)
+	}
+
+	tests := []struct {
+		name        string
+		lastEventID string
+		want        bool
+	}{
+		{name: "trimmed id", lastEventID: ids[0], want: false},
+		{name: "oldest retained id", lastEventID: ids[2], want: true},
+		{name: "newest id", lastEventID: ids[4], want: true},
+		{name: "invalid id", lastEventID: "invalid", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sub.CanReplay(ctx, channel, tt.lastEventID)
+			if err != nil {
+				t.Fatalf("CanReplay() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CanReplay(%s) = %v, want %v", tt.lastEventID, got, tt.want)
+			}
+		})
+	}
+
+	entries, err := sub.Range(ctx, channel, ids[2], ids[3])
+	if err != nil || len(entries) != 1 || entries[0].ID != ids[3] || string(entries[0].Msg) != "3" {
+		t.Errorf("Range() = %v, %v, want only %s", entries, err, ids[3])
+	}
+
+	last, err := sub.LastID(ctx, channel)
+	if err != nil || last != ids[4] {
+		t.Errorf("LastID() = %v, %v, want %v", last, err, ids[4])
+	}
+}
+
+func TestCompareEventID(t *testing.T) {
+	tests := []struct {
+		a, b    string
+		want    int
+		wantErr bool
+	}{
+		{a: "1-0", b: "1-0", want: 0},
+		{a: "1-1", b: "1-0", want: 1},
+		{a: "1-9", b: "2-0", want: -1},
+		{a: "10-0", b: "9-0", want: 1},
+		{a: "invalid", b: "1-0", wantErr: true},
+		{a: "1-0", b: "1-x", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := CompareEventID(tt.a, tt.b)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("CompareEventID(%s, %s) = %d, %v, want %d", tt.a, tt.b, got, err, tt.want)
+		}
+	}
+}
+
+func TestStream_TraceContext(t *testing.T) {
+	rdb := setupTestRedis(t)
+	if rdb == nil {
+		return
+	}
+	defer rdb.Close()
+
+	memory := telemetry.NewInMemory()
+	memory.Install()
+
+	channel := "test_" + uuid.NewString()
+	defer rdb.Del(context.Background(), channelName(channel))
+
+	ctx := ctxlog.SetLogger(context.Background(), slog.Default())
+	ctx, span := memory.TracerProvider.Tracer("test").Start(ctx, "request")
+	if err := NewStreamPublisher(rdb, 100).Publish(ctx, channel, NewPayload("traced")); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	span.End()
+
+	listenCtx, cancel := context.WithTimeout(WithLastEventID(context.Background(), streamFirstID), 10*time.Second)
+	defer cancel()
+
+	traceIDs := make(chan trace.TraceID, 1)
+	go NewStreamSubscriber(rdb).Listener(listenCtx, channel, func(ctx context.Context, msg Msg) error {
+		traceIDs <- trace.SpanContextFromContext(ctx).TraceID()
+		return nil
+	})
+
+	select {
+	case got := <-traceIDs:
+		if want := span.SpanContext().TraceID(); got != want {
+			t.Errorf("handler trace id = %s, want the one of the publisher %s", got, want)
+		}
+	case <-listenCtx.Done():
+		t.Fatal("timeout waiting event")
+	}
+}
@@ -0,0 +1,62 @@
+package pubsub
+
+import (
+	"context"
+
+	"github.com/IsaacDSC/featureflag/pkg/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// startPublish starts the producer span of an event, system is the messaging.system of the backend
+func startPublish(ctx context.Context, system, channel string) (context.Context, trace.Span) {
+	return telemetry.Tracer().Start(ctx, "publish "+channel,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", system),
+			attribute.String("messaging.destination.name", channel),
+			attribute.String("messaging.operation.type", "publish"),
+		))
+}
+
+// startProcess starts the consumer span of an event, a child of the producer span when ctx carries it
+func startProcess(ctx context.Context, system, channel, id string) (context.Context, trace.Span) {
+	return telemetry.Tracer().Start(ctx, "process "+channel,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", system),
+			attribute.String("messaging.destination.name", channel),
+			attribute.String("messaging.operation.type", "process"),
+			attribute.String("messaging.message.id", id),
+		))
+}
+
+// injectFields adds the trace context of ctx to the fields of a stream entry
+func injectFields(ctx context.Context, values map[string]any) {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+
+	for key, value := range carrier {
+		values[key] = value
+	}
+}
+
+// extractFields returns ctx with the trace context of the fields of a stream entry
+func extractFields(ctx context.Context, values map[string]any) context.Context {
+	carrier := propagation.MapCarrier{}
+	for _, key := range otel.GetTextMapPropagator().Fields() {
+		if value, ok := values[key].(string); ok {
+			carrier[key] = value
+		}
+	}
+
+	return otel.GetTextMapPropagator().Extract(ctx, carrier)
+}
+
+// endPublish ends the producer span and counts the event as published or failed
+func endPublish(ctx context.Context, span trace.Span, channel string, err error) {
+	telemetry.RecordPublish(ctx, channel, err)
+	telemetry.End(span, err)
+}
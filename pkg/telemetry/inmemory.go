@@ -0,0 +1,104 @@
+package telemetry
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// InMemory keeps the spans and metrics in memory, for tests
+type InMemory struct {
+	TracerProvider *sdktrace.TracerProvider
+	MeterProvider  *sdkmetric.MeterProvider
+
+	spans  *tracetest.InMemoryExporter
+	reader *sdkmetric.ManualReader
+}
+
+func NewInMemory() *InMemory {
+	spans := tracetest.NewInMemoryExporter()
+	reader := sdkmetric.NewManualReader()
+
+	return &InMemory{
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSyncer(spans)),
+		MeterProvider:  sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)),
+		spans:          spans,
+		reader:         reader,
+	}
+}
+
+// Install makes m the global providers, with the propagators of Setup, and the source of
+// PrometheusHandler. The instruments created
+// before keep reporting to the first providers installed, so tests install it once per package.
+func (m *InMemory) Install() {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	otel.SetTracerProvider(m.TracerProvider)
+	otel.SetMeterProvider(m.MeterProvider)
+	prometheusCollector.reader.Store(m.reader)
+}
+
+// Spans returns the ended spans
+func (m *InMemory) Spans() tracetest.SpanStubs {
+	return m.spans.GetSpans()
+}
+
+// Reset discards the spans recorded
+func (m *InMemory) Reset() {
+	m.spans.Reset()
+}
+
+// Metrics collects the current value of the metrics
+func (m *InMemory) Metrics(ctx context.Context) (metricdata.ResourceMetrics, error) {
+	var rm metricdata.ResourceMetrics
+	err := m.reader.Collect(ctx, &rm)
+	return rm, err
+}
+
+// Sum returns the sum of the int64 counter or gauge name for the data points with every attribute of attrs
+func (m *InMemory) Sum(ctx context.Context, name string, attrs map[string]string) (int64, error) {
+	rm, err := m.Metrics(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, scope := range rm.ScopeMetrics {
+		for _, metric := range scope.Metrics {
+			if metric.Name != name {
+				continue
+			}
+
+			var points []metricdata.DataPoint[int64]
+			switch data := metric.Data.(type) {
+			case metricdata.Sum[int64]:
+				points = data.DataPoints
+			case metricdata.Gauge[int64]:
+				points = data.DataPoints
+			}
+
+			for _, point := range points {
+				if matches(point, attrs) {
+					total += point.Value
+				}
+			}
+		}
+	}
+
+	return total, nil
+}
+
+func matches(point metricdata.DataPoint[int64], attrs map[string]string) bool {
+	for key, want := range attrs {
+		if value, ok := point.Attributes.Value(attribute.Key(key)); !ok || value.AsString() != want {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,108 @@
+package telemetry
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+const (
+	VariantOn  = "on"
+	VariantOff = "off"
+)
+
+// the instruments of the global meter are bound to the first provider installed
+var meter = otel.Meter(Scope)
+
+var evaluations, _ = meter.Int64Counter("featureflag.evaluations",
+	metric.WithDescription("Feature flags evaluated by the server, by flag and variant"),
+	metric.WithUnit("{evaluation}"))
+
+// RecordEvaluation counts an evaluation of flag made by the server
+func RecordEvaluation(ctx context.Context, flag string, active bool) {
+	variant := VariantOff
+	if active {
+		variant = VariantOn
+	}
+
+	evaluations.Add(ctx, 1, metric.WithAttributes(attribute.String("flag", flag), attribute.String("variant", variant)))
+}
+
+// Meter returns the meter of the server, obtained before any provider is installed. The callbacks
+// must be registered on the meter of their instruments, so the server does not use otel.Meter directly.
+func Meter() metric.Meter {
+	return meter
+}
+
+// latencyBuckets are in seconds, from 1ms to 10s
+var latencyBuckets = []float64{0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+var requestDuration, _ = meter.Float64Histogram("http.server.request.duration",
+	metric.WithDescription("Duration of the HTTP requests, by route pattern and status code"),
+	metric.WithUnit("s"),
+	metric.WithExplicitBucketBoundaries(latencyBuckets...))
+
+// RecordRequest records a request of route, the pattern it was registered with (never the path,
+// which would make a series for every key)
+func RecordRequest(ctx context.Context, route string, status int, duration time.Duration) {
+	requestDuration.Record(ctx, duration.Seconds(), metric.WithAttributes(
+		attribute.String("route", route),
+		attribute.String("status", strconv.Itoa(status)),
+	))
+}
+
+var (
+	repositoryDuration, _ = meter.Float64Histogram("db.client.operation.duration",
+		metric.WithDescription("Duration of the repository operations, by backend, collection and operation"),
+		metric.WithUnit("s"),
+		metric.WithExplicitBucketBoundaries(latencyBuckets...))
+
+	repositoryErrors, _ = meter.Int64Counter("featureflag.repository.errors",
+		metric.WithDescription("Repository operations that returned an error, by backend, collection and operation"),
+		metric.WithUnit("{error}"))
+)
+
+var publishes, _ = meter.Int64Counter("featureflag.publishes",
+	metric.WithDescription("Events published, by channel and result"),
+	metric.WithUnit("{event}"))
+
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+)
+
+// RecordPublish counts an event published on channel, a failure when err is not nil
+func RecordPublish(ctx context.Context, channel string, err error) {
+	result := ResultSuccess
+	if err != nil {
+		result = ResultFailure
+	}
+
+	publishes.Add(ctx, 1, metric.WithAttributes(attribute.String("channel", channel), attribute.String("result", result)))
+}
+
+var entries, _ = meter.Int64ObservableGauge("featureflag.entries",
+	metric.WithDescription("Feature flags and contenthub entries stored, by resource"),
+	metric.WithUnit("{entry}"))
+
+// ObserveEntries reports the number of entries of every resource on each collection of the metrics,
+// the resources whose counter fails are not reported
+func ObserveEntries(counters map[string]func(ctx context.Context) (int, error)) (metric.Registration, error) {
+	return meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		for resource, count := range counters {
+			n, err := count(ctx)
+			if err != nil {
+				otel.Handle(err)
+				continue
+			}
+
+			o.ObserveInt64(entries, int64(n), metric.WithAttributes(attribute.String("resource", resource)))
+		}
+
+		return nil
+	}, entries)
+}
@@ -0,0 +1,154 @@
+package telemetry
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+var (
+	prometheusCollector = &collector{}
+	registry            = prometheus.NewRegistry()
+)
+
+func init() {
+	registry.MustRegister(
+		prometheusCollector,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// PrometheusHandler serves the metrics of the meter provider installed by Setup in the Prometheus
+// exposition format, with the go runtime and process metrics
+func PrometheusHandler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// collector converts the metrics of the reader on every scrape, as the Prometheus exporter
+// of OpenTelemetry does: "featureflag.evaluations" becomes featureflag_evaluations_total
+type collector struct {
+	reader atomic.Pointer[sdkmetric.ManualReader]
+}
+
+// Describe sends nothing, which makes the collector unchecked as the metrics are only known on Collect
+func (c *collector) Describe(ch chan<- *prometheus.Desc) {}
+
+func (c *collector) Collect(ch chan<- prometheus.Metric) {
+	reader := c.reader.Load()
+	if reader == nil {
+		return
+	}
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		otel.Handle(err)
+		return
+	}
+
+	for _, scope := range rm.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			name := prometheusName(m.Name, m.Unit)
+
+			switch data := m.Data.(type) {
+			case metricdata.Sum[int64]:
+				collectSum(ch, name, m.Description, data.IsMonotonic, data.DataPoints)
+			case metricdata.Sum[float64]:
+				collectSum(ch, name, m.Description, data.IsMonotonic, data.DataPoints)
+			case metricdata.Gauge[int64]:
+				collectPoints(ch, name, m.Description, prometheus.GaugeValue, data.DataPoints)
+			case metricdata.Gauge[float64]:
+				collectPoints(ch, name, m.Description, prometheus.GaugeValue, data.DataPoints)
+			case metricdata.Histogram[float64]:
+				collectHistogram(ch, name, m.Description, data.DataPoints)
+			}
+		}
+	}
+}
+
+func collectSum[N int64 | float64](ch chan<- prometheus.Metric, name, help string, monotonic bool, points []metricdata.DataPoint[N]) {
+	if !monotonic {
+		collectPoints(ch, name, help, prometheus.GaugeValue, points)
+		return
+	}
+
+	collectPoints(ch, name+"_total", help, prometheus.CounterValue, points)
+}
+
+func collectPoints[N int64 | float64](ch chan<- prometheus.Metric, name, help string, valueType prometheus.ValueType, points []metricdata.DataPoint[N]) {
+	for _, point := range points {
+		keys, values := labels(point.Attributes)
+		metric, err := prometheus.NewConstMetric(prometheus.NewDesc(name, help, keys, nil), valueType, float64(point.Value), values...)
+		if err != nil {
+			otel.Handle(err)
+			continue
+		}
+
+		ch <- metric
+	}
+}
+
+func collectHistogram(ch chan<- prometheus.Metric, name, help string, points []metricdata.HistogramDataPoint[float64]) {
+	for _, point := range points {
+		// the buckets of OpenTelemetry count their own values, the ones of Prometheus are cumulative
+		buckets := make(map[float64]uint64, len(point.Bounds))
+		var cumulative uint64
+		for i, bound := range point.Bounds {
+			cumulative += point.BucketCounts[i]
+			buckets[bound] = cumulative
+		}
+
+		keys, values := labels(point.Attributes)
+		metric, err := prometheus.NewConstHistogram(prometheus.NewDesc(name, help, keys, nil), point.Count, point.Sum, buckets, values...)
+		if err != nil {
+			otel.Handle(err)
+			continue
+		}
+
+		ch <- metric
+	}
+}
+
+func labels(attrs attribute.Set) ([]string, []string) {
+	keys := make([]string, 0, attrs.Len())
+	values := make([]string, 0, attrs.Len())
+
+	for _, attr := range attrs.ToSlice() {
+		keys = append(keys, sanitize(string(attr.Key)))
+		values = append(values, attr.Value.Emit())
+	}
+
+	return keys, values
+}
+
+// prometheusName adds the unit of seconds and bytes to the name, the units in braces are only annotations
+func prometheusName(name, unit string) string {
+	name = sanitize(name)
+
+	switch unit {
+	case "s":
+		return name + "_seconds"
+	case "By":
+		return name + "_bytes"
+	}
+
+	return name
+}
+
+func sanitize(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == ':' {
+			return r
+		}
+		return '_'
+	}, name)
+}
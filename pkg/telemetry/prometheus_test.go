@@ -0,0 +1,57 @@
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPrometheusHandler(t *testing.T) {
+	ctx := context.Background()
+
+	RecordEvaluation(ctx, "prometheus", true)
+	RecordRequest(ctx, "GET /featureflags/{key}", 200, 30*time.Millisecond)
+	RecordPublish(ctx, "featureflag", nil)
+	RecordPublish(ctx, "featureflag", errors.New("redis down"))
+
+	_, op := StartRepository(ctx, "mongodb", "featureflags", "GetFF")
+	op.End(ctx, errors.New("not found"))
+
+	registration, err := ObserveEntries(map[string]func(ctx context.Context) (int, error){
+		"featureflag": func(ctx context.Context) (int, error) { return 3, nil },
+		"contenthub":  func(ctx context.Context) (int, error) { return 0, errors.New("failed") },
+	})
+	if err != nil {
+		t.Fatalf("ObserveEntries() error = %v", err)
+	}
+	defer registration.Unregister()
+
+	rec := httptest.NewRecorder()
+	PrometheusHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+
+	for _, want := range []string{
+		`featureflag_evaluations_total{flag="prometheus",variant="on"} 1`,
+		`http_server_request_duration_seconds_bucket{route="GET /featureflags/{key}",status="200",le="0.05"} 1`,
+		`http_server_request_duration_seconds_bucket{route="GET /featureflags/{key}",status="200",le="0.025"} 0`,
+		`http_server_request_duration_seconds_count{route="GET /featureflags/{key}",status="200"} 1`,
+		`featureflag_publishes_total{channel="featureflag",result="success"} 1`,
+		`featureflag_publishes_total{channel="featureflag",result="failure"} 1`,
+		`db_client_operation_duration_seconds_count{db_collection_name="featureflags",db_operation_name="GetFF",db_system="mongodb"} 1`,
+		`featureflag_repository_errors_total{db_collection_name="featureflags",db_operation_name="GetFF",db_system="mongodb"} 1`,
+		`featureflag_entries{resource="featureflag"} 3`,
+		"go_goroutines",
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("metrics without %s:\n%s", want, body)
+		}
+	}
+
+	if strings.Contains(string(body), `resource="contenthub"`) {
+		t.Error("entries reported for a failed counter")
+	}
+}
@@ -0,0 +1,147 @@
+// Package telemetry configures the OpenTelemetry providers of the server and holds its shared instruments.
+//
+// The instrumented packages use the global providers (otel.Tracer, otel.Meter), which are no-ops until
+// Setup or InMemory.Install replaces them.
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/stdout/stdoutmetric"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Scope is the instrumentation scope of the server spans and metrics
+const Scope = "github.com/IsaacDSC/featureflag"
+
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+)
+
+// stdoutInterval is how often the metrics are written by the stdout exporter
+const stdoutInterval = 30 * time.Second
+
+type Config struct {
+	ServiceName string
+	// Exporter is ExporterNone (the metrics are only served by PrometheusHandler) or ExporterStdout
+	Exporter string
+	// Output is where ExporterStdout writes, os.Stdout when nil
+	Output io.Writer
+}
+
+// Setup installs the global providers and the W3C trace context and baggage propagators.
+// shutdown flushes the spans and metrics not exported yet.
+func Setup(ctx context.Context, cfg Config) (shutdown func(ctx context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	res := resource.NewSchemaless(attribute.String("service.name", cfg.ServiceName))
+	prometheusReader := sdkmetric.NewManualReader()
+	metricOpts := []sdkmetric.Option{sdkmetric.WithReader(prometheusReader), sdkmetric.WithResource(res)}
+
+	var tp *sdktrace.TracerProvider
+	switch cfg.Exporter {
+	case "", ExporterNone:
+	case ExporterStdout:
+		traceOpts := []stdouttrace.Option{}
+		stdoutOpts := []stdoutmetric.Option{}
+		if cfg.Output != nil {
+			traceOpts = append(traceOpts, stdouttrace.WithWriter(cfg.Output))
+			stdoutOpts = append(stdoutOpts, stdoutmetric.WithWriter(cfg.Output))
+		}
+
+		spanExporter, err := stdouttrace.New(traceOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("error on create span exporter: %w", err)
+		}
+
+		metricExporter, err := stdoutmetric.New(stdoutOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("error on create metric exporter: %w", err)
+		}
+
+		tp = sdktrace.NewTracerProvider(sdktrace.WithBatcher(spanExporter), sdktrace.WithResource(res))
+		metricOpts = append(metricOpts, sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter, sdkmetric.WithInterval(stdoutInterval))))
+	default:
+		return nil, fmt.Errorf("unknown telemetry exporter %q", cfg.Exporter)
+	}
+
+	mp := sdkmetric.NewMeterProvider(metricOpts...)
+	otel.SetMeterProvider(mp)
+	prometheusCollector.reader.Store(prometheusReader)
+
+	if tp == nil {
+		return mp.Shutdown, nil
+	}
+
+	otel.SetTracerProvider(tp)
+
+	return func(ctx context.Context) error {
+		return errors.Join(tp.Shutdown(ctx), mp.Shutdown(ctx))
+	}, nil
+}
+
+// Tracer returns the tracer of the server from the global provider
+func Tracer() trace.Tracer {
+	return otel.Tracer(Scope)
+}
+
+// Start starts a span of the server, a child of the span in ctx
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records err on span, when not nil, and ends it
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
+
+// Operation is a repository operation started by StartRepository
+type Operation struct {
+	span  trace.Span
+	start time.Time
+	attrs metric.MeasurementOption
+}
+
+// StartRepository starts a client span of operation on collection, backend is the db.system (mongodb, jsonfile)
+func StartRepository(ctx context.Context, backend, collection, operation string) (context.Context, Operation) {
+	attrs := []attribute.KeyValue{
+		attribute.String("db.system", backend),
+		attribute.String("db.collection.name", collection),
+		attribute.String("db.operation.name", operation),
+	}
+
+	ctx, span := Tracer().Start(ctx, collection+"."+operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...))
+
+	return ctx, Operation{span: span, start: time.Now(), attrs: metric.WithAttributes(attrs...)}
+}
+
+// End ends the span of the operation and records its duration, and err when not nil
+func (o Operation) End(ctx context.Context, err error) {
+	repositoryDuration.Record(ctx, time.Since(o.start).Seconds(), o.attrs)
+	if err != nil {
+		repositoryErrors.Add(ctx, 1, o.attrs)
+	}
+
+	End(o.span, err)
+}
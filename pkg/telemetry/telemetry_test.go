@@ -0,0 +1,85 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+)
+
+var memory = NewInMemory()
+
+func TestMain(m *testing.M) {
+	memory.Install()
+	os.Exit(m.Run())
+}
+
+func TestSetup_Stdout(t *testing.T) {
+	defer memory.Install()
+
+	var out bytes.Buffer
+	shutdown, err := Setup(context.Background(), Config{ServiceName: "test", Exporter: ExporterStdout, Output: &out})
+	if err != nil {
+		t.Fatalf("Setup() error = %v", err)
+	}
+
+	_, span := Start(context.Background(), "operation")
+	End(span, errors.New("failed"))
+
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown() error = %v", err)
+	}
+
+	for _, want := range []string{`"Name":"operation"`, `"Description":"failed"`, `"Value":"test"`} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output without %s: %s", want, out.String())
+		}
+	}
+}
+
+func TestSetup_Exporter(t *testing.T) {
+	defer memory.Install()
+
+	if _, err := Setup(context.Background(), Config{Exporter: "zipkin"}); err == nil {
+		t.Error("Setup() error = nil with an unknown exporter")
+	}
+
+	if _, err := Setup(context.Background(), Config{Exporter: ExporterNone}); err != nil {
+		t.Errorf("Setup() error = %v", err)
+	}
+
+	if fields := otel.GetTextMapPropagator().Fields(); !slices.Contains(fields, "traceparent") {
+		t.Errorf("propagator fields = %v, want traceparent", fields)
+	}
+}
+
+func TestRecordEvaluation(t *testing.T) {
+	ctx := context.Background()
+	RecordEvaluation(ctx, "checkout", true)
+	RecordEvaluation(ctx, "checkout", true)
+	RecordEvaluation(ctx, "checkout", false)
+
+	tests := []struct {
+		variant string
+		want    int64
+	}{
+		{variant: VariantOn, want: 2},
+		{variant: VariantOff, want: 1},
+	}
+
+	for _, tt := range tests {
+		got, err := memory.Sum(ctx, "featureflag.evaluations", map[string]string{"flag": "checkout", "variant": tt.variant})
+		if err != nil {
+			t.Fatalf("Sum() error = %v", err)
+		}
+
+		if got != tt.want {
+			t.Errorf("evaluations of %s = %d, want %d", tt.variant, got, tt.want)
+		}
+	}
+}
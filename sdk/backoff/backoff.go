@@ -0,0 +1,44 @@
+package backoff
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff is an exponential delay with jitter: attempt n waits Min*Multiplier^n, capped at Max,
+// randomized by up to Jitter (a fraction of the delay) in both directions
+type Backoff struct {
+	Min        time.Duration
+	Max        time.Duration
+	Multiplier float64
+	Jitter     float64
+}
+
+func Default() Backoff {
+	return Backoff{
+		Min:        500 * time.Millisecond,
+		Max:        30 * time.Second,
+		Multiplier: 2,
+		Jitter:     0.2,
+	}
+}
+
+// Delay returns the wait before the attempt, starting at 0
+func (b Backoff) Delay(attempt int) time.Duration {
+	multiplier := b.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	delay := float64(b.Min) * math.Pow(multiplier, float64(attempt))
+	if b.Max > 0 && delay > float64(b.Max) {
+		delay = float64(b.Max)
+	}
+
+	if b.Jitter > 0 {
+		delay += delay * b.Jitter * (2*rand.Float64() - 1)
+	}
+
+	return time.Duration(delay)
+}
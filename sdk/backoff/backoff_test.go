@@ -0,0 +1,46 @@
+package backoff
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoff_Delay(t *testing.T) {
+	tests := []struct {
+		name    string
+		backoff Backoff
+		attempt int
+		want    time.Duration
+	}{
+		{name: "first attempt", backoff: Backoff{Min: time.Second, Max: time.Minute, Multiplier: 2}, attempt: 0, want: time.Second},
+		{name: "exponential", backoff: Backoff{Min: time.Second, Max: time.Minute, Multiplier: 2}, attempt: 3, want: 8 * time.Second},
+		{name: "capped", backoff: Backoff{Min: time.Second, Max: time.Minute, Multiplier: 2}, attempt: 20, want: time.Minute},
+		{name: "constant without multiplier", backoff: Backoff{Min: time.Second, Max: time.Minute}, attempt: 5, want: time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.backoff.Delay(tt.attempt); got != tt.want {
+				t.Errorf("Delay(%d) = %v, want %v", tt.attempt, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackoff_Delay_Jitter(t *testing.T) {
+	backoff := Backoff{Min: time.Second, Max: time.Minute, Multiplier: 2, Jitter: 0.5}
+
+	varied := false
+	for i := 0; i < 100; i++ {
+		got := backoff.Delay(1)
+		if got < time.Second || got > 3*time.Second {
+			t.Fatalf("Delay(1) = %v, want between 1s and 3s", got)
+		}
+
+		varied = varied || got != 2*time.Second
+	}
+
+	if !varied {
+		t.Error("Delay() has no jitter")
+	}
+}
@@ -0,0 +1,149 @@
+package contenthub
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// WithCacheFile writes the contents to path after every change received from the server, and Start
+// serves them when the server is unavailable
+func (c *ContenthubSDK) WithCacheFile(path string) *ContenthubSDK {
+	c.cacheFile = path
+	return c
+}
+
+// WithBootstrapFile makes Start serve the contents of path, in the format of GET /contenthubs,
+// when the server is unavailable and there is no cache file
+func (c *ContenthubSDK) WithBootstrapFile(path string) *ContenthubSDK {
+	c.bootstrapFile = path
+	return c
+}
+
+// WithBootstrap is WithBootstrapFile for a snapshot embedded in the binary, as with //go:embed.
+// It is used after the cache file and the bootstrap file.
+func (c *ContenthubSDK) WithBootstrap(snapshot []byte) *ContenthubSDK {
+	c.bootstrap = snapshot
+	return c
+}
+
+// Cached reports whether the contents come from the cache file or the bootstrap, it is false
+// once they are loaded from the server
+func (c *ContenthubSDK) Cached() bool {
+	return c.cached.Load()
+}
+
+// SyncedAt returns when the contents were last received from the server, by a load or an event
+// of the stream. It is zero while only the offline contents were loaded.
+func (c *ContenthubSDK) SyncedAt() time.Time {
+	if nanos := c.syncedAt.Load(); nanos != 0 {
+		return time.Unix(0, nanos)
+	}
+
+	return time.Time{}
+}
+
+func (c *ContenthubSDK) markSynced() {
+	c.syncedAt.Store(time.Now().UnixNano())
+}
+
+// loadOffline returns the contents of the first offline source available
+func (c *ContenthubSDK) loadOffline() (map[string]Content, string, error) {
+	var errs []error
+	for _, path := range []string{c.cacheFile, c.bootstrapFile} {
+		if path == "" {
+			continue
+		}
+
+		b, err := os.ReadFile(path)
+		if err == nil {
+			var contents map[string]Content
+			if contents, err = decodeContents(b); err == nil {
+				return contents, path, nil
+			}
+		}
+
+		errs = append(errs, fmt.Errorf("error on load %s: %w", path, err))
+	}
+
+	if c.bootstrap != nil {
+		contents, err := decodeContents(c.bootstrap)
+		if err == nil {
+			return contents, "embedded bootstrap", nil
+		}
+
+		errs = append(errs, fmt.Errorf("error on load embedded bootstrap: %w", err))
+	}
+
+	if len(errs) == 0 {
+		return nil, "", errors.New("no cache file or bootstrap configured")
+	}
+
+	return nil, "", errors.Join(errs...)
+}
+
+func decodeContents(b []byte) (map[string]Content, error) {
+	var contents []Content
+	if err := json.Unmarshal(b, &contents); err != nil {
+		return nil, fmt.Errorf("error on decode json: %w", err)
+	}
+
+	output := make(map[string]Content)
+	for _, content := range contents {
+		output[content.Key] = content
+	}
+
+	return output, nil
+}
+
+// persist writes the contents to the cache file, only the contents received from the server are written
+func (c *ContenthubSDK) persist() {
+	if c.cacheFile == "" || c.Cached() {
+		return
+	}
+
+	c.mu.RLock()
+	contents := make([]Content, 0, len(c.db))
+	for _, content := range c.db {
+		contents = append(contents, content)
+	}
+	c.mu.RUnlock()
+
+	sort.Slice(contents, func(i, j int) bool { return contents[i].Key < contents[j].Key })
+
+	c.fileMu.Lock()
+	defer c.fileMu.Unlock()
+
+	if err := writeFile(c.cacheFile, contents); err != nil {
+		c.logger.Error("error on write contenthub cache file", "path", c.cacheFile, "error", err)
+	}
+}
+
+// writeFile replaces path atomically, a crash never leaves a partial cache
+func writeFile(path string, value any) error {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("error on encode json: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,90 @@
+package contenthub
+
+import "reflect"
+
+// change is a content added (old is empty), updated or removed on the server (new is empty)
+type change struct {
+	old, new Content
+}
+
+// OnChange registers fn to be called when the content key is added, changed or removed on the server.
+// The callbacks run in order on a goroutine of the sdk, never for updates that change nothing,
+// and a slow callback delays the next ones without blocking the stream.
+func (c *ContenthubSDK) OnChange(key string, fn func(old, new Content)) {
+	c.changeMu.Lock()
+	defer c.changeMu.Unlock()
+
+	if c.onChange == nil {
+		c.onChange = make(map[string][]func(old, new Content))
+	}
+	c.onChange[key] = append(c.onChange[key], fn)
+}
+
+// OnAnyChange is OnChange for every content, the key is in old.Key when the content is removed
+func (c *ContenthubSDK) OnAnyChange(fn func(old, new Content)) {
+	c.changeMu.Lock()
+	defer c.changeMu.Unlock()
+
+	c.onAnyChange = append(c.onAnyChange, fn)
+}
+
+func (c *ContenthubSDK) notify(changes []change) {
+	c.changeMu.RLock()
+	defer c.changeMu.RUnlock()
+
+	for _, ch := range changes {
+		key := ch.new.Key
+		if key == "" {
+			key = ch.old.Key
+		}
+
+		for _, fns := range [][]func(old, new Content){c.onChange[key], c.onAnyChange} {
+			for _, fn := range fns {
+				c.changes.Push(func() { fn(ch.old, ch.new) })
+			}
+		}
+	}
+}
+
+// diff returns the contents added, changed and removed from old to new
+func diff(old, new map[string]Content) []change {
+	var changes []change
+	for key, content := range new {
+		if oldContent, ok := old[key]; !ok || hasChanged(oldContent, content) {
+			changes = append(changes, change{old: oldContent, new: content})
+		}
+	}
+
+	for key, content := range old {
+		if _, ok := new[key]; !ok {
+			changes = append(changes, change{old: content})
+		}
+	}
+
+	return changes
+}
+
+// hasChanged compares the fields set on the server, the balancer counters are local
+func hasChanged(oldContent, newContent Content) bool {
+	if oldContent.Description != newContent.Description {
+		return true
+	}
+
+	if !reflect.DeepEqual(oldContent.SessionStrategy, newContent.SessionStrategy) {
+		return true
+	}
+
+	if len(oldContent.BalancerStrategy) != len(newContent.BalancerStrategy) {
+		return true
+	}
+
+	for i, strategy := range newContent.BalancerStrategy {
+		oldStrategy := oldContent.BalancerStrategy[i]
+		oldStrategy.Qtt, strategy.Qtt = 0, 0
+		if !reflect.DeepEqual(oldStrategy, strategy) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,219 @@
+// Package chtest is an in-memory contenthub.Evaluator for the tests of the applications that use the SDK,
+// Get and the typed getters of contenthub included.
+//
+//	contents := chtest.New().Set("banner", "Welcome").SetVariant("banner", "user-1", "Welcome back")
+//	title, _ := contenthub.String(contents, "banner", ctx, "")
+package chtest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	internal "github.com/IsaacDSC/featureflag/internal/contenthub"
+	"github.com/IsaacDSC/featureflag/sdk/contenthub"
+	"github.com/IsaacDSC/featureflag/sdk/evalctx"
+)
+
+// Evaluation is an evaluation made on the Fake
+type Evaluation struct {
+	Key     string
+	Context contenthub.EvalContext
+	Value   contenthub.Value
+	Err     error
+}
+
+type entry struct {
+	value    contenthub.Value
+	variants map[string]contenthub.Value
+}
+
+// Fake evaluates the contents set by the test: the variant of the targeting key when there is one,
+// otherwise the value of the content. The values are encoded to JSON, as the server sends them.
+// Unlike the SDK, the change callbacks run before Set, SetVariant and Delete return.
+type Fake struct {
+	mu          sync.Mutex
+	contents    map[string]entry
+	evaluations []Evaluation
+
+	changeMu    sync.Mutex
+	onChange    map[string][]func(old, new contenthub.Content)
+	onAnyChange []func(old, new contenthub.Content)
+}
+
+var _ contenthub.Evaluator = (*Fake)(nil)
+
+func New() *Fake {
+	return &Fake{
+		contents: make(map[string]entry),
+		onChange: make(map[string][]func(old, new contenthub.Content)),
+	}
+}
+
+// Set adds or changes the content key, as a change received from the server. It panics when value
+// can't be encoded to JSON.
+func (f *Fake) Set(key string, value any) *Fake {
+	raw := encode(key, value)
+
+	f.mu.Lock()
+	old, existed := f.contents[key]
+	e := entry{value: raw, variants: old.variants}
+	f.contents[key] = e
+	f.mu.Unlock()
+
+	f.changed(key, old.content(key, existed), e.content(key, true))
+	return f
+}
+
+// SetVariant makes key return value for the evaluations with targetingKey, the content is added
+// (null for the other targeting keys) when it does not exist
+func (f *Fake) SetVariant(key, targetingKey string, value any) *Fake {
+	raw := encode(key, value)
+
+	f.mu.Lock()
+	old, existed := f.contents[key]
+	e := entry{value: old.value, variants: make(map[string]contenthub.Value, len(old.variants)+1)}
+	if !existed {
+		e.value = contenthub.Value("null")
+	}
+	for tk, v := range old.variants {
+		e.variants[tk] = v
+	}
+	e.variants[targetingKey] = raw
+	f.contents[key] = e
+	f.mu.Unlock()
+
+	f.changed(key, old.content(key, existed), e.content(key, true))
+	return f
+}
+
+// Delete removes the content key and its variants, as a content removed on the server
+func (f *Fake) Delete(key string) *Fake {
+	f.mu.Lock()
+	old, ok := f.contents[key]
+	delete(f.contents, key)
+	f.mu.Unlock()
+
+	if ok {
+		f.changed(key, old.content(key, true), contenthub.Content{})
+	}
+	return f
+}
+
+func encode(key string, value any) contenthub.Value {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		panic(fmt.Sprintf("chtest: error on encode %s: %v", key, err))
+	}
+
+	return raw
+}
+
+// content is the Content given to the change callbacks, with the value in a balancer of weight 100
+// and the variants in the session strategy
+func (e entry) content(key string, exists bool) contenthub.Content {
+	if !exists {
+		return contenthub.Content{}
+	}
+
+	sessions := internal.SessionsStrategies{{SessionID: "default", Response: json.RawMessage(e.value)}}
+	for tk, v := range e.variants {
+		sessions = append(sessions, internal.SessionStrategy{SessionID: tk, Response: json.RawMessage(v)})
+	}
+
+	return contenthub.Content{
+		Key:              key,
+		SessionStrategy:  sessions,
+		BalancerStrategy: internal.BalancerStrategy{{Weight: 100, Response: json.RawMessage(e.value)}},
+	}
+}
+
+func (f *Fake) Evaluate(key string, ec contenthub.EvalContext) contenthub.Result {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	value, err := f.evaluate(key, ec)
+	f.evaluations = append(f.evaluations, Evaluation{Key: key, Context: ec, Value: value, Err: err})
+
+	return contenthub.NewResult(value, err)
+}
+
+func (f *Fake) evaluate(key string, ec contenthub.EvalContext) (contenthub.Value, error) {
+	e, ok := f.contents[key]
+	if !ok {
+		return nil, contenthub.ErrNotFoundContenthub
+	}
+
+	if value, ok := e.variants[ec.TargetingKey]; ok && ec.TargetingKey != "" {
+		return value, nil
+	}
+
+	return e.value, nil
+}
+
+// EvaluateContext is Evaluate with the EvalContext carried by ctx
+func (f *Fake) EvaluateContext(ctx context.Context, key string) contenthub.Result {
+	return f.Evaluate(key, evalctx.FromContext(ctx))
+}
+
+// Content is Evaluate with sessionID as the targeting key
+func (f *Fake) Content(key string, sessionID ...string) contenthub.Result {
+	if len(sessionID) > 0 {
+		return f.Evaluate(key, evalctx.New(sessionID[0]))
+	}
+
+	return f.Evaluate(key, contenthub.EvalContext{})
+}
+
+func (f *Fake) OnChange(key string, fn func(old, new contenthub.Content)) {
+	f.changeMu.Lock()
+	defer f.changeMu.Unlock()
+
+	f.onChange[key] = append(f.onChange[key], fn)
+}
+
+func (f *Fake) OnAnyChange(fn func(old, new contenthub.Content)) {
+	f.changeMu.Lock()
+	defer f.changeMu.Unlock()
+
+	f.onAnyChange = append(f.onAnyChange, fn)
+}
+
+func (f *Fake) changed(key string, old, new contenthub.Content) {
+	f.changeMu.Lock()
+	fns := append(append([]func(old, new contenthub.Content){}, f.onChange[key]...), f.onAnyChange...)
+	f.changeMu.Unlock()
+
+	for _, fn := range fns {
+		fn(old, new)
+	}
+}
+
+// Evaluations returns the evaluations made, in order
+func (f *Fake) Evaluations() []Evaluation {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]Evaluation(nil), f.evaluations...)
+}
+
+// Evaluated returns the contexts key was evaluated with, in order
+func (f *Fake) Evaluated(key string) []contenthub.EvalContext {
+	var contexts []contenthub.EvalContext
+	for _, e := range f.Evaluations() {
+		if e.Key == key {
+			contexts = append(contexts, e.Context)
+		}
+	}
+
+	return contexts
+}
+
+// Reset forgets the evaluations made, the contents and the callbacks are kept
+func (f *Fake) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.evaluations = nil
+}
@@ -0,0 +1,75 @@
+package chtest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/IsaacDSC/featureflag/sdk/contenthub"
+	"github.com/IsaacDSC/featureflag/sdk/evalctx"
+)
+
+type banner struct {
+	Title string `json:"title"`
+}
+
+func TestFake_Get(t *testing.T) {
+	fake := New().
+		Set("banner", banner{Title: "Welcome"}).
+		SetVariant("banner", "user-1", banner{Title: "Welcome back"}).
+		Set("limit", 10)
+
+	ctx := context.Background()
+	user := evalctx.NewContext(ctx, evalctx.New("user-1"))
+
+	if got, err := contenthub.Get(fake, "banner", ctx, banner{}); err != nil || got.Title != "Welcome" {
+		t.Errorf("Get() = %+v, %v, want Welcome", got, err)
+	}
+
+	if got, err := contenthub.Get(fake, "banner", user, banner{}); err != nil || got.Title != "Welcome back" {
+		t.Errorf("Get() for user-1 = %+v, %v, want Welcome back", got, err)
+	}
+
+	if got, err := contenthub.Int(fake, "limit", ctx, 5); err != nil || got != 10 {
+		t.Errorf("Int() = %d, %v, want 10", got, err)
+	}
+
+	if got, err := contenthub.String(fake, "limit", ctx, "fallback"); !errors.Is(err, contenthub.ErrTypeMismatch) || got != "fallback" {
+		t.Errorf("String() = %q, %v, want fallback with ErrTypeMismatch", got, err)
+	}
+
+	if _, err := fake.Content("missing").Err(); !errors.Is(err, contenthub.ErrNotFoundContenthub) {
+		t.Errorf("Content() error = %v, want ErrNotFoundContenthub", err)
+	}
+
+	contexts := fake.Evaluated("banner")
+	if len(contexts) != 2 || contexts[0].TargetingKey != "" || contexts[1].TargetingKey != "user-1" {
+		t.Errorf("Evaluated() = %+v", contexts)
+	}
+}
+
+func TestFake_OnChange(t *testing.T) {
+	fake := New()
+
+	var values []string
+	fake.OnChange("banner", func(old, new contenthub.Content) {
+		values = append(values, string(new.Value()))
+	})
+
+	var removed string
+	fake.OnAnyChange(func(old, new contenthub.Content) {
+		if new.Key == "" {
+			removed = old.Key
+		}
+	})
+
+	fake.Set("banner", "Welcome").Set("banner", "Hello").Delete("banner")
+
+	if len(values) != 3 || values[0] != `"Welcome"` || values[1] != `"Hello"` || values[2] != "null" {
+		t.Errorf("OnChange values = %v", values)
+	}
+
+	if removed != "banner" {
+		t.Errorf("removed = %q, want banner", removed)
+	}
+}
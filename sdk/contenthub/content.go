@@ -17,8 +17,9 @@ type Content struct {
 	BalancerStrategy contenthub.BalancerStrategy   `json:"balancer_strategy"`
 }
 
-func (ff Content) Value() Value {
-	value := ff.BalancerStrategy.Distribution()
+// Value is the response of the balancer, translated to the first of the locales found
+func (ff Content) Value(locales ...string) Value {
+	value := ff.BalancerStrategy.Distribution(locales...)
 	b, _ := json.Marshal(value)
 	return b
 }
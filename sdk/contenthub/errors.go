@@ -4,3 +4,8 @@ import "errors"
 
 var ErrInvalidStrategy = errors.New("contenthub with strategy required sessionID")
 var ErrNotFoundContenthub = errors.New("not found contenthub")
+var ErrAlreadyStarted = errors.New("contenthub sdk already started")
+var ErrTypeMismatch = errors.New("contenthub value type mismatch")
+
+// errNotModified is returned when the contents loaded are still the ones of the server
+var errNotModified = errors.New("contents not modified")
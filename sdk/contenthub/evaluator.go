@@ -0,0 +1,20 @@
+package contenthub
+
+import "context"
+
+// Evaluator is what the applications use of ContenthubSDK, Get and the typed getters included.
+// Depending on it instead of the SDK lets their tests use chtest.Fake, without a server.
+type Evaluator interface {
+	Content(key string, sessionID ...string) Result
+	Evaluate(key string, ec EvalContext) Result
+	EvaluateContext(ctx context.Context, key string) Result
+	OnChange(key string, fn func(old, new Content))
+	OnAnyChange(fn func(old, new Content))
+}
+
+var _ Evaluator = (*ContenthubSDK)(nil)
+
+// NewResult is the result of an evaluation, for the implementations of Evaluator
+func NewResult(value Value, err error) Result {
+	return Result{value: value, error: err}
+}
@@ -0,0 +1,152 @@
+package contenthub
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/IsaacDSC/featureflag/sdk/evalctx"
+)
+
+// decodedValues are the values decoded from one version of a content, by type and raw value
+// as the balancer and the session strategy give different values for the same version
+type decodedValues struct {
+	version uint64
+	values  map[decodedKey]any
+}
+
+type decodedKey struct {
+	typ reflect.Type
+	raw string
+}
+
+// Get evaluates key for the EvalContext of ctx and decodes the value into T. It returns fallback with
+// ErrNotFoundContenthub when the content does not exist, and with ErrTypeMismatch when the value is
+// null or can't be decoded into T. With ContenthubSDK the decoded values are cached until the content
+// changes, so values with slices, maps or pointers are shared by the calls and must not be modified.
+func Get[T any](sdk Evaluator, key string, ctx context.Context, fallback T) (T, error) {
+	c, ok := sdk.(*ContenthubSDK)
+	if !ok {
+		raw, err := sdk.EvaluateContext(ctx, key).Err()
+		if err != nil {
+			return fallback, err
+		}
+
+		return decode(key, raw, fallback)
+	}
+
+	raw, version, err := c.hooked(ctx, key, evalctx.FromContext(ctx))
+	if err != nil {
+		return fallback, err
+	}
+
+	cacheKey := decodedKey{typ: reflect.TypeFor[T](), raw: string(raw)}
+	if value, ok := c.cachedValue(key, version, cacheKey); ok {
+		return value.(T), nil
+	}
+
+	value, err := decode(key, raw, fallback)
+	if err != nil {
+		return fallback, err
+	}
+
+	c.cacheValue(key, version, cacheKey, value)
+	return value, nil
+}
+
+func decode[T any](key string, raw Value, fallback T) (T, error) {
+	typ := reflect.TypeFor[T]()
+	if bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+		return fallback, fmt.Errorf("%w: %s is null, want %s", ErrTypeMismatch, key, typ)
+	}
+
+	var value T
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return fallback, fmt.Errorf("%w: %s is %s, want %s: %v", ErrTypeMismatch, key, raw, typ, err)
+	}
+
+	return value, nil
+}
+
+func String(sdk Evaluator, key string, ctx context.Context, fallback string) (string, error) {
+	return Get(sdk, key, ctx, fallback)
+}
+
+func Int(sdk Evaluator, key string, ctx context.Context, fallback int) (int, error) {
+	return Get(sdk, key, ctx, fallback)
+}
+
+func Float(sdk Evaluator, key string, ctx context.Context, fallback float64) (float64, error) {
+	return Get(sdk, key, ctx, fallback)
+}
+
+func Bool(sdk Evaluator, key string, ctx context.Context, fallback bool) (bool, error) {
+	return Get(sdk, key, ctx, fallback)
+}
+
+// Duration decodes strings as time.ParseDuration ("1m30s") and numbers as nanoseconds
+func Duration(sdk Evaluator, key string, ctx context.Context, fallback time.Duration) (time.Duration, error) {
+	value, err := Get(sdk, key, ctx, duration(fallback))
+	return time.Duration(value), err
+}
+
+// Time decodes RFC 3339 strings
+func Time(sdk Evaluator, key string, ctx context.Context, fallback time.Time) (time.Time, error) {
+	return Get(sdk, key, ctx, fallback)
+}
+
+type duration time.Duration
+
+func (d *duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		var n int64
+		if err := json.Unmarshal(b, &n); err != nil {
+			return fmt.Errorf("invalid duration %s", b)
+		}
+
+		*d = duration(n)
+		return nil
+	}
+
+	value, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+
+	*d = duration(value)
+	return nil
+}
+
+func (c *ContenthubSDK) cachedValue(key string, version uint64, cacheKey decodedKey) (any, bool) {
+	c.decodeMu.Lock()
+	defer c.decodeMu.Unlock()
+
+	decoded, ok := c.decoded[key]
+	if !ok || decoded.version != version {
+		return nil, false
+	}
+
+	value, ok := decoded.values[cacheKey]
+	return value, ok
+}
+
+func (c *ContenthubSDK) cacheValue(key string, version uint64, cacheKey decodedKey, value any) {
+	c.decodeMu.Lock()
+	defer c.decodeMu.Unlock()
+
+	if c.decoded == nil {
+		c.decoded = make(map[string]decodedValues)
+	}
+
+	decoded, ok := c.decoded[key]
+	if !ok || decoded.version != version {
+		decoded = decodedValues{version: version, values: make(map[decodedKey]any)}
+		c.decoded[key] = decoded
+	}
+
+	decoded.values[cacheKey] = value
+}
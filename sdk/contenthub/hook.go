@@ -0,0 +1,49 @@
+package contenthub
+
+import (
+	"context"
+	"time"
+)
+
+// Evaluation is given to the hooks after every evaluation, including the ones of Get
+type Evaluation struct {
+	Key     string
+	Context EvalContext
+	Value   Value
+	// Err is ErrNotFoundContenthub when the content does not exist, then Value is the default
+	Err      error
+	Start    time.Time
+	Duration time.Duration
+}
+
+// WithEvaluationHook registers fn to be called after every evaluation, with the ctx of EvaluateContext
+// and Get or context.Background. fn is called by the goroutine that evaluated, so it must be fast; see
+// sdk/otelhook for hooks that record spans and metrics.
+func (c *ContenthubSDK) WithEvaluationHook(fn func(ctx context.Context, e Evaluation)) *ContenthubSDK {
+	c.hooks = append(c.hooks, fn)
+	return c
+}
+
+func (c *ContenthubSDK) hooked(ctx context.Context, key string, ec EvalContext) (Value, uint64, error) {
+	if len(c.hooks) == 0 {
+		return c.evaluate(key, ec)
+	}
+
+	start := time.Now()
+	value, version, err := c.evaluate(key, ec)
+
+	e := Evaluation{
+		Key:      key,
+		Context:  ec,
+		Value:    value,
+		Err:      err,
+		Start:    start,
+		Duration: time.Since(start),
+	}
+
+	for _, hook := range c.hooks {
+		hook(ctx, e)
+	}
+
+	return value, version, err
+}
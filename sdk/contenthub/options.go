@@ -0,0 +1,66 @@
+package contenthub
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/IsaacDSC/featureflag/sdk/backoff"
+)
+
+// Option configures the requests of the sdk to the server, including the streams
+type Option func(c *ContenthubSDK)
+
+// WithToken sends token as the Authorization header, required when the server has an SDK token
+func WithToken(token string) Option {
+	return func(c *ContenthubSDK) {
+		c.http.Token = token
+	}
+}
+
+// WithHTTPClient replaces the client used for every request, its Timeout must be zero
+// as it would also close the streams, use WithTimeout instead
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *ContenthubSDK) {
+		c.client = client
+	}
+}
+
+// WithRoundTripper sends the requests through rt, e.g. a proxy or an instrumented transport
+func WithRoundTripper(rt http.RoundTripper) Option {
+	return func(c *ContenthubSDK) {
+		c.client = &http.Client{Transport: rt}
+	}
+}
+
+// WithTimeout bounds each attempt of the requests and the websocket handshake, zero disables it
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *ContenthubSDK) {
+		c.http.Timeout = timeout
+	}
+}
+
+// WithRetry retries the requests up to retries times on network errors, 429 and 5xx, waiting b between them.
+// The streams are not retried, they reconnect with WithReconnectBackoff
+func WithRetry(retries int, b backoff.Backoff) Option {
+	return func(c *ContenthubSDK) {
+		c.http.Retries = retries
+		c.http.Backoff = b
+	}
+}
+
+// WithHeader adds a header to every request
+func WithHeader(key, value string) Option {
+	return func(c *ContenthubSDK) {
+		if c.http.Header == nil {
+			c.http.Header = http.Header{}
+		}
+		c.http.Header.Add(key, value)
+	}
+}
+
+// WithUserAgent prefixes the User-Agent, which always ends with the sdk version
+func WithUserAgent(userAgent string) Option {
+	return func(c *ContenthubSDK) {
+		c.http.UserAgent = userAgent
+	}
+}
@@ -1,146 +1,363 @@
 package contenthub
 
 import (
-	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
-	"os"
-	"os/signal"
-	"strings"
-	"syscall"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/IsaacDSC/featureflag/sdk/backoff"
+	"github.com/IsaacDSC/featureflag/sdk/evalctx"
+	"github.com/IsaacDSC/featureflag/sdk/httpclient"
+	"github.com/IsaacDSC/featureflag/sdk/notify"
+	"github.com/IsaacDSC/featureflag/sdk/sse"
+	"github.com/IsaacDSC/featureflag/sdk/ws"
 )
 
 type ContenthubSDK struct {
 	host      string
 	client    *http.Client
+	http      httpclient.Config
 	ffDefault Value
 
-	sleeper time.Duration
-	db      map[string]Content
+	sleeper     time.Duration
+	mu          sync.RWMutex
+	db          map[string]Content
+	versions    map[string]uint64
+	etag        string
+	lastEventID string
+	transport   Transport
+	logger      *slog.Logger
+	backoff     backoff.Backoff
+
+	cacheFile     string
+	bootstrapFile string
+	bootstrap     []byte
+	cached        atomic.Bool
+	syncedAt      atomic.Int64
+	fileMu        sync.Mutex
+
+	changeMu    sync.RWMutex
+	onChange    map[string][]func(old, new Content)
+	onAnyChange []func(old, new Content)
+	changes     *notify.Queue
+
+	decodeMu sync.Mutex
+	decoded  map[string]decodedValues
+
+	stateMu sync.Mutex
+	state   State
+	onState func(state State)
+
+	hooks []func(ctx context.Context, e Evaluation)
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+const (
+	snapshotEvent = "snapshot"
+	// deletedEvent has the key of a content deleted on the server
+	deletedEvent = "deleted"
+)
+
+// deleted is the data of deletedEvent
+type deleted struct {
+	Key string `json:"key"`
 }
 
-func NewContenthubSDK(hostFF string) *ContenthubSDK {
-	sdk := &ContenthubSDK{client: &http.Client{}, host: hostFF, sleeper: time.Second * 60}
+// EvalContext is who the contents are evaluated for, built with evalctx.New
+type EvalContext = evalctx.EvalContext
+
+type Transport string
+
+const (
+	TransportSSE       Transport = "sse"
+	TransportWebSocket Transport = "websocket"
+)
+
+// NewContenthubSDK accepts Options for the requests to the server, sent with httpclient.Default otherwise
+func NewContenthubSDK(hostFF string, opts ...Option) *ContenthubSDK {
+	sdk := &ContenthubSDK{client: &http.Client{}, host: hostFF, http: httpclient.Default(), sleeper: time.Second * 60, logger: slog.Default(), backoff: backoff.Default(), changes: notify.NewQueue()}
+	for _, opt := range opts {
+		opt(sdk)
+	}
+
 	return sdk
 }
 
+// WithLogger replaces slog.Default as the logger of the stream and refresh
+func (c *ContenthubSDK) WithLogger(logger *slog.Logger) *ContenthubSDK {
+	c.logger = logger
+	return c
+}
+
+// WithTransport chooses how updates are received, TransportSSE (default) or TransportWebSocket
+// for environments where proxies buffer or break server-sent events
+func (c *ContenthubSDK) WithTransport(transport Transport) *ContenthubSDK {
+	c.transport = transport
+	return c
+}
+
 func (c *ContenthubSDK) WithEventualConsistency(time time.Duration) *ContenthubSDK {
 	c.sleeper = time
 	return c
 }
 
-func (c *ContenthubSDK) Listenner(ctx context.Context) (*ContenthubSDK, error) {
+// Start loads the contents and returns, the stream and the refresh keep them updated in background
+// until Close. ctx only bounds the initial load. When the server is unavailable it serves the
+// cache file or the bootstrap, and the contents are loaded from the server once it is back.
+func (c *ContenthubSDK) Start(ctx context.Context) error {
+	if c.cancel != nil {
+		return ErrAlreadyStarted
+	}
+
 	contents, err := c.getAllContents(ctx)
 	if err != nil {
-		return nil, err
-	}
+		offline, source, offlineErr := c.loadOffline()
+		if offlineErr != nil {
+			return errors.Join(err, offlineErr)
+		}
 
-	c.db = contents
+		c.logger.Warn("contenthub server unavailable, serving offline contents", "source", source, "error", err)
+		contents = offline
+		c.cached.Store(true)
+	}
 
-	// Configurar context com cancelamento
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	c.replace(contents)
+	c.persist()
 
-	// Capturar sinais de interrupção
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	ctx, c.cancel = context.WithCancel(context.WithoutCancel(ctx))
 
+	c.wg.Add(3)
 	go func() {
-		<-sigChan
-		fmt.Println("\n🛑 Encerrando cliente...")
-		cancel()
+		defer c.wg.Done()
+		c.changes.Run(ctx)
 	}()
 
-	// Iniciar refresh em background
-	go c.refresh(ctx)
+	go func() {
+		defer c.wg.Done()
+		c.refresh(ctx)
+	}()
 
-	//FICAR ESCUTANDO EVENTOS (SSE)
-	// Cliente com timeout apenas para verificar se o servidor está rodando
-	clientWithTimeout := &http.Client{Timeout: 5 * time.Second}
-	_, err = clientWithTimeout.Get(c.host)
-	if err != nil {
-		fmt.Println("❌ Servidor não está rodando!")
-		fmt.Println("💡 Inicie o servidor primeiro: go run ./cmd/server")
-		return nil, err
-	}
+	go func() {
+		defer c.wg.Done()
+		c.listen(ctx)
+	}()
 
-	// Cliente sem timeout para a conexão SSE (que precisa ficar aberta)
-	sseClient := &http.Client{}
+	return nil
+}
 
-	serverUrl := fmt.Sprintf("%s/events/contenthub", c.host)
-	req, err := http.NewRequestWithContext(ctx, "GET", serverUrl, nil)
-	if err != nil {
-		log.Fatal("Erro ao criar request:", err)
+// Close stops the stream and the refresh, the contents already loaded are still served
+func (c *ContenthubSDK) Close() {
+	if c.cancel == nil {
+		return
 	}
 
-	req.Header.Set("Accept", "text/event-stream")
-	req.Header.Set("Cache-Control", "no-cache")
+	c.cancel()
+	c.wg.Wait()
+	c.setState(StateClosed)
+}
 
-	resp, err := sseClient.Do(req)
-	if err != nil {
-		log.Fatal("Erro ao conectar:", err)
+// Deprecated: use Start and Close. Listenner starts the sdk and blocks until ctx is done.
+func (c *ContenthubSDK) Listenner(ctx context.Context) (*ContenthubSDK, error) {
+	if err := c.Start(ctx); err != nil {
+		return nil, err
 	}
 
-	defer resp.Body.Close()
+	<-ctx.Done()
+	c.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		log.Fatalf("Status inválido: %d", resp.StatusCode)
-	}
+	return c, nil
+}
 
-	scanner := bufio.NewScanner(resp.Body)
-	done := make(chan bool)
-	go func() {
-		defer func() { done <- true }()
+// listen keeps the stream connected, reconnecting with backoff when it ends. Events may be lost
+// while disconnected, so the contents are resynced before every reconnection, and before the first
+// connection when they were loaded offline.
+func (c *ContenthubSDK) listen(ctx context.Context) {
+	attempt := 0
+	resynced := !c.Cached()
+
+	var err error
+	for {
+		for !resynced {
+			c.setState(StatePollingFallback)
+			delay := c.backoff.Delay(attempt)
+			attempt++
+			c.logger.Warn("contenthub stream disconnected, reconnecting", "in", delay, "error", err)
 
-		for scanner.Scan() {
 			select {
 			case <-ctx.Done():
 				return
-			default:
+			case <-time.After(delay):
 			}
 
-			line := strings.TrimSpace(scanner.Text())
+			c.setState(StateConnecting)
+			err = c.resync(ctx)
+			resynced = err == nil
+		}
 
-			if data, ok := strings.CutPrefix(line, "data: "); ok {
+		c.setState(StateConnecting)
+		err = c.stream(ctx)
+		if ctx.Err() != nil {
+			return
+		}
 
-				var content Content
-				if err := json.Unmarshal([]byte(data), &content); err == nil {
-					fmt.Printf("📦 Feature Content recebida:\n")
-					fmt.Println()
-					fmt.Printf("%+v\n", content)
-					fmt.Println()
+		// the stream was up, the next reconnection starts from the minimum delay
+		if c.State() == StateStreaming {
+			attempt = 0
+		}
 
-					c.db[content.Key] = content
-					for _, v := range c.db {
-						fmt.Printf("%+v\n", v)
-					}
-					continue
-				}
+		resynced = false
+	}
+}
 
-				// Se não conseguir parsear, mostrar raw data
-				fmt.Printf("📨 Mensagem recebida:\n")
-				fmt.Printf("   %s\n", data)
+// stream connects with the configured transport and applies the events until the connection ends.
+// When an event was already received it sends Last-Event-ID, so the server replays
+// what was missed or answers with a snapshot.
+func (c *ContenthubSDK) stream(ctx context.Context) error {
+	reader, err := c.connect(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer reader.Close()
+	c.setState(StateStreaming)
+
+	for {
+		event, err := reader.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) || ctx.Err() != nil {
+				return nil
 			}
+			return err
 		}
 
-		if err := scanner.Err(); err != nil {
-			fmt.Printf("❌ Erro ao ler stream: %v\n", err)
+		if err := c.apply(event); err != nil {
+			c.logger.Warn("skipping invalid contenthub event", "id", event.ID, "data", event.Data, "error", err)
+			continue
 		}
-	}()
 
-	select {
-	case <-ctx.Done():
-		fmt.Println("🔌 Conexão cancelada pelo usuário")
-	case <-done:
-		fmt.Println("🔌 Conexão encerrada")
+		c.lastEventID = event.ID
 	}
+}
 
-	return c, nil
+// apply updates the in-memory contents with a single content event, a delete or a full snapshot
+func (c *ContenthubSDK) apply(event sse.Event) error {
+	c.markSynced()
+
+	if event.Event == snapshotEvent {
+		var contents []Content
+		if err := json.Unmarshal([]byte(event.Data), &contents); err != nil {
+			return err
+		}
+
+		db := make(map[string]Content)
+		for _, content := range contents {
+			db[content.Key] = content
+		}
+
+		c.synced(c.replace(db))
+		c.logger.Info("contenthub snapshot received", "contents", len(db))
+		return nil
+	}
+
+	if event.Event == deletedEvent {
+		var d deleted
+		if err := json.Unmarshal([]byte(event.Data), &d); err != nil {
+			return err
+		}
+
+		c.logger.Debug("contenthub deleted", "key", d.Key)
+
+		c.mu.Lock()
+		old, ok := c.db[d.Key]
+		if ok {
+			delete(c.db, d.Key)
+			c.bump(d.Key)
+		}
+		c.mu.Unlock()
+
+		if ok {
+			c.synced([]change{{old: old}})
+		}
+		return nil
+	}
+
+	var content Content
+	if err := json.Unmarshal([]byte(event.Data), &content); err != nil {
+		return err
+	}
+
+	c.logger.Debug("contenthub received", "key", content.Key)
+
+	c.mu.Lock()
+	if c.db == nil {
+		c.db = make(map[string]Content)
+	}
+	old, ok := c.db[content.Key]
+	c.db[content.Key] = content
+
+	// the server also sends the contents saved without changes
+	changed := !ok || hasChanged(old, content)
+	if changed {
+		c.bump(content.Key)
+	}
+	c.mu.Unlock()
+
+	if !changed {
+		return nil
+	}
+
+	c.synced([]change{{old: old, new: content}})
+	return nil
+}
+
+type eventReader interface {
+	Next() (sse.Event, error)
+	Close() error
+}
+
+func (c *ContenthubSDK) connect(ctx context.Context) (eventReader, error) {
+	header := c.http.Headers()
+	if c.lastEventID != "" {
+		header.Set("Last-Event-ID", c.lastEventID)
+	}
+
+	if c.transport == TransportWebSocket {
+		return ws.Dial(ctx, httpclient.Dialer(c.client, c.http), c.host, "contenthub", header)
+	}
+
+	serverUrl := fmt.Sprintf("%s/events/contenthub", c.host)
+	header.Set("Accept", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+
+	resp, err := httpclient.Stream(ctx, c.client, c.http, serverUrl, header)
+	if err != nil {
+		return nil, fmt.Errorf("error on connect: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("invalid status: %d", resp.StatusCode)
+	}
+
+	return sseReader{Reader: sse.NewReader(resp.Body), body: resp.Body}, nil
+}
+
+type sseReader struct {
+	*sse.Reader
+	body io.Closer
+}
+
+func (r sseReader) Close() error {
+	return r.body.Close()
 }
 
 type Result struct {
@@ -164,32 +381,118 @@ func (fr Result) DecodeJson(value any) error {
 	return json.Unmarshal(fr.value, value)
 }
 
+// replace swaps the whole cache with the contents loaded from the server and returns what changed
+func (c *ContenthubSDK) replace(db map[string]Content) []change {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	changes := diff(c.db, db)
+	c.db = db
+
+	for _, ch := range changes {
+		c.bump(ch.new.Key)
+		c.bump(ch.old.Key)
+	}
+
+	return changes
+}
+
+// bump changes the version of key, the values decoded from the previous one are discarded.
+// It is called with the write lock held.
+func (c *ContenthubSDK) bump(key string) {
+	if key == "" {
+		return
+	}
+
+	if c.versions == nil {
+		c.versions = make(map[string]uint64)
+	}
+	c.versions[key]++
+}
+
+// synced is called after every change of the contents received from the server
+func (c *ContenthubSDK) synced(changes []change) {
+	c.persist()
+	c.notify(changes)
+}
+
+// Content evaluates key for the sessionID, it is Evaluate with sessionID as the targeting key
 func (c *ContenthubSDK) Content(key string, sessionID ...string) Result {
+	if len(sessionID) > 0 {
+		return c.Evaluate(key, evalctx.New(sessionID[0]))
+	}
+
+	return c.Evaluate(key, EvalContext{})
+}
+
+// EvaluateContext is Evaluate with the EvalContext carried by ctx, see evalctx.NewContext.
+// The hooks receive ctx, so they can record the evaluation in the span of the caller.
+func (c *ContenthubSDK) EvaluateContext(ctx context.Context, key string) Result {
+	value, _, err := c.hooked(ctx, key, evalctx.FromContext(ctx))
+	return Result{value, err}
+}
+
+// Evaluate is safe for concurrent use, the balancer is evaluated under the write lock.
+// The session strategy uses the targeting key as the sessionID, without it the balancer is used.
+// The response is translated to the locale of ec, see evalctx.EvalContext.WithLocale.
+func (c *ContenthubSDK) Evaluate(key string, ec EvalContext) Result {
+	value, _, err := c.hooked(context.Background(), key, ec)
+	return Result{value, err}
+}
+
+// evaluate returns the value with the version of the content it came from
+func (c *ContenthubSDK) evaluate(key string, ec EvalContext) (Value, uint64, error) {
+	c.mu.RLock()
 	content, ok := c.db[key]
+	version := c.versions[key]
+	c.mu.RUnlock()
 
 	if !ok {
-		return Result{c.ffDefault, ErrNotFoundContenthub}
+		return c.ffDefault, 0, ErrNotFoundContenthub
 	}
 
-	if len(sessionID) == 0 {
-		return Result{content.Value(), nil}
+	var locales []string
+	if locale := ec.Locale(); locale != "" {
+		locales = append(locales, locale)
 	}
 
-	ch := content.SessionStrategy.Val(sessionID[0])
-	b, _ := json.Marshal(ch)
+	if ec.TargetingKey == "" {
+		// the balancer counts the responses in the cached strategy
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return content.Value(locales...), version, nil
+	}
 
-	return Result{b, nil}
+	ch := content.SessionStrategy.Val(ec.TargetingKey, locales...)
+	b, _ := json.Marshal(ch)
 
+	return b, version, nil
 }
 
+// getAllContents sends the ETag of the last contents loaded, and returns errNotModified when the
+// server has no changes since them
 func (c *ContenthubSDK) getAllContents(ctx context.Context) (map[string]Content, error) {
-	resp, err := http.Get(fmt.Sprintf("%s/contenthubs", c.host))
+	header := make(http.Header)
+	if etag := c.getETag(); etag != "" && !c.Cached() {
+		header.Set("If-None-Match", etag)
+	}
+
+	resp, err := httpclient.GetWithHeader(ctx, c.client, c.http, fmt.Sprintf("%s/contenthubs", c.host), header)
 	if err != nil {
 		return nil, fmt.Errorf("error on get features Contents :%w", err)
 	}
 
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified {
+		c.markSynced()
+		return nil, errNotModified
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("invalid status: %d", resp.StatusCode)
+	}
+
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("error on io read all, %w", err)
@@ -200,6 +503,9 @@ func (c *ContenthubSDK) getAllContents(ctx context.Context) (map[string]Content,
 		return nil, fmt.Errorf("error on json unmarshal, %w", err)
 	}
 
+	c.markSynced()
+	c.setETag(resp.Header.Get("ETag"))
+
 	output := make(map[string]Content)
 	for _, content := range contents {
 		output[content.Key] = content
@@ -212,22 +518,47 @@ func (c *ContenthubSDK) refresh(ctx context.Context) {
 	ticker := time.NewTicker(c.sleeper)
 	defer ticker.Stop()
 
-	fmt.Println("🔄 Refresh iniciado - atualizando contents a cada 60 segundos")
-
 	for {
 		select {
 		case <-ctx.Done():
-			fmt.Println("🛑 Refresh encerrado")
 			return
 		case <-ticker.C:
-			contents, err := c.getAllContents(ctx)
-			if err != nil {
-				fmt.Printf("error on refresh contents: %v\n", err)
-				continue
+			if err := c.resync(ctx); err != nil && ctx.Err() == nil {
+				c.logger.Error("error on refresh contents", "error", err)
 			}
-
-			c.db = contents
-			fmt.Println("✅ Contents atualizados via refresh")
 		}
 	}
 }
+
+// resync replaces the contents with the ones loaded from the server
+func (c *ContenthubSDK) resync(ctx context.Context) error {
+	contents, err := c.getAllContents(ctx)
+	if errors.Is(err, errNotModified) {
+		c.logger.Debug("contents not modified since the last resync")
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	c.cached.Store(false)
+	c.synced(c.replace(contents))
+	c.logger.Debug("contents updated by resync", "contents", len(contents))
+
+	return nil
+}
+
+func (c *ContenthubSDK) getETag() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.etag
+}
+
+func (c *ContenthubSDK) setETag(etag string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.etag = etag
+}
@@ -3,12 +3,21 @@ package contenthub
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/IsaacDSC/featureflag/internal/contenthub"
+	"github.com/IsaacDSC/featureflag/sdk/backoff"
+	"github.com/IsaacDSC/featureflag/sdk/evalctx"
+	"github.com/IsaacDSC/featureflag/sdk/httpclient"
+	"github.com/IsaacDSC/featureflag/sdk/sse"
 )
 
 func TestContenthubSDK_Content(t *testing.T) {
@@ -527,3 +536,589 @@ func TestContenthubSDK_Integration(t *testing.T) {
 		t.Errorf("Content() with non-existent key error = %v, want %v", err4, ErrNotFoundContenthub)
 	}
 }
+
+func TestContenthubSDK_stream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/events/contenthub" {
+			t.Errorf("Expected path /events/contenthub, got %s", r.URL.Path)
+		}
+
+		if got := r.Header.Get("Last-Event-ID"); got != "" {
+			t.Errorf("Last-Event-ID = %q, want empty on first connection", got)
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("id: 5-0\nevent: snapshot\ndata: [{\"key\":\"banner\",\"balancer_strategy\":[{\"weight\":100,\"response\":\"a\"}]}]\n\n"))
+		w.Write([]byte("id: 6-0\ndata: {\"key\":\"title\",\"balancer_strategy\":[{\"weight\":100,\"response\":\"b\"}]}\n\n"))
+	}))
+	defer server.Close()
+
+	sdk := NewContenthubSDK(server.URL)
+	sdk.db = map[string]Content{"removed": {Key: "removed"}}
+
+	if err := sdk.stream(context.Background()); err != nil {
+		t.Fatalf("stream() error = %v", err)
+	}
+
+	if sdk.lastEventID != "6-0" {
+		t.Errorf("lastEventID = %q, want %q", sdk.lastEventID, "6-0")
+	}
+
+	if _, ok := sdk.db["removed"]; ok {
+		t.Error("content removed on server should be removed by snapshot")
+	}
+
+	if got := sdk.Content("title").String(); got != `"b"` {
+		t.Errorf("Content(title) = %s, want %s", got, `"b"`)
+	}
+}
+
+func TestContenthubSDK_StartClose(t *testing.T) {
+	events := make(chan string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/contenthubs":
+			w.Write([]byte(`[{"key":"banner","balancer_strategy":[{"weight":100,"response":"a"}]}]`))
+		case "/events/contenthub":
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.(http.Flusher).Flush()
+			for {
+				select {
+				case data := <-events:
+					fmt.Fprintf(w, "data: %s\n\n", data)
+					w.(http.Flusher).Flush()
+				case <-r.Context().Done():
+					return
+				}
+			}
+		}
+	}))
+	defer server.Close()
+
+	sdk := NewContenthubSDK(server.URL).WithEventualConsistency(time.Millisecond)
+	if err := sdk.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	if err := sdk.Start(context.Background()); err != ErrAlreadyStarted {
+		t.Errorf("second Start() error = %v, want %v", err, ErrAlreadyStarted)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				sdk.Content("banner")
+				sdk.Content("title", "session")
+			}
+		}()
+	}
+
+	for i := 0; i < 10; i++ {
+		events <- fmt.Sprintf(`{"key":"title","balancer_strategy":[{"weight":100,"response":"%d"}]}`, i)
+	}
+	wg.Wait()
+
+	done := make(chan struct{})
+	go func() {
+		sdk.Close()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Close() did not stop the stream")
+	}
+
+	if got := sdk.Content("banner").String(); got != `"a"` {
+		t.Errorf("Content(banner) = %s after Close, want %s", got, `"a"`)
+	}
+}
+
+func TestContenthubSDK_Reconnect(t *testing.T) {
+	var loads, connections atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/contenthubs":
+			// the content changes while the stream is down
+			fmt.Fprintf(w, `[{"key":"banner","balancer_strategy":[{"weight":100,"response":"%d"}]}]`, loads.Add(1))
+		case "/events/contenthub":
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.(http.Flusher).Flush()
+			if connections.Add(1) == 1 {
+				return
+			}
+			<-r.Context().Done()
+		}
+	}))
+	defer server.Close()
+
+	streaming := make(chan struct{}, 2)
+	sdk := NewContenthubSDK(server.URL).
+		WithReconnectBackoff(backoff.Backoff{Min: 10 * time.Millisecond, Max: 10 * time.Millisecond}).
+		WithStateHandler(func(state State) {
+			if state == StateStreaming {
+				streaming <- struct{}{}
+			}
+		})
+
+	if err := sdk.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer sdk.Close()
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-streaming:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("stream not connected %d times", i+1)
+		}
+	}
+
+	if got := sdk.Content("banner").String(); got != `"2"` {
+		t.Errorf("Content(banner) = %s, want the resynced value %s", got, `"2"`)
+	}
+}
+
+func TestContenthubSDK_Start_Offline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/contenthubs":
+			w.Write([]byte(`[{"key":"banner","balancer_strategy":[{"weight":100,"response":"live"}]}]`))
+		case "/events/contenthub":
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}
+	}))
+	defer server.Close()
+
+	cacheFile := filepath.Join(t.TempDir(), "cache.json")
+
+	live := NewContenthubSDK(server.URL).WithCacheFile(cacheFile)
+	if err := live.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	live.Close()
+
+	if live.Cached() {
+		t.Error("Cached() = true with the server up")
+	}
+
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	offline := NewContenthubSDK(down.URL).
+		WithCacheFile(cacheFile).
+		WithBootstrap([]byte(`[{"key":"banner","balancer_strategy":[{"weight":100,"response":"bootstrap"}]}]`))
+	if err := offline.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer offline.Close()
+
+	if !offline.Cached() {
+		t.Error("Cached() = false with the server down")
+	}
+
+	if got := offline.Content("banner").String(); got != `"live"` {
+		t.Errorf("Content(banner) = %s, want the cached %s", got, `"live"`)
+	}
+}
+
+func TestContenthubSDK_OnChange(t *testing.T) {
+	events := make(chan string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/contenthubs":
+			w.Write([]byte(`[{"key":"banner","balancer_strategy":[{"weight":100,"response":"a"}]}]`))
+		case "/events/contenthub":
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.(http.Flusher).Flush()
+			for {
+				select {
+				case data := <-events:
+					fmt.Fprintf(w, "data: %s\n\n", data)
+					w.(http.Flusher).Flush()
+				case <-r.Context().Done():
+					return
+				}
+			}
+		}
+	}))
+	defer server.Close()
+
+	sdk := NewContenthubSDK(server.URL)
+
+	changed := make(chan [2]Content, 10)
+	sdk.OnChange("banner", func(old, new Content) {
+		changed <- [2]Content{old, new}
+	})
+
+	if err := sdk.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer sdk.Close()
+
+	// the balancer counters are local, evaluating does not change the content
+	sdk.Content("banner")
+
+	events <- `{"key":"banner","balancer_strategy":[{"weight":100,"response":"a"}]}`
+	events <- `{"key":"banner","balancer_strategy":[{"weight":100,"response":"b"}]}`
+
+	select {
+	case got := <-changed:
+		if old, new := got[0].BalancerStrategy[0].Response, got[1].BalancerStrategy[0].Response; old != "a" || new != "b" {
+			t.Errorf("OnChange(banner) old = %v, new = %v, want a -> b", old, new)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting OnChange(banner)")
+	}
+
+	select {
+	case got := <-changed:
+		t.Errorf("OnChange(banner) called again with %+v", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestContenthubSDK_OnChange_Locales(t *testing.T) {
+	sdk := NewContenthubSDK("http://localhost:8080")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go sdk.changes.Run(ctx)
+
+	if err := sdk.apply(sse.Event{Data: `{"key":"banner","balancer_strategy":[{"weight":100,"response":"buy","locales":{"pt":"comprar"}}]}`}); err != nil {
+		t.Fatalf("apply() error = %v", err)
+	}
+
+	var calls []map[string]any
+	sdk.OnAnyChange(func(old, new Content) {
+		calls = append(calls, map[string]any{"old": old.BalancerStrategy[0].Locales, "new": new.BalancerStrategy[0].Locales})
+	})
+
+	// the counter of the balancer is local, only the translation changed
+	sdk.Content("banner")
+	if err := sdk.apply(sse.Event{Data: `{"key":"banner","balancer_strategy":[{"weight":100,"response":"buy","locales":{"pt":"compre"}}]}`}); err != nil {
+		t.Fatalf("apply() error = %v", err)
+	}
+
+	sdk.changes.Push(cancel)
+	<-ctx.Done()
+
+	if len(calls) != 1 {
+		t.Fatalf("OnAnyChange() called %d times for a change of the locales, want 1", len(calls))
+	}
+
+	if got := fmt.Sprint(calls[0]["new"]); got != "map[pt:compre]" {
+		t.Errorf("OnAnyChange() new locales = %s, want map[pt:compre]", got)
+	}
+
+	if got := sdk.Evaluate("banner", evalctx.New("").WithLocale("pt")).String(); got != `"compre"` {
+		t.Errorf("Evaluate() with locale pt = %s, want %s", got, `"compre"`)
+	}
+}
+
+func TestContenthubSDK_OnChange_Deleted(t *testing.T) {
+	sdk := NewContenthubSDK("http://localhost:8080")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go sdk.changes.Run(ctx)
+
+	if err := sdk.apply(sse.Event{Data: `{"key":"banner","balancer_strategy":[{"weight":100,"response":"a"}]}`}); err != nil {
+		t.Fatalf("apply() error = %v", err)
+	}
+
+	var calls []string
+	sdk.OnAnyChange(func(old, new Content) {
+		calls = append(calls, old.Key+"->"+new.Key)
+	})
+
+	if err := sdk.apply(sse.Event{Event: deletedEvent, Data: `{"key":"banner","revision":2}`}); err != nil {
+		t.Fatalf("apply() error = %v", err)
+	}
+	// a content unknown to the sdk is ignored
+	if err := sdk.apply(sse.Event{Event: deletedEvent, Data: `{"key":"other","revision":3}`}); err != nil {
+		t.Fatalf("apply() error = %v", err)
+	}
+
+	sdk.changes.Push(cancel)
+	<-ctx.Done()
+
+	if _, err := sdk.Content("banner").Err(); !errors.Is(err, ErrNotFoundContenthub) {
+		t.Errorf("Content(banner) after its delete error = %v, want ErrNotFoundContenthub", err)
+	}
+
+	if len(calls) != 1 || calls[0] != "banner->" {
+		t.Errorf("OnAnyChange() calls = %v, want [banner->]", calls)
+	}
+}
+
+func TestContenthubSDK_EvaluateContext(t *testing.T) {
+	sdk := NewContenthubSDK("http://localhost:8080")
+	sdk.db = map[string]Content{
+		"banner": {
+			Key:              "banner",
+			BalancerStrategy: contenthub.BalancerStrategy{{Weight: 100, Response: "balanced"}},
+			SessionStrategy: contenthub.SessionsStrategies{
+				{SessionID: "user-1", Response: "user"},
+				{SessionID: "default", Response: "default"},
+			},
+		},
+	}
+
+	ctx := evalctx.NewContext(context.Background(), evalctx.New("user-1"))
+	if got := sdk.EvaluateContext(ctx, "banner").String(); got != `"user"` {
+		t.Errorf("EvaluateContext() with targeting key = %s, want %s", got, `"user"`)
+	}
+
+	if got := sdk.EvaluateContext(context.Background(), "banner").String(); got != `"balanced"` {
+		t.Errorf("EvaluateContext() without targeting key = %s, want %s", got, `"balanced"`)
+	}
+
+	if got := sdk.Evaluate("banner", evalctx.New("user-2")).String(); got != `"default"` {
+		t.Errorf("Evaluate() with unknown targeting key = %s, want %s", got, `"default"`)
+	}
+}
+
+func TestContenthubSDK_Evaluate_Locale(t *testing.T) {
+	sdk := NewContenthubSDK("http://localhost:8080")
+	sdk.db = map[string]Content{
+		"banner": {
+			Key:              "banner",
+			BalancerStrategy: contenthub.BalancerStrategy{{Weight: 100, Response: "buy", Locales: contenthub.Locales{"pt": "comprar"}}},
+			SessionStrategy: contenthub.SessionsStrategies{
+				{SessionID: "default", Response: "welcome", Locales: contenthub.Locales{"es-ES": "bienvenido"}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name string
+		ec   EvalContext
+		want string
+	}{
+		{name: "balancer falls back to the language", ec: evalctx.EvalContext{}.WithLocale("pt-BR"), want: `"comprar"`},
+		{name: "balancer without translation", ec: evalctx.EvalContext{}.WithLocale("en-US"), want: `"buy"`},
+		{name: "session strategy", ec: evalctx.New("user-1").WithLocale("es-ES"), want: `"bienvenido"`},
+		{name: "without locale", ec: evalctx.New("user-1"), want: `"welcome"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sdk.Evaluate("banner", tt.ec).String(); got != tt.want {
+				t.Errorf("Evaluate() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGet(t *testing.T) {
+	content := func(key string, response any) Content {
+		return Content{Key: key, BalancerStrategy: contenthub.BalancerStrategy{{Weight: 100, Response: response}}}
+	}
+
+	sdk := NewContenthubSDK("http://localhost:8080")
+	sdk.db = map[string]Content{
+		"title":    content("title", "Welcome"),
+		"limit":    content("limit", 10),
+		"ratio":    content("ratio", 0.5),
+		"enabled":  content("enabled", true),
+		"timeout":  content("timeout", "1m30s"),
+		"deadline": content("deadline", "2025-01-02T03:04:05Z"),
+		"empty":    content("empty", nil),
+	}
+	ctx := context.Background()
+
+	if got, err := String(sdk, "title", ctx, ""); err != nil || got != "Welcome" {
+		t.Errorf("String() = %q, %v, want Welcome", got, err)
+	}
+
+	if got, err := Int(sdk, "limit", ctx, 0); err != nil || got != 10 {
+		t.Errorf("Int() = %d, %v, want 10", got, err)
+	}
+
+	if got, err := Float(sdk, "ratio", ctx, 0); err != nil || got != 0.5 {
+		t.Errorf("Float() = %v, %v, want 0.5", got, err)
+	}
+
+	if got, err := Bool(sdk, "enabled", ctx, false); err != nil || !got {
+		t.Errorf("Bool() = %v, %v, want true", got, err)
+	}
+
+	if got, err := Duration(sdk, "timeout", ctx, 0); err != nil || got != 90*time.Second {
+		t.Errorf("Duration() = %v, %v, want 1m30s", got, err)
+	}
+
+	if got, err := Time(sdk, "deadline", ctx, time.Time{}); err != nil || !got.Equal(time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("Time() = %v, %v, want 2025-01-02T03:04:05Z", got, err)
+	}
+
+	type limits struct {
+		Max int `json:"max"`
+	}
+	sdk.db["limits"] = content("limits", map[string]any{"max": 3})
+	if got, err := Get(sdk, "limits", ctx, limits{}); err != nil || got.Max != 3 {
+		t.Errorf("Get[limits]() = %+v, %v, want max 3", got, err)
+	}
+
+	mismatches := []struct {
+		name string
+		get  func() error
+	}{
+		{name: "string as int", get: func() error { _, err := Int(sdk, "title", ctx, 7); return err }},
+		{name: "float as int", get: func() error { _, err := Int(sdk, "ratio", ctx, 7); return err }},
+		{name: "number as bool", get: func() error { _, err := Bool(sdk, "limit", ctx, true); return err }},
+		{name: "invalid duration", get: func() error { _, err := Duration(sdk, "title", ctx, time.Second); return err }},
+		{name: "null as string", get: func() error { _, err := String(sdk, "empty", ctx, "fallback"); return err }},
+	}
+
+	for _, tt := range mismatches {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.get(); !errors.Is(err, ErrTypeMismatch) {
+				t.Errorf("error = %v, want %v", err, ErrTypeMismatch)
+			}
+		})
+	}
+
+	if got, err := Int(sdk, "title", ctx, 7); got != 7 || err == nil {
+		t.Errorf("Int() on mismatch = %d, %v, want the fallback 7", got, err)
+	}
+
+	if got, err := String(sdk, "missing", ctx, "fallback"); got != "fallback" || err != ErrNotFoundContenthub {
+		t.Errorf("String() of missing content = %q, %v, want fallback", got, err)
+	}
+}
+
+func TestGet_CachePerVersion(t *testing.T) {
+	sdk := NewContenthubSDK("http://localhost:8080")
+	sdk.replace(map[string]Content{
+		"title": {Key: "title", BalancerStrategy: contenthub.BalancerStrategy{{Weight: 100, Response: []string{"a"}}}},
+	})
+	ctx := context.Background()
+
+	first, _ := Get(sdk, "title", ctx, []string(nil))
+	second, _ := Get(sdk, "title", ctx, []string(nil))
+	if &first[0] != &second[0] {
+		t.Error("Get() decoded the same version twice")
+	}
+
+	sdk.apply(sse.Event{Data: `{"key":"title","balancer_strategy":[{"weight":100,"response":["b"]}]}`})
+
+	if got, _ := Get(sdk, "title", ctx, []string(nil)); len(got) != 1 || got[0] != "b" {
+		t.Errorf("Get() after change = %v, want [b]", got)
+	}
+}
+
+type countingTransport struct {
+	requests atomic.Int32
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests.Add(1)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestContenthubSDK_Options(t *testing.T) {
+	var unavailable atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token" || r.Header.Get("X-Tenant") != "acme" ||
+			r.Header.Get("User-Agent") != "checkout/1.0 featureflag-sdk-go/"+httpclient.Version {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/contenthubs":
+			if unavailable.CompareAndSwap(false, true) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte(`[{"key":"banner","value":"a"}]`))
+		case "/events/contenthub":
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}
+	}))
+	defer server.Close()
+
+	streaming := make(chan struct{}, 1)
+	transport := &countingTransport{}
+	sdk := NewContenthubSDK(server.URL,
+		WithToken("token"),
+		WithHeader("X-Tenant", "acme"),
+		WithUserAgent("checkout/1.0"),
+		WithRoundTripper(transport),
+		WithTimeout(time.Second),
+		WithRetry(1, backoff.Backoff{Min: time.Millisecond, Max: time.Millisecond}),
+	).WithStateHandler(func(state State) {
+		if state == StateStreaming {
+			streaming <- struct{}{}
+		}
+	})
+
+	if err := sdk.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer sdk.Close()
+
+	select {
+	case <-streaming:
+	case <-time.After(2 * time.Second):
+		t.Fatal("sdk did not connect to the stream")
+	}
+
+	// the retried snapshot, the snapshot and the stream
+	if got := transport.requests.Load(); got != 3 {
+		t.Errorf("requests = %d, want 3", got)
+	}
+}
+
+func TestContenthubSDK_Unauthorized(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	sdk := NewContenthubSDK(server.URL)
+	if err := sdk.Start(context.Background()); err == nil {
+		sdk.Close()
+		t.Error("Start() error = nil with an unauthorized sdk")
+	}
+}
+
+func TestContenthubSDK_resync_NotModified(t *testing.T) {
+	var loads, notModified atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"7"`)
+		if r.Header.Get("If-None-Match") == `"7"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		loads.Add(1)
+		w.Write([]byte(`[{"key":"banner","balancer_strategy":[{"weight":100,"response":"a"}]}]`))
+	}))
+	defer server.Close()
+
+	sdk := NewContenthubSDK(server.URL)
+	if err := sdk.resync(context.Background()); err != nil {
+		t.Fatalf("resync() error = %v", err)
+	}
+
+	if err := sdk.resync(context.Background()); err != nil {
+		t.Fatalf("resync() not modified error = %v", err)
+	}
+
+	if loads.Load() != 1 || notModified.Load() != 1 {
+		t.Errorf("loads = %d, not modified = %d, want 1 and 1", loads.Load(), notModified.Load())
+	}
+
+	if _, err := sdk.Content("banner").Err(); err != nil {
+		t.Errorf("Content() after not modified error = %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+package contenthub
+
+import "github.com/IsaacDSC/featureflag/sdk/backoff"
+
+// State is the connection state of the stream
+type State string
+
+const (
+	// StateConnecting is set while connecting, including the resync made before every reconnection
+	StateConnecting State = "connecting"
+	// StateStreaming is set while the stream is connected
+	StateStreaming State = "streaming"
+	// StatePollingFallback is set while waiting to reconnect, only the refresh updates the cache
+	StatePollingFallback State = "polling-fallback"
+	// StateClosed is set by Close
+	StateClosed State = "closed"
+)
+
+// WithStateHandler registers fn to be called on every change of the connection state,
+// fn is called by the stream goroutine and by Close, so it must not block
+func (c *ContenthubSDK) WithStateHandler(fn func(state State)) *ContenthubSDK {
+	c.onState = fn
+	return c
+}
+
+// WithReconnectBackoff replaces backoff.Default as the delay between the reconnections of the stream
+func (c *ContenthubSDK) WithReconnectBackoff(b backoff.Backoff) *ContenthubSDK {
+	c.backoff = b
+	return c
+}
+
+// State returns the connection state of the stream, empty before Start
+func (c *ContenthubSDK) State() State {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	return c.state
+}
+
+func (c *ContenthubSDK) setState(state State) {
+	c.stateMu.Lock()
+	changed := c.state != state
+	c.state = state
+	c.stateMu.Unlock()
+
+	if changed && c.onState != nil {
+		c.onState(state)
+	}
+}
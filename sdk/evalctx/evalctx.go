@@ -0,0 +1,97 @@
+// Package evalctx is the evaluation context shared by the SDKs: who the flag or content is evaluated for.
+package evalctx
+
+import (
+	"context"
+	"maps"
+)
+
+// EvalContext has the targeting key, used as the sessionID by the strategies, and typed attributes
+// for targeting rules and bucketing. It is immutable, the With methods return a copy.
+type EvalContext struct {
+	TargetingKey string
+	attributes   map[string]any
+}
+
+// LocaleAttribute is the attribute of the locale the contents are translated to, as pt-BR
+const LocaleAttribute = "locale"
+
+func New(targetingKey string) EvalContext {
+	return EvalContext{TargetingKey: targetingKey}
+}
+
+// WithLocale sets the locale the contents are translated to, falling back from pt-BR to pt and then
+// to the response without translation
+func (e EvalContext) WithLocale(locale string) EvalContext {
+	return e.with(LocaleAttribute, locale)
+}
+
+// Locale returns the locale set with WithLocale, empty without it
+func (e EvalContext) Locale() string {
+	locale, _ := e.String(LocaleAttribute)
+	return locale
+}
+
+func (e EvalContext) WithString(name, value string) EvalContext {
+	return e.with(name, value)
+}
+
+func (e EvalContext) WithNumber(name string, value float64) EvalContext {
+	return e.with(name, value)
+}
+
+func (e EvalContext) WithBool(name string, value bool) EvalContext {
+	return e.with(name, value)
+}
+
+func (e EvalContext) WithList(name string, values ...string) EvalContext {
+	return e.with(name, append([]string(nil), values...))
+}
+
+func (e EvalContext) with(name string, value any) EvalContext {
+	attributes := make(map[string]any, len(e.attributes)+1)
+	maps.Copy(attributes, e.attributes)
+	attributes[name] = value
+
+	e.attributes = attributes
+	return e
+}
+
+func (e EvalContext) String(name string) (string, bool) {
+	value, ok := e.attributes[name].(string)
+	return value, ok
+}
+
+func (e EvalContext) Number(name string) (float64, bool) {
+	value, ok := e.attributes[name].(float64)
+	return value, ok
+}
+
+func (e EvalContext) Bool(name string) (bool, bool) {
+	value, ok := e.attributes[name].(bool)
+	return value, ok
+}
+
+func (e EvalContext) List(name string) ([]string, bool) {
+	value, ok := e.attributes[name].([]string)
+	return append([]string(nil), value...), ok
+}
+
+// Attributes returns a copy of the attributes, the values are string, float64, bool or []string
+func (e EvalContext) Attributes() map[string]any {
+	return maps.Clone(e.attributes)
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying e, so request handlers can evaluate for the caller
+// without passing it around
+func NewContext(ctx context.Context, e EvalContext) context.Context {
+	return context.WithValue(ctx, contextKey{}, e)
+}
+
+// FromContext returns the EvalContext of ctx, empty when there is none
+func FromContext(ctx context.Context) EvalContext {
+	e, _ := ctx.Value(contextKey{}).(EvalContext)
+	return e
+}
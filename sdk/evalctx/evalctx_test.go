@@ -0,0 +1,61 @@
+package evalctx
+
+import (
+	"context"
+	"testing"
+)
+
+func TestEvalContext_Attributes(t *testing.T) {
+	base := New("user-1").WithString("country", "BR")
+	e := base.WithNumber("age", 30).WithBool("beta", true).WithList("groups", "a", "b")
+
+	if country, ok := e.String("country"); !ok || country != "BR" {
+		t.Errorf("String(country) = %q, %v", country, ok)
+	}
+
+	if age, ok := e.Number("age"); !ok || age != 30 {
+		t.Errorf("Number(age) = %v, %v", age, ok)
+	}
+
+	if beta, ok := e.Bool("beta"); !ok || !beta {
+		t.Errorf("Bool(beta) = %v, %v", beta, ok)
+	}
+
+	if groups, ok := e.List("groups"); !ok || len(groups) != 2 || groups[1] != "b" {
+		t.Errorf("List(groups) = %v, %v", groups, ok)
+	}
+
+	if _, ok := e.Number("country"); ok {
+		t.Error("Number(country) ok = true for a string attribute")
+	}
+
+	if _, ok := base.Number("age"); ok {
+		t.Error("With changed the EvalContext it was called on")
+	}
+
+	if len(e.Attributes()) != 4 {
+		t.Errorf("Attributes() = %v, want 4 attributes", e.Attributes())
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	if e := FromContext(context.Background()); e.TargetingKey != "" {
+		t.Errorf("FromContext() without EvalContext = %+v, want empty", e)
+	}
+
+	ctx := NewContext(context.Background(), New("user-1"))
+	if e := FromContext(ctx); e.TargetingKey != "user-1" {
+		t.Errorf("FromContext().TargetingKey = %q, want %q", e.TargetingKey, "user-1")
+	}
+}
+
+func TestEvalContext_Locale(t *testing.T) {
+	e := New("user-1")
+	if e.Locale() != "" {
+		t.Errorf("Locale() without locale = %q, want empty", e.Locale())
+	}
+
+	if locale := e.WithLocale("pt-BR").Locale(); locale != "pt-BR" {
+		t.Errorf("Locale() = %q, want pt-BR", locale)
+	}
+}
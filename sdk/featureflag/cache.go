@@ -0,0 +1,149 @@
+package featureflag
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// WithCacheFile writes the flags to path after every change received from the server, and Start
+// serves them when the server is unavailable
+func (ff *FeatureFlagSDK) WithCacheFile(path string) *FeatureFlagSDK {
+	ff.cacheFile = path
+	return ff
+}
+
+// WithBootstrapFile makes Start serve the flags of path, in the format of GET /featureflags,
+// when the server is unavailable and there is no cache file
+func (ff *FeatureFlagSDK) WithBootstrapFile(path string) *FeatureFlagSDK {
+	ff.bootstrapFile = path
+	return ff
+}
+
+// WithBootstrap is WithBootstrapFile for a snapshot embedded in the binary, as with //go:embed.
+// It is used after the cache file and the bootstrap file.
+func (ff *FeatureFlagSDK) WithBootstrap(snapshot []byte) *FeatureFlagSDK {
+	ff.bootstrap = snapshot
+	return ff
+}
+
+// Cached reports whether the flags come from the cache file or the bootstrap, it is false
+// once they are loaded from the server
+func (ff *FeatureFlagSDK) Cached() bool {
+	return ff.cached.Load()
+}
+
+// SyncedAt returns when the flags were last received from the server, by a load or an event
+// of the stream. It is zero while only the offline flags were loaded.
+func (ff *FeatureFlagSDK) SyncedAt() time.Time {
+	if nanos := ff.syncedAt.Load(); nanos != 0 {
+		return time.Unix(0, nanos)
+	}
+
+	return time.Time{}
+}
+
+func (ff *FeatureFlagSDK) markSynced() {
+	ff.syncedAt.Store(time.Now().UnixNano())
+}
+
+// loadOffline returns the flags of the first offline source available
+func (ff *FeatureFlagSDK) loadOffline() (map[string]Flag, string, error) {
+	var errs []error
+	for _, path := range []string{ff.cacheFile, ff.bootstrapFile} {
+		if path == "" {
+			continue
+		}
+
+		b, err := os.ReadFile(path)
+		if err == nil {
+			var flags map[string]Flag
+			if flags, err = decodeFlags(b); err == nil {
+				return flags, path, nil
+			}
+		}
+
+		errs = append(errs, fmt.Errorf("error on load %s: %w", path, err))
+	}
+
+	if ff.bootstrap != nil {
+		flags, err := decodeFlags(ff.bootstrap)
+		if err == nil {
+			return flags, "embedded bootstrap", nil
+		}
+
+		errs = append(errs, fmt.Errorf("error on load embedded bootstrap: %w", err))
+	}
+
+	if len(errs) == 0 {
+		return nil, "", errors.New("no cache file or bootstrap configured")
+	}
+
+	return nil, "", errors.Join(errs...)
+}
+
+func decodeFlags(b []byte) (map[string]Flag, error) {
+	var flags []Flag
+	if err := json.Unmarshal(b, &flags); err != nil {
+		return nil, fmt.Errorf("error on decode json: %w", err)
+	}
+
+	output := make(map[string]Flag)
+	for _, flag := range flags {
+		output[flag.FlagName] = flag
+	}
+
+	return output, nil
+}
+
+// persist writes the flags to the cache file, only the flags received from the server are written
+func (ff *FeatureFlagSDK) persist() {
+	if ff.cacheFile == "" || ff.Cached() {
+		return
+	}
+
+	ff.mu.RLock()
+	flags := make([]Flag, 0, len(ff.inMemoryFlags))
+	for _, flag := range ff.inMemoryFlags {
+		flags = append(flags, flag)
+	}
+	ff.mu.RUnlock()
+
+	sort.Slice(flags, func(i, j int) bool { return flags[i].FlagName < flags[j].FlagName })
+
+	ff.fileMu.Lock()
+	defer ff.fileMu.Unlock()
+
+	if err := writeFile(ff.cacheFile, flags); err != nil {
+		ff.logger.Error("error on write featureflag cache file", "path", ff.cacheFile, "error", err)
+	}
+}
+
+// writeFile replaces path atomically, a crash never leaves a partial cache
+func writeFile(path string, value any) error {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("error on encode json: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,45 @@
+package featureflag
+
+// change is a flag added (old is empty), updated or removed on the server (new is empty)
+type change struct {
+	old, new Flag
+}
+
+// OnChange registers fn to be called when the flag key is added, changed or removed on the server.
+// The callbacks run in order on a goroutine of the sdk, never for updates that change nothing,
+// and a slow callback delays the next ones without blocking the stream.
+func (ff *FeatureFlagSDK) OnChange(key string, fn func(old, new Flag)) {
+	ff.changeMu.Lock()
+	defer ff.changeMu.Unlock()
+
+	if ff.onChange == nil {
+		ff.onChange = make(map[string][]func(old, new Flag))
+	}
+	ff.onChange[key] = append(ff.onChange[key], fn)
+}
+
+// OnAnyChange is OnChange for every flag, the name is in old.FlagName when the flag is removed
+func (ff *FeatureFlagSDK) OnAnyChange(fn func(old, new Flag)) {
+	ff.changeMu.Lock()
+	defer ff.changeMu.Unlock()
+
+	ff.onAnyChange = append(ff.onAnyChange, fn)
+}
+
+func (ff *FeatureFlagSDK) notify(changes []change) {
+	ff.changeMu.RLock()
+	defer ff.changeMu.RUnlock()
+
+	for _, c := range changes {
+		key := c.new.FlagName
+		if key == "" {
+			key = c.old.FlagName
+		}
+
+		for _, fns := range [][]func(old, new Flag){ff.onChange[key], ff.onAnyChange} {
+			for _, fn := range fns {
+				ff.changes.Push(func() { fn(c.old, c.new) })
+			}
+		}
+	}
+}
@@ -4,3 +4,4 @@ import "errors"
 
 var ErrInvalidStrategy = errors.New("featureflag with strategy required sessionID")
 var ErrNotFoundFeatureFlag = errors.New("not found featureflag")
+var ErrAlreadyStarted = errors.New("featureflag sdk already started")
@@ -0,0 +1,16 @@
+package featureflag
+
+import "context"
+
+// Evaluator is what the applications use of FeatureFlagSDK. Depending on it instead of the SDK
+// lets their tests use fftest.Fake, without a server.
+type Evaluator interface {
+	Evaluate(key string, ec EvalContext) FFResponse
+	EvaluateContext(ctx context.Context, key string) FFResponse
+	GetFeatureFlag(key string, sessionID ...string) FFResponse
+	Flag(key string) (Flag, bool)
+	OnChange(key string, fn func(old, new Flag))
+	OnAnyChange(fn func(old, new Flag))
+}
+
+var _ Evaluator = (*FeatureFlagSDK)(nil)
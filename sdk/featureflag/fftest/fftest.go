@@ -0,0 +1,192 @@
+// Package fftest is an in-memory featureflag.Evaluator for the tests of the applications that use the SDK.
+//
+//	flags := fftest.New().Set("new-checkout", true).SetVariant("new-checkout", "user-1", false)
+//	service := NewCheckout(flags)
+//	...
+//	if got := flags.Evaluated("new-checkout"); len(got) != 1 || got[0].TargetingKey != "user-1" {
+//		t.Errorf("new-checkout evaluated for %v", got)
+//	}
+package fftest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/IsaacDSC/featureflag/sdk/evalctx"
+	"github.com/IsaacDSC/featureflag/sdk/featureflag"
+)
+
+// Evaluation is an evaluation made on the Fake
+type Evaluation struct {
+	Key     string
+	Context featureflag.EvalContext
+	Value   bool
+	Err     error
+}
+
+// Fake evaluates the flags set by the test: the variant of the targeting key when there is one,
+// otherwise the value of the flag. The strategies are not evaluated, SetVariant replaces them.
+// Unlike the SDK, the change callbacks run before Set, SetVariant and Delete return.
+type Fake struct {
+	mu          sync.Mutex
+	flags       map[string]featureflag.Flag
+	variants    map[string]map[string]bool
+	evaluations []Evaluation
+
+	changeMu    sync.Mutex
+	onChange    map[string][]func(old, new featureflag.Flag)
+	onAnyChange []func(old, new featureflag.Flag)
+}
+
+var _ featureflag.Evaluator = (*Fake)(nil)
+
+func New() *Fake {
+	return &Fake{
+		flags:    make(map[string]featureflag.Flag),
+		variants: make(map[string]map[string]bool),
+		onChange: make(map[string][]func(old, new featureflag.Flag)),
+	}
+}
+
+// Set adds or changes the flag key, as a change received from the server
+func (f *Fake) Set(key string, active bool) *Fake {
+	f.mu.Lock()
+	old := f.flags[key]
+	flag := featureflag.Flag{FlagName: key, Active: active}
+	f.flags[key] = flag
+	f.mu.Unlock()
+
+	f.changed(key, old, flag)
+	return f
+}
+
+// SetVariant makes key return active for the evaluations with targetingKey, the flag is added
+// (inactive for the other targeting keys) when it does not exist
+func (f *Fake) SetVariant(key, targetingKey string, active bool) *Fake {
+	f.mu.Lock()
+	old, ok := f.flags[key]
+	if !ok {
+		f.flags[key] = featureflag.Flag{FlagName: key}
+	}
+
+	if f.variants[key] == nil {
+		f.variants[key] = make(map[string]bool)
+	}
+	f.variants[key][targetingKey] = active
+	flag := f.flags[key]
+	f.mu.Unlock()
+
+	f.changed(key, old, flag)
+	return f
+}
+
+// Delete removes the flag key and its variants, as a flag removed on the server
+func (f *Fake) Delete(key string) *Fake {
+	f.mu.Lock()
+	old, ok := f.flags[key]
+	delete(f.flags, key)
+	delete(f.variants, key)
+	f.mu.Unlock()
+
+	if ok {
+		f.changed(key, old, featureflag.Flag{})
+	}
+	return f
+}
+
+func (f *Fake) Evaluate(key string, ec featureflag.EvalContext) featureflag.FFResponse {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	response := f.evaluate(key, ec)
+	f.evaluations = append(f.evaluations, Evaluation{Key: key, Context: ec, Value: response.Bool, Err: response.Error})
+
+	return response
+}
+
+func (f *Fake) evaluate(key string, ec featureflag.EvalContext) featureflag.FFResponse {
+	flag, ok := f.flags[key]
+	if !ok {
+		return featureflag.FFResponse{Error: featureflag.ErrNotFoundFeatureFlag}
+	}
+
+	if active, ok := f.variants[key][ec.TargetingKey]; ok && ec.TargetingKey != "" {
+		return featureflag.FFResponse{Bool: active}
+	}
+
+	return featureflag.FFResponse{Bool: flag.Active}
+}
+
+// EvaluateContext is Evaluate with the EvalContext carried by ctx
+func (f *Fake) EvaluateContext(ctx context.Context, key string) featureflag.FFResponse {
+	return f.Evaluate(key, evalctx.FromContext(ctx))
+}
+
+// GetFeatureFlag is Evaluate with sessionID as the targeting key
+func (f *Fake) GetFeatureFlag(key string, sessionID ...string) featureflag.FFResponse {
+	if len(sessionID) > 0 {
+		return f.Evaluate(key, evalctx.New(sessionID[0]))
+	}
+
+	return f.Evaluate(key, featureflag.EvalContext{})
+}
+
+func (f *Fake) Flag(key string) (featureflag.Flag, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	flag, ok := f.flags[key]
+	return flag, ok
+}
+
+func (f *Fake) OnChange(key string, fn func(old, new featureflag.Flag)) {
+	f.changeMu.Lock()
+	defer f.changeMu.Unlock()
+
+	f.onChange[key] = append(f.onChange[key], fn)
+}
+
+func (f *Fake) OnAnyChange(fn func(old, new featureflag.Flag)) {
+	f.changeMu.Lock()
+	defer f.changeMu.Unlock()
+
+	f.onAnyChange = append(f.onAnyChange, fn)
+}
+
+func (f *Fake) changed(key string, old, new featureflag.Flag) {
+	f.changeMu.Lock()
+	fns := append(append([]func(old, new featureflag.Flag){}, f.onChange[key]...), f.onAnyChange...)
+	f.changeMu.Unlock()
+
+	for _, fn := range fns {
+		fn(old, new)
+	}
+}
+
+// Evaluations returns the evaluations made, in order
+func (f *Fake) Evaluations() []Evaluation {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]Evaluation(nil), f.evaluations...)
+}
+
+// Evaluated returns the contexts key was evaluated with, in order
+func (f *Fake) Evaluated(key string) []featureflag.EvalContext {
+	var contexts []featureflag.EvalContext
+	for _, e := range f.Evaluations() {
+		if e.Key == key {
+			contexts = append(contexts, e.Context)
+		}
+	}
+
+	return contexts
+}
+
+// Reset forgets the evaluations made, the flags and the callbacks are kept
+func (f *Fake) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.evaluations = nil
+}
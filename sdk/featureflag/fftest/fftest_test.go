@@ -0,0 +1,87 @@
+package fftest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/IsaacDSC/featureflag/sdk/evalctx"
+	"github.com/IsaacDSC/featureflag/sdk/featureflag"
+)
+
+// checkout depends on the Evaluator, as the applications using the fake
+type checkout struct {
+	flags featureflag.Evaluator
+}
+
+func (c checkout) newFlow(ctx context.Context) bool {
+	return c.flags.EvaluateContext(ctx, "new-checkout").WithDefault(false)
+}
+
+func TestFake_Evaluate(t *testing.T) {
+	fake := New().Set("new-checkout", true).SetVariant("new-checkout", "user-2", false)
+	service := checkout{flags: fake}
+
+	tests := []struct {
+		name string
+		ec   featureflag.EvalContext
+		want bool
+	}{
+		{name: "without targeting key", want: true},
+		{name: "targeting key without variant", ec: evalctx.New("user-1").WithString("country", "BR"), want: true},
+		{name: "targeting key with variant", ec: evalctx.New("user-2"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := service.newFlow(evalctx.NewContext(context.Background(), tt.ec)); got != tt.want {
+				t.Errorf("newFlow() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	contexts := fake.Evaluated("new-checkout")
+	if len(contexts) != 3 || contexts[1].TargetingKey != "user-1" {
+		t.Fatalf("Evaluated() = %+v, want the 3 contexts", contexts)
+	}
+	if country, _ := contexts[1].String("country"); country != "BR" {
+		t.Errorf("country = %q, want BR", country)
+	}
+
+	if _, err := fake.GetFeatureFlag("missing").Err(); !errors.Is(err, featureflag.ErrNotFoundFeatureFlag) {
+		t.Errorf("GetFeatureFlag() error = %v, want ErrNotFoundFeatureFlag", err)
+	}
+
+	fake.Reset()
+	if len(fake.Evaluations()) != 0 {
+		t.Errorf("Evaluations() = %+v after Reset", fake.Evaluations())
+	}
+}
+
+func TestFake_OnChange(t *testing.T) {
+	fake := New()
+
+	var changes, all []featureflag.Flag
+	fake.OnChange("a", func(old, new featureflag.Flag) { changes = append(changes, old, new) })
+	fake.OnAnyChange(func(old, new featureflag.Flag) { all = append(all, new) })
+
+	fake.Set("a", true).Set("b", true).Delete("a").Delete("missing")
+
+	want := []featureflag.Flag{{}, {FlagName: "a", Active: true}, {FlagName: "a", Active: true}, {}}
+	if len(changes) != len(want) {
+		t.Fatalf("OnChange got %+v, want %+v", changes, want)
+	}
+	for i := range want {
+		if changes[i].FlagName != want[i].FlagName || changes[i].Active != want[i].Active {
+			t.Errorf("change %d = %+v, want %+v", i, changes[i], want[i])
+		}
+	}
+
+	if len(all) != 3 {
+		t.Errorf("OnAnyChange called %d times, want 3", len(all))
+	}
+
+	if _, ok := fake.Flag("a"); ok {
+		t.Error("Flag() found a after Delete")
+	}
+}
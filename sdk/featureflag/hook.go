@@ -0,0 +1,49 @@
+package featureflag
+
+import (
+	"context"
+	"time"
+)
+
+// Evaluation is given to the hooks after every evaluation
+type Evaluation struct {
+	Key     string
+	Context EvalContext
+	Value   bool
+	// Err is ErrNotFoundFeatureFlag when the flag does not exist, then Value is the default
+	Err      error
+	Start    time.Time
+	Duration time.Duration
+}
+
+// WithEvaluationHook registers fn to be called after every evaluation, with the ctx of EvaluateContext
+// or context.Background. fn is called by the goroutine that evaluated, so it must be fast; see
+// sdk/otelhook for hooks that record spans and metrics.
+func (ff *FeatureFlagSDK) WithEvaluationHook(fn func(ctx context.Context, e Evaluation)) *FeatureFlagSDK {
+	ff.hooks = append(ff.hooks, fn)
+	return ff
+}
+
+func (ff *FeatureFlagSDK) hooked(ctx context.Context, key string, ec EvalContext) FFResponse {
+	if len(ff.hooks) == 0 {
+		return ff.evaluate(key, ec)
+	}
+
+	start := time.Now()
+	response := ff.evaluate(key, ec)
+
+	e := Evaluation{
+		Key:      key,
+		Context:  ec,
+		Value:    response.Bool,
+		Err:      response.Error,
+		Start:    start,
+		Duration: time.Since(start),
+	}
+
+	for _, hook := range ff.hooks {
+		hook(ctx, e)
+	}
+
+	return response
+}
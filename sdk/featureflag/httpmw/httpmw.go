@@ -0,0 +1,153 @@
+// Package httpmw is a net/http middleware that resolves the flags of each request.
+//
+//	mw := httpmw.New(sdk, httpmw.WithCookie("session_id"), httpmw.WithHeader("session_id"))
+//	http.ListenAndServe(":8080", mw(mux))
+//
+// The handlers read the flags with featureflag.FromContext(r.Context()).Bool("key").
+package httpmw
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/IsaacDSC/featureflag/sdk/evalctx"
+	"github.com/IsaacDSC/featureflag/sdk/featureflag"
+)
+
+// Extractor adds what it finds in the request to ec. The extractors run in the order they were
+// given, and only the first targeting key found is kept.
+type Extractor func(r *http.Request, ec featureflag.EvalContext) featureflag.EvalContext
+
+type config struct {
+	extractors  []Extractor
+	debugHeader string
+}
+
+type Option func(cfg *config)
+
+// WithExtractor adds a custom extractor
+func WithExtractor(extractor Extractor) Option {
+	return func(cfg *config) {
+		cfg.extractors = append(cfg.extractors, extractor)
+	}
+}
+
+// WithCookie uses the value of the cookie name as the targeting key
+func WithCookie(name string) Option {
+	return WithExtractor(func(r *http.Request, ec featureflag.EvalContext) featureflag.EvalContext {
+		cookie, err := r.Cookie(name)
+		if err != nil {
+			return ec
+		}
+
+		return withTargetingKey(ec, cookie.Value)
+	})
+}
+
+// WithHeader uses the value of the header name, as session_id, as the targeting key
+func WithHeader(name string) Option {
+	return WithExtractor(func(r *http.Request, ec featureflag.EvalContext) featureflag.EvalContext {
+		return withTargetingKey(ec, r.Header.Get(name))
+	})
+}
+
+// WithDebugHeader adds the header name to the responses with the flags evaluated before the
+// response was written, as "new-checkout=true, dark-mode=false". The flags not found are not listed.
+func WithDebugHeader(name string) Option {
+	return func(cfg *config) {
+		cfg.debugHeader = name
+	}
+}
+
+func withTargetingKey(ec featureflag.EvalContext, targetingKey string) featureflag.EvalContext {
+	if ec.TargetingKey == "" {
+		ec.TargetingKey = targetingKey
+	}
+
+	return ec
+}
+
+// New returns the middleware. It stores the EvalContext built by the extractors in the context
+// of the request, as evalctx.NewContext, with the featureflag.RequestFlags that evaluates for it.
+func New(evaluator featureflag.Evaluator, opts ...Option) func(http.Handler) http.Handler {
+	cfg := &config{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ec := featureflag.EvalContext{}
+			for _, extractor := range cfg.extractors {
+				ec = extractor(r, ec)
+			}
+
+			ctx := evalctx.NewContext(r.Context(), ec)
+			flags := featureflag.NewRequestFlags(ctx, evaluator)
+			ctx = featureflag.NewContext(ctx, flags)
+
+			if cfg.debugHeader != "" {
+				w = &debugWriter{ResponseWriter: w, header: cfg.debugHeader, flags: flags}
+			}
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// debugWriter adds the debug header right before the headers are written
+type debugWriter struct {
+	http.ResponseWriter
+	header  string
+	flags   *featureflag.RequestFlags
+	written bool
+}
+
+func (dw *debugWriter) WriteHeader(code int) {
+	if !dw.written {
+		dw.written = true
+		if value := debugValue(dw.flags); value != "" {
+			dw.Header().Set(dw.header, value)
+		}
+	}
+
+	dw.ResponseWriter.WriteHeader(code)
+}
+
+func (dw *debugWriter) Write(b []byte) (int, error) {
+	if !dw.written {
+		dw.WriteHeader(http.StatusOK)
+	}
+
+	return dw.ResponseWriter.Write(b)
+}
+
+func (dw *debugWriter) Flush() {
+	if !dw.written {
+		dw.WriteHeader(http.StatusOK)
+	}
+
+	if flusher, ok := dw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the writer of the server
+func (dw *debugWriter) Unwrap() http.ResponseWriter {
+	return dw.ResponseWriter
+}
+
+func debugValue(flags *featureflag.RequestFlags) string {
+	keys, values := flags.Evaluated()
+
+	evaluated := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if values[key].Error != nil {
+			continue
+		}
+		evaluated = append(evaluated, fmt.Sprintf("%s=%t", key, values[key].Bool))
+	}
+
+	return strings.Join(evaluated, ", ")
+}
@@ -0,0 +1,102 @@
+package httpmw
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/IsaacDSC/featureflag/sdk/evalctx"
+	"github.com/IsaacDSC/featureflag/sdk/featureflag"
+	"github.com/IsaacDSC/featureflag/sdk/featureflag/fftest"
+)
+
+func TestNew_Extractors(t *testing.T) {
+	token := "header." + base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"jwt-user","plan":"pro","beta":true,"groups":["a","b"]}`)) + ".signature"
+
+	tests := []struct {
+		name    string
+		request func(r *http.Request)
+		want    string
+	}{
+		{name: "nothing", request: func(r *http.Request) {}, want: ""},
+		{name: "cookie", request: func(r *http.Request) { r.AddCookie(&http.Cookie{Name: "session", Value: "cookie-user"}) }, want: "cookie-user"},
+		{name: "header", request: func(r *http.Request) { r.Header.Set("session_id", "header-user") }, want: "header-user"},
+		{name: "jwt", request: func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+token) }, want: "jwt-user"},
+		{name: "cookie first", request: func(r *http.Request) {
+			r.AddCookie(&http.Cookie{Name: "session", Value: "cookie-user"})
+			r.Header.Set("session_id", "header-user")
+		}, want: "cookie-user"},
+		{name: "invalid jwt", request: func(r *http.Request) { r.Header.Set("Authorization", "Bearer invalid") }, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got featureflag.EvalContext
+			handler := New(fftest.New(),
+				WithCookie("session"),
+				WithHeader("session_id"),
+				WithJWT(UnverifiedClaims, "sub", "plan", "beta", "groups"),
+			)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = evalctx.FromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest("GET", "/", nil)
+			tt.request(req)
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+
+			if got.TargetingKey != tt.want {
+				t.Errorf("TargetingKey = %q, want %q", got.TargetingKey, tt.want)
+			}
+
+			if tt.name == "jwt" {
+				plan, _ := got.String("plan")
+				beta, _ := got.Bool("beta")
+				groups, _ := got.List("groups")
+				if plan != "pro" || !beta || len(groups) != 2 {
+					t.Errorf("attributes = %v", got.Attributes())
+				}
+			}
+		})
+	}
+}
+
+func TestNew_OncePerRequest(t *testing.T) {
+	flags := fftest.New().Set("new-checkout", true).Set("dark-mode", false).SetVariant("new-checkout", "user-1", false)
+
+	handler := New(flags, WithHeader("session_id"), WithDebugHeader("X-Featureflags"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rf := featureflag.FromContext(r.Context())
+		first := rf.Bool("new-checkout")
+
+		// a change in the middle of the request is not seen by it
+		flags.Set("new-checkout", true).SetVariant("new-checkout", "user-1", true)
+
+		if rf.Bool("new-checkout") != first || first {
+			t.Errorf("new-checkout = %v then %v, want false twice", first, rf.Bool("new-checkout"))
+		}
+
+		rf.Bool("dark-mode")
+		rf.Bool("missing")
+		w.Write([]byte("ok"))
+	}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("session_id", "user-1")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if got := flags.Evaluated("new-checkout"); len(got) != 1 || got[0].TargetingKey != "user-1" {
+		t.Errorf("new-checkout evaluated for %+v, want user-1 once", got)
+	}
+
+	if got := rec.Header().Get("X-Featureflags"); got != "new-checkout=false, dark-mode=false" {
+		t.Errorf("debug header = %q", got)
+	}
+}
+
+func TestFromContext_WithoutMiddleware(t *testing.T) {
+	rf := featureflag.FromContext(httptest.NewRequest("GET", "/", nil).Context())
+	if rf.Bool("new-checkout") {
+		t.Error("Bool() = true without the middleware")
+	}
+}
@@ -0,0 +1,86 @@
+package httpmw
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/IsaacDSC/featureflag/sdk/featureflag"
+)
+
+var ErrInvalidToken = errors.New("invalid jwt")
+
+// WithJWT reads the bearer token of the Authorization header with parse, which verifies it and
+// returns its claims. The claim targetingClaim (as "sub") is the targeting key, and the claims
+// listed in attributes are added to the EvalContext when they are strings, numbers, booleans or
+// lists of strings. The requests whose token parse rejects are evaluated without them.
+func WithJWT(parse func(token string) (map[string]any, error), targetingClaim string, attributes ...string) Option {
+	return WithExtractor(func(r *http.Request, ec featureflag.EvalContext) featureflag.EvalContext {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok {
+			return ec
+		}
+
+		claims, err := parse(token)
+		if err != nil {
+			return ec
+		}
+
+		if targetingKey, ok := claims[targetingClaim].(string); ok {
+			ec = withTargetingKey(ec, targetingKey)
+		}
+
+		for _, name := range attributes {
+			ec = withClaim(ec, name, claims[name])
+		}
+
+		return ec
+	})
+}
+
+func withClaim(ec featureflag.EvalContext, name string, value any) featureflag.EvalContext {
+	switch v := value.(type) {
+	case string:
+		return ec.WithString(name, v)
+	case bool:
+		return ec.WithBool(name, v)
+	case float64:
+		return ec.WithNumber(name, v)
+	case []any:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return ec
+			}
+			list = append(list, s)
+		}
+		return ec.WithList(name, list...)
+	}
+
+	return ec
+}
+
+// UnverifiedClaims decodes the claims of token without verifying its signature, it is only a parse
+// for WithJWT behind a gateway that already verified the token
+func UnverifiedClaims(token string) (map[string]any, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	var claims map[string]any
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	return claims, nil
+}
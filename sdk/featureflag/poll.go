@@ -0,0 +1,155 @@
+package featureflag
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/IsaacDSC/featureflag/sdk/httpclient"
+)
+
+// errChangesUnsupported is returned by the servers without revisions, which are resynced instead
+var errChangesUnsupported = errors.New("featureflag changes unsupported by the server")
+
+// errChangesExpired is returned when the server no longer knows the keys deleted since the revision
+var errChangesExpired = errors.New("featureflag changes expired on the server")
+
+type changesResponse struct {
+	Revision int64    `json:"revision"`
+	Changed  []Flag   `json:"changed"`
+	Deleted  []string `json:"deleted"`
+}
+
+// poll is the refresh of the flags, it asks only for the flags changed since the revision of the
+// last response and loads all of them when the revision is unknown, too old, or the server has no revisions
+func (ff *FeatureFlagSDK) poll(ctx context.Context) error {
+	since := ff.getRevision()
+	if since == "" || ff.Cached() {
+		return ff.resync(ctx)
+	}
+
+	response, err := ff.getChanges(ctx, since)
+	if errors.Is(err, errChangesUnsupported) || errors.Is(err, errChangesExpired) {
+		ff.setRevision("")
+		return ff.resync(ctx)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	changes := ff.mergeChanges(response)
+	if len(changes) > 0 {
+		ff.logger.Info("featureflags updated by refresh", "changed", len(changes))
+		ff.synced(changes)
+	}
+
+	return nil
+}
+
+func (ff *FeatureFlagSDK) getChanges(ctx context.Context, since string) (changesResponse, error) {
+	serverUrl := fmt.Sprintf("%s/featureflags/changes?since=%s", ff.host, url.QueryEscape(since))
+
+	resp, err := httpclient.Get(ctx, ff.client, ff.http, serverUrl)
+	if err != nil {
+		return changesResponse{}, fmt.Errorf("error on get featureflag changes: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotImplemented:
+		return changesResponse{}, errChangesUnsupported
+	case http.StatusGone:
+		return changesResponse{}, errChangesExpired
+	default:
+		return changesResponse{}, fmt.Errorf("invalid status: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return changesResponse{}, fmt.Errorf("error on io read all, %w", err)
+	}
+
+	var response changesResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return changesResponse{}, fmt.Errorf("error on decode json: %w", err)
+	}
+
+	ff.markSynced()
+
+	return response, nil
+}
+
+// mergeChanges applies the flags changed and deleted to the cache and returns the changes
+func (ff *FeatureFlagSDK) mergeChanges(response changesResponse) []change {
+	serverFlags := make(map[string]Flag)
+	for _, flag := range response.Changed {
+		serverFlags[flag.FlagName] = flag
+	}
+
+	ff.mu.Lock()
+	defer ff.mu.Unlock()
+
+	changedFlags := filterChangedFlags(serverFlags, ff.inMemoryFlags)
+
+	var changes []change
+	for name, flag := range changedFlags {
+		changes = append(changes, change{old: ff.inMemoryFlags[name], new: flag})
+	}
+
+	flags := maps.Clone(ff.inMemoryFlags)
+	if flags == nil {
+		flags = make(map[string]Flag)
+	}
+
+	maps.Copy(flags, changedFlags)
+
+	for _, name := range response.Deleted {
+		if flag, ok := flags[name]; ok {
+			changes = append(changes, change{old: flag})
+			delete(flags, name)
+		}
+	}
+
+	ff.inMemoryFlags = flags
+	ff.revision = strconv.FormatInt(response.Revision, 10)
+
+	return changes
+}
+
+func (ff *FeatureFlagSDK) getRevision() string {
+	ff.mu.RLock()
+	defer ff.mu.RUnlock()
+
+	return ff.revision
+}
+
+func (ff *FeatureFlagSDK) setRevision(revision string) {
+	ff.mu.Lock()
+	defer ff.mu.Unlock()
+
+	ff.revision = revision
+}
+
+// revisionOf returns the revision of the ETag of GET /featureflags, empty without revisions
+func revisionOf(etag string) string {
+	revision, err := strconv.Unquote(strings.TrimPrefix(etag, "W/"))
+	if err != nil {
+		return ""
+	}
+
+	if _, err := strconv.ParseInt(revision, 10, 64); err != nil {
+		return ""
+	}
+
+	return revision
+}
@@ -0,0 +1,85 @@
+package featureflag
+
+import (
+	"context"
+	"sync"
+)
+
+// RequestFlags evaluates the flags of one request, each flag once: the first evaluation of a key
+// is kept, so every handler of the request sees the same value even if the flag changes meanwhile
+// or its strategy is balanced by percentage. It is safe for concurrent use.
+type RequestFlags struct {
+	ctx       context.Context
+	evaluator Evaluator
+
+	mu     sync.Mutex
+	values map[string]FFResponse
+	keys   []string
+}
+
+// NewRequestFlags evaluates with evaluator.EvaluateContext and ctx, which carries the EvalContext
+// of the request (see evalctx.NewContext)
+func NewRequestFlags(ctx context.Context, evaluator Evaluator) *RequestFlags {
+	return &RequestFlags{
+		ctx:       ctx,
+		evaluator: evaluator,
+		values:    make(map[string]FFResponse),
+	}
+}
+
+// Evaluate returns the value of key for the request, evaluating it on the first call.
+// Without RequestFlags (FromContext returned nil) every key is ErrNotFoundFeatureFlag.
+func (rf *RequestFlags) Evaluate(key string) FFResponse {
+	if rf == nil {
+		return FFResponse{Error: ErrNotFoundFeatureFlag}
+	}
+
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	if response, ok := rf.values[key]; ok {
+		return response
+	}
+
+	response := rf.evaluator.EvaluateContext(rf.ctx, key)
+	rf.values[key] = response
+	rf.keys = append(rf.keys, key)
+
+	return response
+}
+
+// Bool is the value of key, false when the flag does not exist
+func (rf *RequestFlags) Bool(key string) bool {
+	return rf.Evaluate(key).WithDefault(false)
+}
+
+// Evaluated returns the keys evaluated so far with their responses, in the order of evaluation
+func (rf *RequestFlags) Evaluated() ([]string, map[string]FFResponse) {
+	if rf == nil {
+		return nil, nil
+	}
+
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+
+	values := make(map[string]FFResponse, len(rf.values))
+	for key, response := range rf.values {
+		values[key] = response
+	}
+
+	return append([]string(nil), rf.keys...), values
+}
+
+type requestFlagsKey struct{}
+
+// NewContext returns a copy of ctx carrying rf, see sdk/featureflag/httpmw
+func NewContext(ctx context.Context, rf *RequestFlags) context.Context {
+	return context.WithValue(ctx, requestFlagsKey{}, rf)
+}
+
+// FromContext returns the RequestFlags of ctx. It is nil when there is none, which evaluates
+// every flag as not found.
+func FromContext(ctx context.Context) *RequestFlags {
+	rf, _ := ctx.Value(requestFlagsKey{}).(*RequestFlags)
+	return rf
+}
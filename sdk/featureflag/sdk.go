@@ -1,147 +1,361 @@
 package featureflag
 
 import (
-	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
-	"os"
-	"os/signal"
 	"reflect"
-	"strings"
-	"syscall"
+	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/IsaacDSC/featureflag/sdk/backoff"
+	"github.com/IsaacDSC/featureflag/sdk/evalctx"
+	"github.com/IsaacDSC/featureflag/sdk/httpclient"
+	"github.com/IsaacDSC/featureflag/sdk/notify"
+	"github.com/IsaacDSC/featureflag/sdk/sse"
+	"github.com/IsaacDSC/featureflag/sdk/ws"
 )
 
 type FeatureFlagSDK struct {
 	host      string
 	client    *http.Client
+	http      httpclient.Config
 	ffDefault bool
 
 	sleeper       time.Duration
+	mu            sync.RWMutex
 	inMemoryFlags map[string]Flag
+	revision      string
+	lastEventID   string
+	transport     Transport
+	logger        *slog.Logger
+	backoff       backoff.Backoff
+
+	cacheFile     string
+	bootstrapFile string
+	bootstrap     []byte
+	cached        atomic.Bool
+	syncedAt      atomic.Int64
+	fileMu        sync.Mutex
+
+	changeMu    sync.RWMutex
+	onChange    map[string][]func(old, new Flag)
+	onAnyChange []func(old, new Flag)
+	changes     *notify.Queue
+
+	stateMu sync.Mutex
+	state   State
+	onState func(state State)
+
+	hooks []func(ctx context.Context, e Evaluation)
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+const (
+	snapshotEvent = "snapshot"
+	// deletedEvent has the key of a flag deleted on the server
+	deletedEvent = "deleted"
+)
+
+// deleted is the data of deletedEvent
+type deleted struct {
+	Key string `json:"key"`
 }
 
-func NewFeatureFlagSDK(hostFF string) *FeatureFlagSDK {
-	sdk := &FeatureFlagSDK{client: &http.Client{}, host: hostFF, sleeper: time.Second * 60}
+// EvalContext is who the flags are evaluated for, built with evalctx.New
+type EvalContext = evalctx.EvalContext
+
+type Transport string
+
+const (
+	TransportSSE       Transport = "sse"
+	TransportWebSocket Transport = "websocket"
+)
+
+// NewFeatureFlagSDK accepts Options for the requests to the server, sent with httpclient.Default otherwise
+func NewFeatureFlagSDK(hostFF string, opts ...Option) *FeatureFlagSDK {
+	sdk := &FeatureFlagSDK{client: &http.Client{}, host: hostFF, http: httpclient.Default(), sleeper: time.Second * 60, logger: slog.Default(), backoff: backoff.Default(), changes: notify.NewQueue()}
+	for _, opt := range opts {
+		opt(sdk)
+	}
+
 	return sdk
 }
 
+// WithLogger replaces slog.Default as the logger of the stream and refresh
+func (ff *FeatureFlagSDK) WithLogger(logger *slog.Logger) *FeatureFlagSDK {
+	ff.logger = logger
+	return ff
+}
+
+// WithTransport chooses how updates are received, TransportSSE (default) or TransportWebSocket
+// for environments where proxies buffer or break server-sent events
+func (ff *FeatureFlagSDK) WithTransport(transport Transport) *FeatureFlagSDK {
+	ff.transport = transport
+	return ff
+}
+
+// synced is called after every change of the flags received from the server
+func (ff *FeatureFlagSDK) synced(changes []change) {
+	ff.persist()
+	ff.notify(changes)
+}
+
 func (c *FeatureFlagSDK) WithEventualConsistency(time time.Duration) *FeatureFlagSDK {
 	c.sleeper = time
 	return c
 }
 
-func (ff *FeatureFlagSDK) Listenner(ctx context.Context) (*FeatureFlagSDK, error) {
+// Start loads the flags and returns, the stream and the refresh keep them updated in background
+// until Close. ctx only bounds the initial load. When the server is unavailable it serves the
+// cache file or the bootstrap, and the flags are loaded from the server once it is back.
+func (ff *FeatureFlagSDK) Start(ctx context.Context) error {
+	if ff.cancel != nil {
+		return ErrAlreadyStarted
+	}
+
 	flags, err := ff.getAllFlags(ctx)
 	if err != nil {
-		return nil, err
+		offline, source, offlineErr := ff.loadOffline()
+		if offlineErr != nil {
+			return errors.Join(err, offlineErr)
+		}
+
+		ff.logger.Warn("featureflag server unavailable, serving offline flags", "source", source, "error", err)
+		flags = offline
+		ff.cached.Store(true)
 	}
 
+	ff.mu.Lock()
 	ff.inMemoryFlags = flags
+	ff.mu.Unlock()
+	ff.persist()
 
-	// Configurar context com cancelamento
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
-
-	// Capturar sinais de interrupção
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	ctx, ff.cancel = context.WithCancel(context.WithoutCancel(ctx))
 
+	ff.wg.Add(3)
 	go func() {
-		<-sigChan
-		fmt.Println("\n🛑 Encerrando cliente...")
-		cancel()
+		defer ff.wg.Done()
+		ff.changes.Run(ctx)
 	}()
 
-	// Iniciar refresh em background
-	go ff.refresh(ctx)
+	go func() {
+		defer ff.wg.Done()
+		ff.refresh(ctx)
+	}()
 
-	//FICAR ESCUTANDO EVENTOS (SSE)
-	// Cliente com timeout apenas para verificar se o servidor está rodando
-	clientWithTimeout := &http.Client{Timeout: 5 * time.Second}
-	_, err = clientWithTimeout.Get(ff.host)
-	if err != nil {
-		fmt.Println("❌ Servidor não está rodando!")
-		fmt.Println("💡 Inicie o servidor primeiro: go run ./cmd/server")
-		return nil, err
-	}
+	go func() {
+		defer ff.wg.Done()
+		ff.listen(ctx)
+	}()
 
-	// Cliente sem timeout para a conexão SSE (que precisa ficar aberta)
-	sseClient := &http.Client{}
+	return nil
+}
 
-	serverUrl := fmt.Sprintf("%s/events/featureflag", ff.host)
-	req, err := http.NewRequestWithContext(ctx, "GET", serverUrl, nil)
-	if err != nil {
-		log.Fatal("Erro ao criar request:", err)
+// Close stops the stream and the refresh, the flags already loaded are still served
+func (ff *FeatureFlagSDK) Close() {
+	if ff.cancel == nil {
+		return
 	}
 
-	req.Header.Set("Accept", "text/event-stream")
-	req.Header.Set("Cache-Control", "no-cache")
+	ff.cancel()
+	ff.wg.Wait()
+	ff.setState(StateClosed)
+}
 
-	resp, err := sseClient.Do(req)
-	if err != nil {
-		log.Fatal("Erro ao conectar:", err)
+// Deprecated: use Start and Close. Listenner starts the sdk and blocks until ctx is done.
+func (ff *FeatureFlagSDK) Listenner(ctx context.Context) (*FeatureFlagSDK, error) {
+	if err := ff.Start(ctx); err != nil {
+		return nil, err
 	}
 
-	defer resp.Body.Close()
+	<-ctx.Done()
+	ff.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		log.Fatalf("Status inválido: %d", resp.StatusCode)
-	}
+	return ff, nil
+}
 
-	scanner := bufio.NewScanner(resp.Body)
-	done := make(chan bool)
-	go func() {
-		defer func() { done <- true }()
+// listen keeps the stream connected, reconnecting with backoff when it ends. Events may be lost
+// while disconnected, so the flags are resynced before every reconnection, and before the first
+// connection when they were loaded offline.
+func (ff *FeatureFlagSDK) listen(ctx context.Context) {
+	attempt := 0
+	resynced := !ff.Cached()
+
+	var err error
+	for {
+		for !resynced {
+			ff.setState(StatePollingFallback)
+			delay := ff.backoff.Delay(attempt)
+			attempt++
+			ff.logger.Warn("featureflag stream disconnected, reconnecting", "in", delay, "error", err)
 
-		for scanner.Scan() {
 			select {
 			case <-ctx.Done():
 				return
-			default:
+			case <-time.After(delay):
 			}
 
-			line := strings.TrimSpace(scanner.Text())
+			ff.setState(StateConnecting)
+			err = ff.resync(ctx)
+			resynced = err == nil
+		}
 
-			if data, ok := strings.CutPrefix(line, "data: "); ok {
+		ff.setState(StateConnecting)
+		err = ff.stream(ctx)
+		if ctx.Err() != nil {
+			return
+		}
 
-				var flag Flag
-				if err := json.Unmarshal([]byte(data), &flag); err == nil {
-					fmt.Printf("📦 Feature Flag recebida:\n")
-					fmt.Println()
-					fmt.Printf("%+v\n", flag)
-					fmt.Println()
+		// the stream was up, the next reconnection starts from the minimum delay
+		if ff.State() == StateStreaming {
+			attempt = 0
+		}
+
+		resynced = false
+	}
+}
+
+// stream connects with the configured transport and applies the events until the connection ends.
+// When an event was already received it sends Last-Event-ID, so the server replays
+// what was missed or answers with a snapshot.
+func (ff *FeatureFlagSDK) stream(ctx context.Context) error {
+	reader, err := ff.connect(ctx)
+	if err != nil {
+		return err
+	}
 
-					ff.inMemoryFlags[flag.FlagName] = flag
-					for _, v := range ff.inMemoryFlags {
-						fmt.Printf("%+v\n", v)
-					}
-					continue
-				}
+	defer reader.Close()
+	ff.setState(StateStreaming)
 
-				// Se não conseguir parsear, mostrar raw data
-				fmt.Printf("📨 Mensagem recebida:\n")
-				fmt.Printf("   %s\n", data)
+	for {
+		event, err := reader.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) || ctx.Err() != nil {
+				return nil
 			}
+			return err
 		}
 
-		if err := scanner.Err(); err != nil {
-			fmt.Printf("❌ Erro ao ler stream: %v\n", err)
+		if err := ff.apply(event); err != nil {
+			ff.logger.Warn("skipping invalid featureflag event", "id", event.ID, "data", event.Data, "error", err)
+			continue
 		}
-	}()
 
-	select {
-	case <-ctx.Done():
-		fmt.Println("🔌 Conexão cancelada pelo usuário")
-	case <-done:
-		fmt.Println("🔌 Conexão encerrada")
+		ff.lastEventID = event.ID
 	}
+}
 
-	return ff, nil
+// apply updates the in-memory flags with a single flag event, a delete or a full snapshot
+func (ff *FeatureFlagSDK) apply(event sse.Event) error {
+	ff.markSynced()
+
+	if event.Event == snapshotEvent {
+		var flags []Flag
+		if err := json.Unmarshal([]byte(event.Data), &flags); err != nil {
+			return err
+		}
+
+		serverFlags := make(map[string]Flag)
+		for _, flag := range flags {
+			serverFlags[flag.FlagName] = flag
+		}
+
+		changes := ff.merge(serverFlags)
+		ff.logger.Info("featureflag snapshot received", "changed", len(changes))
+		ff.synced(changes)
+		return nil
+	}
+
+	if event.Event == deletedEvent {
+		var d deleted
+		if err := json.Unmarshal([]byte(event.Data), &d); err != nil {
+			return err
+		}
+
+		ff.logger.Debug("featureflag deleted", "flag", d.Key)
+
+		ff.mu.Lock()
+		old, ok := ff.inMemoryFlags[d.Key]
+		delete(ff.inMemoryFlags, d.Key)
+		ff.mu.Unlock()
+
+		if ok {
+			ff.synced([]change{{old: old}})
+		}
+		return nil
+	}
+
+	var flag Flag
+	if err := json.Unmarshal([]byte(event.Data), &flag); err != nil {
+		return err
+	}
+
+	ff.logger.Debug("featureflag received", "flag", flag.FlagName, "active", flag.Active)
+
+	ff.mu.Lock()
+	if ff.inMemoryFlags == nil {
+		ff.inMemoryFlags = make(map[string]Flag)
+	}
+	old, ok := ff.inMemoryFlags[flag.FlagName]
+	ff.inMemoryFlags[flag.FlagName] = flag
+	ff.mu.Unlock()
+
+	// the server also sends the flags saved without changes
+	if ok && !hasChanged(old, flag) {
+		return nil
+	}
+
+	ff.synced([]change{{old: old, new: flag}})
+	return nil
+}
+
+type eventReader interface {
+	Next() (sse.Event, error)
+	Close() error
+}
+
+func (ff *FeatureFlagSDK) connect(ctx context.Context) (eventReader, error) {
+	header := ff.http.Headers()
+	if ff.lastEventID != "" {
+		header.Set("Last-Event-ID", ff.lastEventID)
+	}
+
+	if ff.transport == TransportWebSocket {
+		return ws.Dial(ctx, httpclient.Dialer(ff.client, ff.http), ff.host, "featureflag", header)
+	}
+
+	serverUrl := fmt.Sprintf("%s/events/featureflag", ff.host)
+	header.Set("Accept", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+
+	resp, err := httpclient.Stream(ctx, ff.client, ff.http, serverUrl, header)
+	if err != nil {
+		return nil, fmt.Errorf("error on connect: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("invalid status: %d", resp.StatusCode)
+	}
+
+	return sseReader{Reader: sse.NewReader(resp.Body), body: resp.Body}, nil
+}
+
+type sseReader struct {
+	*sse.Reader
+	body io.Closer
+}
+
+func (r sseReader) Close() error {
+	return r.body.Close()
 }
 
 type FFResponse struct {
@@ -165,8 +379,39 @@ func (fr FFResponse) Val() bool {
 	return fr.Bool
 }
 
-func (ff *FeatureFlagSDK) GetFeatureFlag(key string, sessionID ...string) FFResponse {
+// Flag returns the cached flag, without evaluating its strategy
+func (ff *FeatureFlagSDK) Flag(key string) (Flag, bool) {
+	ff.mu.RLock()
+	defer ff.mu.RUnlock()
+
 	flag, ok := ff.inMemoryFlags[key]
+	return flag, ok
+}
+
+// GetFeatureFlag evaluates key for the sessionID, it is Evaluate with sessionID as the targeting key
+func (ff *FeatureFlagSDK) GetFeatureFlag(key string, sessionID ...string) FFResponse {
+	if len(sessionID) > 0 {
+		return ff.Evaluate(key, evalctx.New(sessionID[0]))
+	}
+
+	return ff.Evaluate(key, EvalContext{})
+}
+
+// EvaluateContext is Evaluate with the EvalContext carried by ctx, see evalctx.NewContext.
+// The hooks receive ctx, so they can record the evaluation in the span of the caller.
+func (ff *FeatureFlagSDK) EvaluateContext(ctx context.Context, key string) FFResponse {
+	return ff.hooked(ctx, key, evalctx.FromContext(ctx))
+}
+
+// Evaluate is safe for concurrent use, the flags with strategy are evaluated under the write lock
+// as their call counter is kept in the cache. The strategy uses the targeting key as the sessionID,
+// without it the flag is balanced by its percentage.
+func (ff *FeatureFlagSDK) Evaluate(key string, ec EvalContext) FFResponse {
+	return ff.hooked(context.Background(), key, ec)
+}
+
+func (ff *FeatureFlagSDK) evaluate(key string, ec EvalContext) FFResponse {
+	flag, ok := ff.Flag(key)
 
 	if !ok {
 		return FFResponse{ff.ffDefault, ErrNotFoundFeatureFlag}
@@ -177,25 +422,42 @@ func (ff *FeatureFlagSDK) GetFeatureFlag(key string, sessionID ...string) FFResp
 		return FFResponse{flag.Active, nil}
 	}
 
-	if len(sessionID) > 0 {
-		updatedFlag := flag.ValidateStrategy(sessionID[0]).Increment()
-		ff.inMemoryFlags[key] = updatedFlag
-		return FFResponse{updatedFlag.Active, nil}
+	ff.mu.Lock()
+	defer ff.mu.Unlock()
+
+	// the flag may have changed between the locks
+	flag, ok = ff.inMemoryFlags[key]
+	if !ok {
+		return FFResponse{ff.ffDefault, ErrNotFoundFeatureFlag}
 	}
 
-	updatedFlag := flag.Balancer()
-	ff.inMemoryFlags[key] = updatedFlag
+	var updatedFlag Flag
+	if ec.TargetingKey != "" {
+		updatedFlag = flag.ValidateStrategy(ec.TargetingKey).Increment()
+	} else {
+		updatedFlag = flag.Balancer()
+	}
+
+	// only the call counter is kept, Active stays the one of the server so that the next
+	// snapshot or poll compares against it and doesn't report the evaluation as a change
+	flag.Strategy.QtdCall = updatedFlag.Strategy.QtdCall
+	ff.inMemoryFlags[key] = flag
+
 	return FFResponse{updatedFlag.Active, nil}
 }
 
-func (ff FeatureFlagSDK) getAllFlags(ctx context.Context) (map[string]Flag, error) {
-	resp, err := http.Get(fmt.Sprintf("%s/featureflags", ff.host))
+func (ff *FeatureFlagSDK) getAllFlags(ctx context.Context) (map[string]Flag, error) {
+	resp, err := httpclient.Get(ctx, ff.client, ff.http, fmt.Sprintf("%s/featureflags", ff.host))
 	if err != nil {
 		return nil, fmt.Errorf("error on get features flags :%w", err)
 	}
 
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("invalid status: %d", resp.StatusCode)
+	}
+
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("error on io read all, %w", err)
@@ -206,6 +468,9 @@ func (ff FeatureFlagSDK) getAllFlags(ctx context.Context) (map[string]Flag, erro
 		return nil, fmt.Errorf("error on decode json: %w", err)
 	}
 
+	ff.markSynced()
+	ff.setRevision(revisionOf(resp.Header.Get("ETag")))
+
 	output := make(map[string]Flag)
 	for _, flag := range flags {
 		output[flag.FlagName] = flag
@@ -264,6 +529,29 @@ func filterChangedFlags(serverFlags, memoryFlags map[string]Flag) map[string]Fla
 	return changedFlags
 }
 
+// merge applies the server flags to the cache and returns the flags added, changed and removed
+func (ff *FeatureFlagSDK) merge(serverFlags map[string]Flag) []change {
+	ff.mu.Lock()
+	defer ff.mu.Unlock()
+
+	changedFlags := filterChangedFlags(serverFlags, ff.inMemoryFlags)
+
+	var changes []change
+	for name, flag := range changedFlags {
+		changes = append(changes, change{old: ff.inMemoryFlags[name], new: flag})
+	}
+
+	for name, flag := range ff.inMemoryFlags {
+		if _, ok := serverFlags[name]; !ok {
+			changes = append(changes, change{old: flag})
+		}
+	}
+
+	ff.inMemoryFlags = mergeFlags(ff.inMemoryFlags, serverFlags, changedFlags)
+
+	return changes
+}
+
 // mergeFlags merges changed flags with in-memory flags,
 // also removes flags that were deleted on the server.
 func mergeFlags(memoryFlags, serverFlags, changedFlags map[string]Flag) map[string]Flag {
@@ -287,30 +575,34 @@ func (ff *FeatureFlagSDK) refresh(ctx context.Context) {
 	ticker := time.NewTicker(ff.sleeper)
 	defer ticker.Stop()
 
-	fmt.Printf("🔄 Refresh started - updating flags every %v\n", ff.sleeper)
-
 	for {
 		select {
 		case <-ctx.Done():
-			fmt.Println("🛑 Refresh stopped")
 			return
 		case <-ticker.C:
-			serverFlags, err := ff.getAllFlags(ctx)
-			if err != nil {
-				fmt.Printf("error on refresh flags: %v\n", err)
-				continue
+			if err := ff.poll(ctx); err != nil && ctx.Err() == nil {
+				ff.logger.Error("error on refresh featureflags", "error", err)
 			}
+		}
+	}
+}
 
-			// Filter only the flags that changed
-			changedFlags := filterChangedFlags(serverFlags, ff.inMemoryFlags)
+// resync loads all flags from the server and applies the ones that changed
+func (ff *FeatureFlagSDK) resync(ctx context.Context) error {
+	serverFlags, err := ff.getAllFlags(ctx)
+	if err != nil {
+		return err
+	}
 
-			if len(changedFlags) > 0 {
-				// Merge changed flags with in-memory flags
-				ff.inMemoryFlags = mergeFlags(ff.inMemoryFlags, serverFlags, changedFlags)
-				fmt.Printf("✅ %d flag(s) updated via refresh\n", len(changedFlags))
-			} else {
-				fmt.Println("ℹ️  No changes detected on refresh")
-			}
-		}
+	wasCached := ff.cached.Swap(false)
+
+	changes := ff.merge(serverFlags)
+	if len(changes) > 0 {
+		ff.logger.Info("featureflags updated by resync", "changed", len(changes))
+		ff.synced(changes)
+	} else if wasCached {
+		ff.persist()
 	}
+
+	return nil
 }
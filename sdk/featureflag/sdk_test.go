@@ -3,11 +3,23 @@ package featureflag
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/IsaacDSC/featureflag/sdk/backoff"
+	"github.com/IsaacDSC/featureflag/sdk/evalctx"
+	"github.com/IsaacDSC/featureflag/sdk/httpclient"
+	"github.com/IsaacDSC/featureflag/sdk/sse"
 	"github.com/IsaacDSC/featureflag/sdk/stg"
+	"github.com/gorilla/websocket"
 )
 
 func TestNewFeatureFlagSDK(t *testing.T) {
@@ -1227,3 +1239,711 @@ func TestMergeFlags(t *testing.T) {
 		}
 	})
 }
+
+func TestFeatureFlagSDK_stream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/events/featureflag" {
+			t.Errorf("Expected path /events/featureflag, got %s", r.URL.Path)
+		}
+
+		if got := r.Header.Get("Last-Event-ID"); got != "1-0" {
+			t.Errorf("Last-Event-ID = %q, want %q", got, "1-0")
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("id: 5-0\nevent: snapshot\ndata: [{\"flag_name\":\"feature-a\",\"active\":true},{\"flag_name\":\"feature-b\",\"active\":false}]\n\n"))
+		w.Write([]byte("id: 6-0\ndata: {\"flag_name\":\"feature-b\",\"active\":true}\n\n"))
+	}))
+	defer server.Close()
+
+	sdk := NewFeatureFlagSDK(server.URL)
+	sdk.lastEventID = "1-0"
+	sdk.inMemoryFlags = map[string]Flag{
+		"feature-a": {FlagName: "feature-a", Active: false},
+		"removed":   {FlagName: "removed", Active: true},
+	}
+
+	if err := sdk.stream(context.Background()); err != nil {
+		t.Fatalf("stream() error = %v", err)
+	}
+
+	if sdk.lastEventID != "6-0" {
+		t.Errorf("lastEventID = %q, want %q", sdk.lastEventID, "6-0")
+	}
+
+	if len(sdk.inMemoryFlags) != 2 {
+		t.Errorf("inMemoryFlags has %d flags, want 2", len(sdk.inMemoryFlags))
+	}
+
+	if !sdk.inMemoryFlags["feature-a"].Active || !sdk.inMemoryFlags["feature-b"].Active {
+		t.Errorf("inMemoryFlags = %+v, want feature-a and feature-b active", sdk.inMemoryFlags)
+	}
+
+	if _, ok := sdk.inMemoryFlags["removed"]; ok {
+		t.Error("flag removed on server should be removed by snapshot")
+	}
+}
+
+func TestFeatureFlagSDK_stream_WebSocket(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ws/featureflag" {
+			t.Errorf("Expected path /ws/featureflag, got %s", r.URL.Path)
+		}
+
+		if got := r.Header.Get("Last-Event-ID"); got != "1-0" {
+			t.Errorf("Last-Event-ID = %q, want %q", got, "1-0")
+		}
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("Upgrade() error = %v", err)
+			return
+		}
+		defer conn.Close()
+
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"id":"2-0","data":{"flag_name":"feature-a","active":true}}`))
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	}))
+	defer server.Close()
+
+	sdk := NewFeatureFlagSDK(server.URL).WithTransport(TransportWebSocket)
+	sdk.lastEventID = "1-0"
+	sdk.inMemoryFlags = map[string]Flag{}
+
+	if err := sdk.stream(context.Background()); err != nil {
+		t.Fatalf("stream() error = %v", err)
+	}
+
+	if sdk.lastEventID != "2-0" {
+		t.Errorf("lastEventID = %q, want %q", sdk.lastEventID, "2-0")
+	}
+
+	if !sdk.inMemoryFlags["feature-a"].Active {
+		t.Errorf("inMemoryFlags = %+v, want feature-a active", sdk.inMemoryFlags)
+	}
+}
+
+func TestFeatureFlagSDK_StartClose(t *testing.T) {
+	events := make(chan string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/featureflags":
+			w.Write([]byte(`[{"flag_name":"balanced","active":true,"strategy":{"with_strategy":true,"percent":50}}]`))
+		case "/events/featureflag":
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.(http.Flusher).Flush()
+			for {
+				select {
+				case data := <-events:
+					fmt.Fprintf(w, "data: %s\n\n", data)
+					w.(http.Flusher).Flush()
+				case <-r.Context().Done():
+					return
+				}
+			}
+		}
+	}))
+	defer server.Close()
+
+	sdk := NewFeatureFlagSDK(server.URL).WithEventualConsistency(time.Millisecond)
+	if err := sdk.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	if err := sdk.Start(context.Background()); err != ErrAlreadyStarted {
+		t.Errorf("second Start() error = %v, want %v", err, ErrAlreadyStarted)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				sdk.GetFeatureFlag("balanced")
+				sdk.GetFeatureFlag("balanced", "session")
+				sdk.GetFeatureFlag("single")
+			}
+		}()
+	}
+
+	for i := 0; i < 10; i++ {
+		events <- fmt.Sprintf(`{"flag_name":"single","active":%t}`, i%2 == 0)
+	}
+	wg.Wait()
+
+	done := make(chan struct{})
+	go func() {
+		sdk.Close()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Close() did not stop the stream")
+	}
+
+	if _, ok := sdk.Flag("balanced"); !ok {
+		t.Error("flags must still be served after Close")
+	}
+}
+
+func TestFeatureFlagSDK_Start_Error(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	sdk := NewFeatureFlagSDK(server.URL)
+	if err := sdk.Start(context.Background()); err == nil {
+		t.Error("Start() error = nil with the server down")
+	}
+
+	sdk.Close()
+}
+
+func TestFeatureFlagSDK_Reconnect(t *testing.T) {
+	var loads, connections atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/featureflags":
+			// the flag changes while the stream is down
+			active := loads.Add(1) > 1
+			fmt.Fprintf(w, `[{"flag_name":"a","active":%t}]`, active)
+		case "/events/featureflag":
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.(http.Flusher).Flush()
+			if connections.Add(1) == 1 {
+				return
+			}
+			<-r.Context().Done()
+		}
+	}))
+	defer server.Close()
+
+	var mu sync.Mutex
+	var states []State
+	streaming := make(chan struct{}, 2)
+
+	sdk := NewFeatureFlagSDK(server.URL).
+		WithReconnectBackoff(backoff.Backoff{Min: 10 * time.Millisecond, Max: 10 * time.Millisecond}).
+		WithStateHandler(func(state State) {
+			mu.Lock()
+			states = append(states, state)
+			mu.Unlock()
+
+			if state == StateStreaming {
+				streaming <- struct{}{}
+			}
+		})
+
+	if err := sdk.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-streaming:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("stream not connected %d times", i+1)
+		}
+	}
+
+	if !sdk.GetFeatureFlag("a").Val() {
+		t.Error("flags were not resynced before reconnecting")
+	}
+
+	if sdk.State() != StateStreaming {
+		t.Errorf("State() = %s, want %s", sdk.State(), StateStreaming)
+	}
+
+	sdk.Close()
+
+	want := []State{StateConnecting, StateStreaming, StatePollingFallback, StateConnecting, StateStreaming, StateClosed}
+	mu.Lock()
+	defer mu.Unlock()
+	if fmt.Sprint(states) != fmt.Sprint(want) {
+		t.Errorf("states = %v, want %v", states, want)
+	}
+}
+
+func TestFeatureFlagSDK_Start_Offline(t *testing.T) {
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	t.Run("should serve the embedded bootstrap", func(t *testing.T) {
+		sdk := NewFeatureFlagSDK(down.URL).WithBootstrap([]byte(`[{"flag_name":"a","active":true}]`))
+		if err := sdk.Start(context.Background()); err != nil {
+			t.Fatalf("Start() error = %v", err)
+		}
+		defer sdk.Close()
+
+		if !sdk.Cached() || !sdk.GetFeatureFlag("a").Val() {
+			t.Errorf("Cached() = %v, flag a = %v, want both true", sdk.Cached(), sdk.GetFeatureFlag("a").Val())
+		}
+	})
+
+	t.Run("should prefer the cache file to the bootstrap file", func(t *testing.T) {
+		dir := t.TempDir()
+		cacheFile := filepath.Join(dir, "cache.json")
+		bootstrapFile := filepath.Join(dir, "bootstrap.json")
+		os.WriteFile(cacheFile, []byte(`[{"flag_name":"a","active":true}]`), 0o644)
+		os.WriteFile(bootstrapFile, []byte(`[{"flag_name":"a","active":false}]`), 0o644)
+
+		sdk := NewFeatureFlagSDK(down.URL).WithCacheFile(cacheFile).WithBootstrapFile(bootstrapFile)
+		if err := sdk.Start(context.Background()); err != nil {
+			t.Fatalf("Start() error = %v", err)
+		}
+		defer sdk.Close()
+
+		if !sdk.GetFeatureFlag("a").Val() {
+			t.Error("flag a = false, want the value of the cache file")
+		}
+	})
+
+	t.Run("should fail without offline source", func(t *testing.T) {
+		sdk := NewFeatureFlagSDK(down.URL).WithBootstrapFile(filepath.Join(t.TempDir(), "missing.json"))
+		if err := sdk.Start(context.Background()); err == nil {
+			t.Error("Start() error = nil, want error")
+		}
+	})
+}
+
+func TestFeatureFlagSDK_CacheFile(t *testing.T) {
+	var up atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !up.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/featureflags":
+			w.Write([]byte(`[{"flag_name":"a","active":true}]`))
+		case "/events/featureflag":
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}
+	}))
+	defer server.Close()
+
+	cacheFile := filepath.Join(t.TempDir(), "cache.json")
+	streaming := make(chan struct{}, 1)
+
+	sdk := NewFeatureFlagSDK(server.URL).
+		WithBootstrap([]byte(`[{"flag_name":"a","active":false}]`)).
+		WithCacheFile(cacheFile).
+		WithReconnectBackoff(backoff.Backoff{Min: 10 * time.Millisecond, Max: 10 * time.Millisecond}).
+		WithStateHandler(func(state State) {
+			if state == StateStreaming {
+				streaming <- struct{}{}
+			}
+		})
+
+	if err := sdk.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer sdk.Close()
+
+	if _, err := os.Stat(cacheFile); !os.IsNotExist(err) {
+		t.Errorf("cache file written with offline flags, stat error = %v", err)
+	}
+
+	up.Store(true)
+
+	select {
+	case <-streaming:
+	case <-time.After(2 * time.Second):
+		t.Fatal("stream not connected after the server is back")
+	}
+
+	if sdk.Cached() || !sdk.GetFeatureFlag("a").Val() {
+		t.Errorf("Cached() = %v, flag a = %v, want live flags", sdk.Cached(), sdk.GetFeatureFlag("a").Val())
+	}
+
+	b, err := os.ReadFile(cacheFile)
+	if err != nil {
+		t.Fatalf("error on read cache file: %v", err)
+	}
+
+	flags, err := decodeFlags(b)
+	if err != nil || !flags["a"].Active {
+		t.Errorf("cache file = %s, want the flags of the server", b)
+	}
+}
+
+func TestFeatureFlagSDK_OnChange(t *testing.T) {
+	events := make(chan string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/featureflags":
+			w.Write([]byte(`[{"flag_name":"a","active":false},{"flag_name":"b","active":true}]`))
+		case "/events/featureflag":
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.(http.Flusher).Flush()
+			for {
+				select {
+				case data := <-events:
+					fmt.Fprint(w, data)
+					w.(http.Flusher).Flush()
+				case <-r.Context().Done():
+					return
+				}
+			}
+		}
+	}))
+	defer server.Close()
+
+	sdk := NewFeatureFlagSDK(server.URL)
+
+	release := make(chan struct{})
+	changedA := make(chan [2]Flag, 10)
+	sdk.OnChange("a", func(old, new Flag) {
+		// a slow callback must not block the stream
+		<-release
+		changedA <- [2]Flag{old, new}
+	})
+
+	var mu sync.Mutex
+	var all []string
+	sdk.OnAnyChange(func(old, new Flag) {
+		mu.Lock()
+		defer mu.Unlock()
+		all = append(all, fmt.Sprintf("%s:%t->%s:%t", old.FlagName, old.Active, new.FlagName, new.Active))
+	})
+
+	if err := sdk.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer sdk.Close()
+
+	events <- "data: {\"flag_name\":\"a\",\"active\":false}\n\n"
+	events <- "data: {\"flag_name\":\"a\",\"active\":true}\n\n"
+	events <- "event: snapshot\ndata: [{\"flag_name\":\"a\",\"active\":true},{\"flag_name\":\"c\",\"active\":true}]\n\n"
+	events <- "data: {\"flag_name\":\"sync\"}\n\n"
+
+	// the last event is applied while the callback of the first change is still blocked
+	deadline := time.Now().Add(2 * time.Second)
+	for _, ok := sdk.Flag("sync"); !ok; _, ok = sdk.Flag("sync") {
+		if time.Now().After(deadline) {
+			t.Fatal("the stream was blocked by the callback")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+
+	select {
+	case got := <-changedA:
+		if got[0].Active || !got[1].Active {
+			t.Errorf("OnChange(a) old = %+v, new = %+v, want false -> true", got[0], got[1])
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting OnChange(a)")
+	}
+
+	select {
+	case got := <-changedA:
+		t.Errorf("OnChange(a) called again with %+v", got)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	want := []string{"a:false->a:true", "b:true->:false", ":false->c:true", ":false->sync:false"}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(all) != len(want) {
+		t.Fatalf("OnAnyChange() calls = %v, want %v", all, want)
+	}
+
+	// the changes of a snapshot have no order
+	for _, w := range want {
+		found := false
+		for _, got := range all {
+			found = found || got == w
+		}
+		if !found {
+			t.Errorf("OnAnyChange() calls = %v, want %v", all, want)
+		}
+	}
+}
+
+func TestFeatureFlagSDK_OnChange_Evaluated(t *testing.T) {
+	sdk := NewFeatureFlagSDK("http://localhost:8080")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go sdk.changes.Run(ctx)
+
+	snapshot := sse.Event{Event: snapshotEvent, Data: `[{"flag_name":"a","active":false,"strategy":{"with_strategy":true,"session_id":{"user-1":true}}},{"flag_name":"b","active":false,"strategy":{"with_strategy":true,"percent":50}}]`}
+	if err := sdk.apply(snapshot); err != nil {
+		t.Fatalf("apply() error = %v", err)
+	}
+
+	var calls atomic.Int32
+	sdk.OnAnyChange(func(old, new Flag) { calls.Add(1) })
+
+	// the evaluations activate both flags for their callers, not on the server
+	if !sdk.GetFeatureFlag("a", "user-1").Val() {
+		t.Error("GetFeatureFlag(a, user-1) = false, want true")
+	}
+	for range 100 {
+		sdk.GetFeatureFlag("b")
+	}
+
+	if err := sdk.apply(snapshot); err != nil {
+		t.Fatalf("apply() error = %v", err)
+	}
+	if err := sdk.apply(sse.Event{Data: `{"flag_name":"a","active":false,"strategy":{"with_strategy":true,"session_id":{"user-1":true}}}`}); err != nil {
+		t.Fatalf("apply() error = %v", err)
+	}
+
+	sdk.changes.Push(cancel)
+	<-ctx.Done()
+
+	if got := calls.Load(); got != 0 {
+		t.Errorf("OnAnyChange() called %d times for the same flags", got)
+	}
+}
+
+func TestFeatureFlagSDK_OnChange_Deleted(t *testing.T) {
+	sdk := NewFeatureFlagSDK("http://localhost:8080")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go sdk.changes.Run(ctx)
+
+	if err := sdk.apply(sse.Event{Data: `{"flag_name":"a","active":true}`}); err != nil {
+		t.Fatalf("apply() error = %v", err)
+	}
+
+	var calls []string
+	sdk.OnAnyChange(func(old, new Flag) {
+		calls = append(calls, fmt.Sprintf("%s:%t->%s:%t", old.FlagName, old.Active, new.FlagName, new.Active))
+	})
+
+	if err := sdk.apply(sse.Event{Event: deletedEvent, Data: `{"key":"a","revision":2}`}); err != nil {
+		t.Fatalf("apply() error = %v", err)
+	}
+	// a flag unknown to the sdk is ignored
+	if err := sdk.apply(sse.Event{Event: deletedEvent, Data: `{"key":"b","revision":3}`}); err != nil {
+		t.Fatalf("apply() error = %v", err)
+	}
+
+	sdk.changes.Push(cancel)
+	<-ctx.Done()
+
+	if _, ok := sdk.Flag("a"); ok {
+		t.Error("Flag(a) found after its delete")
+	}
+
+	if want := []string{"a:true->:false"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("OnAnyChange() calls = %v, want %v", calls, want)
+	}
+}
+
+func TestFeatureFlagSDK_EvaluateContext(t *testing.T) {
+	sdk := NewFeatureFlagSDK("http://localhost:8080")
+	sdk.inMemoryFlags = map[string]Flag{
+		"sessions": {
+			FlagName: "sessions",
+			Strategy: stg.Strategy[bool]{WithStrategy: true, SessionsID: map[string]bool{"user-1": true}},
+		},
+	}
+
+	ctx := evalctx.NewContext(context.Background(), evalctx.New("user-1").WithString("country", "BR"))
+	if got := sdk.EvaluateContext(ctx, "sessions"); !got.Bool || got.Error != nil {
+		t.Errorf("EvaluateContext() with targeting key in sessions = %+v, want true", got)
+	}
+
+	if got := sdk.Evaluate("sessions", evalctx.New("user-2")); got.Bool {
+		t.Errorf("Evaluate() with targeting key out of sessions = %+v, want false", got)
+	}
+
+	if got := sdk.GetFeatureFlag("sessions", "user-1"); !got.Bool {
+		t.Errorf("GetFeatureFlag() with sessionID in sessions = %+v, want true", got)
+	}
+
+	if got := sdk.EvaluateContext(context.Background(), "missing"); got.Error != ErrNotFoundFeatureFlag {
+		t.Errorf("EvaluateContext() error = %v, want %v", got.Error, ErrNotFoundFeatureFlag)
+	}
+}
+
+type countingTransport struct {
+	requests atomic.Int32
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests.Add(1)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestFeatureFlagSDK_Options(t *testing.T) {
+	var unavailable atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token" || r.Header.Get("X-Tenant") != "acme" ||
+			r.Header.Get("User-Agent") != "checkout/1.0 featureflag-sdk-go/"+httpclient.Version {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/featureflags":
+			if unavailable.CompareAndSwap(false, true) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte(`[{"flag_name":"on","active":true}]`))
+		case "/events/featureflag":
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.(http.Flusher).Flush()
+			<-r.Context().Done()
+		}
+	}))
+	defer server.Close()
+
+	streaming := make(chan struct{}, 1)
+	transport := &countingTransport{}
+	sdk := NewFeatureFlagSDK(server.URL,
+		WithToken("token"),
+		WithHeader("X-Tenant", "acme"),
+		WithUserAgent("checkout/1.0"),
+		WithRoundTripper(transport),
+		WithTimeout(time.Second),
+		WithRetry(1, backoff.Backoff{Min: time.Millisecond, Max: time.Millisecond}),
+	).WithStateHandler(func(state State) {
+		if state == StateStreaming {
+			streaming <- struct{}{}
+		}
+	})
+
+	if err := sdk.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer sdk.Close()
+
+	select {
+	case <-streaming:
+	case <-time.After(2 * time.Second):
+		t.Fatal("sdk did not connect to the stream")
+	}
+
+	// the retried snapshot, the snapshot and the stream
+	if got := transport.requests.Load(); got != 3 {
+		t.Errorf("requests = %d, want 3", got)
+	}
+}
+
+func TestFeatureFlagSDK_Unauthorized(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	sdk := NewFeatureFlagSDK(server.URL)
+	if err := sdk.Start(context.Background()); err == nil {
+		sdk.Close()
+		t.Error("Start() error = nil with an unauthorized sdk")
+	}
+}
+
+func TestFeatureFlagSDK_poll(t *testing.T) {
+	var loads atomic.Int32
+	var since atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/featureflags":
+			loads.Add(1)
+			w.Header().Set("ETag", `"3"`)
+			w.Write([]byte(`[{"flag_name":"a","active":false},{"flag_name":"b","active":true}]`))
+		case "/featureflags/changes":
+			since.Store(r.URL.Query().Get("since"))
+			w.Write([]byte(`{"revision":5,"changed":[{"flag_name":"a","active":true},{"flag_name":"c","active":true}],"deleted":["b","unknown"]}`))
+		}
+	}))
+	defer server.Close()
+
+	sdk := NewFeatureFlagSDK(server.URL)
+	flags, err := sdk.getAllFlags(context.Background())
+	if err != nil {
+		t.Fatalf("getAllFlags() error = %v", err)
+	}
+	sdk.inMemoryFlags = flags
+
+	changes := sdk.mergeChanges(changesResponse{})
+	if len(changes) != 0 {
+		t.Errorf("mergeChanges() without changes = %d changes, want 0", len(changes))
+	}
+
+	sdk.setRevision("3")
+	if err := sdk.poll(context.Background()); err != nil {
+		t.Fatalf("poll() error = %v", err)
+	}
+
+	if got := since.Load(); got != "3" {
+		t.Errorf("since = %v, want the revision of the ETag", got)
+	}
+
+	if loads.Load() != 1 {
+		t.Errorf("loads = %d, poll must not load all flags", loads.Load())
+	}
+
+	if !sdk.GetFeatureFlag("a").Val() || !sdk.GetFeatureFlag("c").Val() {
+		t.Error("changed flags were not applied")
+	}
+
+	if _, ok := sdk.Flag("b"); ok {
+		t.Error("deleted flag is still served")
+	}
+
+	if got := sdk.getRevision(); got != "5" {
+		t.Errorf("revision = %s, want 5", got)
+	}
+}
+
+func TestFeatureFlagSDK_poll_Unsupported(t *testing.T) {
+	for _, status := range []int{http.StatusNotImplemented, http.StatusGone} {
+		t.Run(http.StatusText(status), func(t *testing.T) {
+			testPollResync(t, status)
+		})
+	}
+}
+
+func testPollResync(t *testing.T, status int) {
+	var loads atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/featureflags":
+			// the flag changes after the first load
+			fmt.Fprintf(w, `[{"flag_name":"a","active":%t}]`, loads.Add(1) > 1)
+		default:
+			w.WriteHeader(status)
+		}
+	}))
+	defer server.Close()
+
+	sdk := NewFeatureFlagSDK(server.URL)
+	flags, err := sdk.getAllFlags(context.Background())
+	if err != nil {
+		t.Fatalf("getAllFlags() error = %v", err)
+	}
+	sdk.inMemoryFlags = flags
+
+	if sdk.getRevision() != "" {
+		t.Errorf("revision = %q without ETag, want empty", sdk.getRevision())
+	}
+
+	sdk.setRevision("1")
+	if err := sdk.poll(context.Background()); err != nil {
+		t.Fatalf("poll() error = %v", err)
+	}
+
+	if loads.Load() != 2 || !sdk.GetFeatureFlag("a").Val() {
+		t.Error("poll must resync when the server can't answer the changes")
+	}
+}
+
+func TestRevisionOf(t *testing.T) {
+	for etag, want := range map[string]string{`"12"`: "12", `W/"12"`: "12", "": "", `"abc"`: "", "12": ""} {
+		if got := revisionOf(etag); got != want {
+			t.Errorf("revisionOf(%q) = %q, want %q", etag, got, want)
+		}
+	}
+}
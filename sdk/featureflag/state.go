@@ -0,0 +1,49 @@
+package featureflag
+
+import "github.com/IsaacDSC/featureflag/sdk/backoff"
+
+// State is the connection state of the stream
+type State string
+
+const (
+	// StateConnecting is set while connecting, including the resync made before every reconnection
+	StateConnecting State = "connecting"
+	// StateStreaming is set while the stream is connected
+	StateStreaming State = "streaming"
+	// StatePollingFallback is set while waiting to reconnect, only the refresh updates the cache
+	StatePollingFallback State = "polling-fallback"
+	// StateClosed is set by Close
+	StateClosed State = "closed"
+)
+
+// WithStateHandler registers fn to be called on every change of the connection state,
+// fn is called by the stream goroutine and by Close, so it must not block
+func (ff *FeatureFlagSDK) WithStateHandler(fn func(state State)) *FeatureFlagSDK {
+	ff.onState = fn
+	return ff
+}
+
+// WithReconnectBackoff replaces backoff.Default as the delay between the reconnections of the stream
+func (ff *FeatureFlagSDK) WithReconnectBackoff(b backoff.Backoff) *FeatureFlagSDK {
+	ff.backoff = b
+	return ff
+}
+
+// State returns the connection state of the stream, empty before Start
+func (ff *FeatureFlagSDK) State() State {
+	ff.stateMu.Lock()
+	defer ff.stateMu.Unlock()
+
+	return ff.state
+}
+
+func (ff *FeatureFlagSDK) setState(state State) {
+	ff.stateMu.Lock()
+	changed := ff.state != state
+	ff.state = state
+	ff.stateMu.Unlock()
+
+	if changed && ff.onState != nil {
+		ff.onState(state)
+	}
+}
@@ -0,0 +1,167 @@
+// Package httpclient sends the requests of the SDKs with their authentication, headers, timeout and retries.
+package httpclient
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/IsaacDSC/featureflag/sdk/backoff"
+	"github.com/gorilla/websocket"
+)
+
+// Version is the version of the SDKs, sent in the User-Agent
+const Version = "0.1.0"
+
+const product = "featureflag-sdk-go/" + Version
+
+// Config is the zero value of the SDKs built without constructor: no token, timeout or retries
+type Config struct {
+	// Token is sent as the Authorization header, as expected by middlewares.Authorization
+	Token  string
+	Header http.Header
+	// UserAgent is sent before the product of the SDK, e.g. "checkout/1.2 featureflag-sdk-go/0.1.0"
+	UserAgent string
+	// Timeout bounds each attempt of the requests, the streams have no timeout
+	Timeout time.Duration
+	// Retries is how many times a request is retried on network errors, 429 and 5xx
+	Retries int
+	Backoff backoff.Backoff
+}
+
+func Default() Config {
+	return Config{
+		Timeout: 10 * time.Second,
+		Retries: 2,
+		Backoff: backoff.Backoff{Min: 200 * time.Millisecond, Max: 2 * time.Second, Multiplier: 2, Jitter: 0.2},
+	}
+}
+
+// Headers returns the headers sent on every request
+func (c Config) Headers() http.Header {
+	header := c.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+
+	if c.Token != "" {
+		header.Set("Authorization", c.Token)
+	}
+
+	userAgent := product
+	if c.UserAgent != "" {
+		userAgent = c.UserAgent + " " + product
+	}
+	header.Set("User-Agent", userAgent)
+
+	return header
+}
+
+// Get requests url, retrying with backoff while the server is unavailable. The body of the response
+// must be closed, the timeout only ends after it.
+func Get(ctx context.Context, client *http.Client, cfg Config, url string) (*http.Response, error) {
+	return GetWithHeader(ctx, client, cfg, url, nil)
+}
+
+// GetWithHeader is Get with the headers of cfg plus header, as If-None-Match
+func GetWithHeader(ctx context.Context, client *http.Client, cfg Config, url string, header http.Header) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := get(ctx, client, cfg, url, header)
+		if attempt >= cfg.Retries || !retryable(resp, err) {
+			return resp, err
+		}
+
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(cfg.Backoff.Delay(attempt)):
+		}
+	}
+}
+
+func get(ctx context.Context, client *http.Client, cfg Config, url string, header http.Header) (*http.Response, error) {
+	cancel := context.CancelFunc(func() {})
+	if cfg.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, cfg.Timeout)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("error on create request: %w", err)
+	}
+
+	req.Header = cfg.Headers()
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	resp.Body = cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+}
+
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b cancelBody) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
+// Stream opens a long-lived request with the headers of cfg plus header, without timeout
+// and retries as the SDKs reconnect the streams themselves
+func Stream(ctx context.Context, client *http.Client, cfg Config, url string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error on create request: %w", err)
+	}
+
+	req.Header = cfg.Headers()
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	return client.Do(req)
+}
+
+// Dialer is the websocket dialer with the proxy and TLS configuration of the client transport,
+// when it is an *http.Transport
+func Dialer(client *http.Client, cfg Config) *websocket.Dialer {
+	dialer := *websocket.DefaultDialer
+	dialer.HandshakeTimeout = cfg.Timeout
+
+	transport, ok := client.Transport.(*http.Transport)
+	if client.Transport == nil {
+		transport, ok = http.DefaultTransport.(*http.Transport)
+	}
+
+	if ok {
+		dialer.Proxy = transport.Proxy
+		dialer.TLSClientConfig = transport.TLSClientConfig
+	}
+
+	return &dialer
+}
@@ -0,0 +1,96 @@
+package httpclient
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/IsaacDSC/featureflag/sdk/backoff"
+)
+
+func TestGet(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "token" {
+			t.Errorf("Authorization = %q, want %q", got, "token")
+		}
+
+		if got := r.Header.Get("X-Team"); got != "checkout" {
+			t.Errorf("X-Team = %q, want %q", got, "checkout")
+		}
+
+		if got, want := r.Header.Get("User-Agent"), "checkout/1.0 "+product; got != want {
+			t.Errorf("User-Agent = %q, want %q", got, want)
+		}
+
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	cfg := Config{
+		Token:     "token",
+		Header:    http.Header{"X-Team": {"checkout"}},
+		UserAgent: "checkout/1.0",
+		Timeout:   time.Second,
+		Retries:   2,
+		Backoff:   backoff.Backoff{Min: time.Millisecond},
+	}
+
+	resp, err := Get(context.Background(), &http.Client{}, cfg, server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != "ok" || calls.Load() != 3 {
+		t.Errorf("Get() = %d %q after %d calls, want 200 ok after 3", resp.StatusCode, body, calls.Load())
+	}
+}
+
+func TestGet_RetriesExhausted(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	resp, err := Get(context.Background(), &http.Client{}, Config{Retries: 1, Backoff: backoff.Backoff{Min: time.Millisecond}}, server.URL)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusBadGateway || calls.Load() != 2 {
+		t.Errorf("Get() = %d after %d calls, want 502 after 2", resp.StatusCode, calls.Load())
+	}
+}
+
+func TestGet_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	start := time.Now()
+	if _, err := Get(context.Background(), &http.Client{}, Config{Timeout: 20 * time.Millisecond}, server.URL); err == nil {
+		t.Fatal("Get() error = nil, want timeout")
+	}
+
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Get() took %v with a timeout of 20ms", elapsed)
+	}
+}
@@ -0,0 +1,52 @@
+package notify
+
+import (
+	"context"
+	"sync"
+)
+
+// Queue calls the functions pushed in order on the goroutine of Run, so the callbacks of the SDKs
+// never block the stream. Push never blocks, the queue grows while a callback is slow.
+type Queue struct {
+	mu   sync.Mutex
+	fns  []func()
+	wake chan struct{}
+}
+
+func NewQueue() *Queue {
+	return &Queue{wake: make(chan struct{}, 1)}
+}
+
+func (q *Queue) Push(fn func()) {
+	q.mu.Lock()
+	q.fns = append(q.fns, fn)
+	q.mu.Unlock()
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run calls the functions pushed until ctx is done, the ones still pending are dropped
+func (q *Queue) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-q.wake:
+		}
+
+		q.mu.Lock()
+		fns := q.fns
+		q.fns = nil
+		q.mu.Unlock()
+
+		for _, fn := range fns {
+			if ctx.Err() != nil {
+				return
+			}
+			fn()
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package notify
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestQueue_Run(t *testing.T) {
+	queue := NewQueue()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	got := make(chan int, 100)
+	release := make(chan struct{})
+
+	// pushed before Run, blocks the queue until released
+	queue.Push(func() { <-release })
+	go queue.Run(ctx)
+
+	for i := 0; i < 100; i++ {
+		queue.Push(func() { got <- i })
+	}
+	close(release)
+
+	for want := 0; want < 100; want++ {
+		select {
+		case i := <-got:
+			if i != want {
+				t.Fatalf("called %d, want %d", i, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting call %d", want)
+		}
+	}
+}
+
+func TestQueue_Run_Cancel(t *testing.T) {
+	queue := NewQueue()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		queue.Run(ctx)
+		close(done)
+	}()
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run() did not return after cancel")
+	}
+
+	queue.Push(func() { t.Error("called after cancel") })
+}
@@ -0,0 +1,16 @@
+module github.com/IsaacDSC/featureflag/sdk/openfeature
+
+go 1.22.0
+
+require (
+	github.com/IsaacDSC/featureflag v0.0.0
+	github.com/open-feature/go-sdk v1.14.1
+)
+
+require (
+	github.com/go-logr/logr v1.4.2 // indirect
+	github.com/gorilla/websocket v1.5.3 // indirect
+	golang.org/x/exp v0.0.0-20240506185415-9bf2ced13842 // indirect
+)
+
+replace github.com/IsaacDSC/featureflag => ../..
@@ -0,0 +1,234 @@
+// Package openfeature is an OpenFeature provider for the featureflag server, backed by sdk/featureflag.
+//
+// It lives in its own module so the OpenFeature dependency is only pulled by the services that use it.
+package openfeature
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/IsaacDSC/featureflag/sdk/evalctx"
+	"github.com/IsaacDSC/featureflag/sdk/featureflag"
+	of "github.com/open-feature/go-sdk/openfeature"
+)
+
+const Name = "featureflag"
+
+const (
+	VariantOn  = "on"
+	VariantOff = "off"
+)
+
+const eventBuffer = 64
+
+var (
+	_ of.FeatureProvider = (*Provider)(nil)
+	_ of.StateHandler    = (*Provider)(nil)
+	_ of.EventHandler    = (*Provider)(nil)
+)
+
+// Provider evaluates boolean flags with the targeting key of the evaluation context as the sessionID.
+// Init returns once the flags are loaded (OpenFeature then emits PROVIDER_READY) and every change
+// received from the stream or the refresh emits PROVIDER_CONFIGURATION_CHANGED. PROVIDER_STALE is
+// emitted while the stream is reconnecting, and PROVIDER_READY again once it is back.
+type Provider struct {
+	sdk    *featureflag.FeatureFlagSDK
+	events chan of.Event
+	ready  chan struct{}
+	once   sync.Once
+	stale  bool
+}
+
+// NewProvider registers the provider as the state handler of sdk, sdk must not be started yet
+func NewProvider(sdk *featureflag.FeatureFlagSDK) *Provider {
+	p := &Provider{
+		sdk:    sdk,
+		events: make(chan of.Event, eventBuffer),
+		ready:  make(chan struct{}),
+	}
+
+	sdk.WithStateHandler(p.stateChanged).OnAnyChange(p.changed)
+	return p
+}
+
+func (p *Provider) Metadata() of.Metadata {
+	return of.Metadata{Name: Name}
+}
+
+func (p *Provider) Hooks() []of.Hook {
+	return nil
+}
+
+// Init starts the sdk, it returns once the flags are loaded
+func (p *Provider) Init(evaluationContext of.EvaluationContext) error {
+	if err := p.sdk.Start(context.Background()); err != nil {
+		return err
+	}
+
+	p.once.Do(func() { close(p.ready) })
+	return nil
+}
+
+// Shutdown closes the sdk
+func (p *Provider) Shutdown() {
+	p.sdk.Close()
+}
+
+func (p *Provider) EventChannel() <-chan of.Event {
+	return p.events
+}
+
+func (p *Provider) changed(old, new featureflag.Flag) {
+	key := new.FlagName
+	if key == "" {
+		key = old.FlagName
+	}
+
+	p.emit(of.ProviderConfigChange, "flag changed", []string{key})
+}
+
+// stateChanged is only called by the sdk stream goroutine
+func (p *Provider) stateChanged(state featureflag.State) {
+	switch state {
+	case featureflag.StatePollingFallback:
+		if !p.stale {
+			p.stale = true
+			p.emit(of.ProviderStale, "featureflag stream disconnected, serving the last flags received", nil)
+		}
+	case featureflag.StateStreaming:
+		if p.stale {
+			p.stale = false
+			p.emit(of.ProviderReady, "featureflag stream reconnected", nil)
+		}
+	}
+}
+
+// emit never blocks the sdk, the events are dropped when nobody reads them
+func (p *Provider) emit(eventType of.EventType, message string, changed []string) {
+	event := of.Event{
+		ProviderName: Name,
+		EventType:    eventType,
+		ProviderEventDetails: of.ProviderEventDetails{
+			Message:     message,
+			FlagChanges: changed,
+		},
+	}
+
+	select {
+	case p.events <- event:
+	default:
+	}
+}
+
+func (p *Provider) BooleanEvaluation(ctx context.Context, flag string, defaultValue bool, evalCtx of.FlattenedContext) of.BoolResolutionDetail {
+	select {
+	case <-p.ready:
+	default:
+		return of.BoolResolutionDetail{
+			Value:                    defaultValue,
+			ProviderResolutionDetail: resolutionError(of.NewProviderNotReadyResolutionError("flags not loaded yet")),
+		}
+	}
+
+	ec := evalContext(evalCtx)
+	response := p.sdk.Evaluate(flag, ec)
+
+	if response.Error != nil {
+		if errors.Is(response.Error, featureflag.ErrNotFoundFeatureFlag) {
+			return of.BoolResolutionDetail{
+				Value:                    defaultValue,
+				ProviderResolutionDetail: resolutionError(of.NewFlagNotFoundResolutionError(response.Error.Error())),
+			}
+		}
+
+		return of.BoolResolutionDetail{
+			Value:                    defaultValue,
+			ProviderResolutionDetail: resolutionError(of.NewGeneralResolutionError(response.Error.Error())),
+		}
+	}
+
+	variant := VariantOff
+	if response.Bool {
+		variant = VariantOn
+	}
+
+	return of.BoolResolutionDetail{
+		Value: response.Bool,
+		ProviderResolutionDetail: of.ProviderResolutionDetail{
+			Reason:  p.reason(flag, ec.TargetingKey),
+			Variant: variant,
+		},
+	}
+}
+
+// reason is STATIC for flags without strategy, TARGETING_MATCH when the strategy used the
+// session and SPLIT when it used the percentage balancer
+func (p *Provider) reason(key, sessionID string) of.Reason {
+	flag, ok := p.sdk.Flag(key)
+	if !ok || !flag.IsUseStrategy() {
+		return of.StaticReason
+	}
+
+	if sessionID != "" {
+		return of.TargetingMatchReason
+	}
+
+	return of.SplitReason
+}
+
+// evalContext keeps the attributes of the types supported by evalctx, numbers as float64
+func evalContext(evalCtx of.FlattenedContext) featureflag.EvalContext {
+	targetingKey, _ := evalCtx[of.TargetingKey].(string)
+	ec := evalctx.New(targetingKey)
+
+	for name, value := range evalCtx {
+		if name == of.TargetingKey {
+			continue
+		}
+
+		switch v := value.(type) {
+		case string:
+			ec = ec.WithString(name, v)
+		case bool:
+			ec = ec.WithBool(name, v)
+		case int:
+			ec = ec.WithNumber(name, float64(v))
+		case int64:
+			ec = ec.WithNumber(name, float64(v))
+		case float64:
+			ec = ec.WithNumber(name, v)
+		case []string:
+			ec = ec.WithList(name, v...)
+		}
+	}
+
+	return ec
+}
+
+func (p *Provider) StringEvaluation(ctx context.Context, flag string, defaultValue string, evalCtx of.FlattenedContext) of.StringResolutionDetail {
+	return of.StringResolutionDetail{Value: defaultValue, ProviderResolutionDetail: typeMismatch()}
+}
+
+func (p *Provider) FloatEvaluation(ctx context.Context, flag string, defaultValue float64, evalCtx of.FlattenedContext) of.FloatResolutionDetail {
+	return of.FloatResolutionDetail{Value: defaultValue, ProviderResolutionDetail: typeMismatch()}
+}
+
+func (p *Provider) IntEvaluation(ctx context.Context, flag string, defaultValue int64, evalCtx of.FlattenedContext) of.IntResolutionDetail {
+	return of.IntResolutionDetail{Value: defaultValue, ProviderResolutionDetail: typeMismatch()}
+}
+
+func (p *Provider) ObjectEvaluation(ctx context.Context, flag string, defaultValue interface{}, evalCtx of.FlattenedContext) of.InterfaceResolutionDetail {
+	return of.InterfaceResolutionDetail{Value: defaultValue, ProviderResolutionDetail: typeMismatch()}
+}
+
+func typeMismatch() of.ProviderResolutionDetail {
+	return resolutionError(of.NewTypeMismatchResolutionError("featureflag only has boolean flags"))
+}
+
+func resolutionError(err of.ResolutionError) of.ProviderResolutionDetail {
+	return of.ProviderResolutionDetail{
+		ResolutionError: err,
+		Reason:          of.ErrorReason,
+	}
+}
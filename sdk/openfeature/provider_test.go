@@ -0,0 +1,206 @@
+package openfeature
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/IsaacDSC/featureflag/sdk/backoff"
+	"github.com/IsaacDSC/featureflag/sdk/featureflag"
+	of "github.com/open-feature/go-sdk/openfeature"
+)
+
+const flags = `[
+	{"flag_name":"on","active":true,"strategy":{}},
+	{"flag_name":"sessions","active":false,"strategy":{"with_strategy":true,"session_id":{"s1":true}}}
+]`
+
+func newServer(t *testing.T, events <-chan string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/featureflags":
+			w.Write([]byte(flags))
+		case "/events/featureflag":
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.(http.Flusher).Flush()
+			for {
+				select {
+				case data := <-events:
+					fmt.Fprintf(w, "id: 1-0\ndata: %s\n\n", data)
+					w.(http.Flusher).Flush()
+				case <-r.Context().Done():
+					return
+				}
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestProvider_BooleanEvaluation(t *testing.T) {
+	server := newServer(t, nil)
+	provider := NewProvider(featureflag.NewFeatureFlagSDK(server.URL))
+	defer provider.Shutdown()
+
+	detail := provider.BooleanEvaluation(context.Background(), "on", false, nil)
+	if detail.Reason != of.ErrorReason || !strings.HasPrefix(detail.ResolutionError.Error(), string(of.ProviderNotReadyCode)) {
+		t.Errorf("before Init got %+v, want PROVIDER_NOT_READY", detail)
+	}
+
+	if err := provider.Init(of.EvaluationContext{}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		flag      string
+		evalCtx   of.FlattenedContext
+		want      bool
+		reason    of.Reason
+		errorCode of.ErrorCode
+	}{
+		{name: "static flag", flag: "on", want: true, reason: of.StaticReason},
+		{name: "targeting key in sessions", flag: "sessions", evalCtx: of.FlattenedContext{of.TargetingKey: "s1"}, want: true, reason: of.TargetingMatchReason},
+		{name: "targeting key out of sessions", flag: "sessions", evalCtx: of.FlattenedContext{of.TargetingKey: "s2"}, want: false, reason: of.TargetingMatchReason},
+		{name: "not found", flag: "missing", want: true, reason: of.ErrorReason, errorCode: of.FlagNotFoundCode},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			detail := provider.BooleanEvaluation(context.Background(), tt.flag, true, tt.evalCtx)
+			if detail.Value != tt.want || detail.Reason != tt.reason {
+				t.Errorf("BooleanEvaluation() = %v (%s), want %v (%s)", detail.Value, detail.Reason, tt.want, tt.reason)
+			}
+
+			if tt.errorCode != "" && !strings.HasPrefix(detail.ResolutionError.Error(), string(tt.errorCode)) {
+				t.Errorf("BooleanEvaluation() error = %v, want %s", detail.ResolutionError, tt.errorCode)
+			}
+		})
+	}
+
+	str := provider.StringEvaluation(context.Background(), "on", "default", nil)
+	if str.Value != "default" || !strings.HasPrefix(str.ResolutionError.Error(), string(of.TypeMismatchCode)) {
+		t.Errorf("StringEvaluation() = %+v, want TYPE_MISMATCH", str)
+	}
+}
+
+func TestProvider_Events(t *testing.T) {
+	events := make(chan string)
+	server := newServer(t, events)
+	provider := NewProvider(featureflag.NewFeatureFlagSDK(server.URL))
+	defer provider.Shutdown()
+
+	if err := provider.Init(of.EvaluationContext{}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	select {
+	case events <- `{"flag_name":"on","active":false,"strategy":{}}`:
+	case <-time.After(2 * time.Second):
+		t.Fatal("sdk did not connect to the stream")
+	}
+
+	select {
+	case event := <-provider.EventChannel():
+		if event.EventType != of.ProviderConfigChange || len(event.FlagChanges) != 1 || event.FlagChanges[0] != "on" {
+			t.Errorf("unexpected event %+v", event)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting configuration changed event")
+	}
+
+	if detail := provider.BooleanEvaluation(context.Background(), "on", true, nil); detail.Value {
+		t.Errorf("BooleanEvaluation() = true after the change, want false")
+	}
+}
+
+func TestProvider_Events_Evaluated(t *testing.T) {
+	events := make(chan string)
+	server := newServer(t, events)
+	provider := NewProvider(featureflag.NewFeatureFlagSDK(server.URL))
+	defer provider.Shutdown()
+
+	if err := provider.Init(of.EvaluationContext{}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	if detail := provider.BooleanEvaluation(context.Background(), "sessions", false, of.FlattenedContext{of.TargetingKey: "s1"}); !detail.Value {
+		t.Fatalf("BooleanEvaluation(sessions, s1) = false, want true")
+	}
+
+	// the same flags in a snapshot, followed by a change to tell when the snapshot was applied
+	snapshot := strings.Join(strings.Fields(flags), "") + "\nevent: snapshot"
+	for _, data := range []string{snapshot, `{"flag_name":"on","active":false,"strategy":{}}`} {
+		select {
+		case events <- data:
+		case <-time.After(2 * time.Second):
+			t.Fatal("sdk did not connect to the stream")
+		}
+	}
+
+	select {
+	case event := <-provider.EventChannel():
+		if event.EventType != of.ProviderConfigChange || len(event.FlagChanges) != 1 || event.FlagChanges[0] != "on" {
+			t.Errorf("unexpected event %+v, want only the change of on", event)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting configuration changed event")
+	}
+}
+
+func TestProvider_Stale(t *testing.T) {
+	var connections atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/featureflags":
+			w.Write([]byte(flags))
+		case "/events/featureflag":
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.(http.Flusher).Flush()
+			if connections.Add(1) == 1 {
+				return
+			}
+			<-r.Context().Done()
+		}
+	}))
+	defer server.Close()
+
+	sdk := featureflag.NewFeatureFlagSDK(server.URL).
+		WithReconnectBackoff(backoff.Backoff{Min: 10 * time.Millisecond, Max: 10 * time.Millisecond})
+	provider := NewProvider(sdk)
+	defer provider.Shutdown()
+
+	if err := provider.Init(of.EvaluationContext{}); err != nil {
+		t.Fatalf("Init() error = %v", err)
+	}
+
+	for _, want := range []of.EventType{of.ProviderStale, of.ProviderReady} {
+		select {
+		case event := <-provider.EventChannel():
+			if event.EventType != want {
+				t.Errorf("event = %s, want %s", event.EventType, want)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timeout waiting %s event", want)
+		}
+	}
+}
+
+func TestProvider_InitError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	provider := NewProvider(featureflag.NewFeatureFlagSDK(server.URL))
+	if err := provider.Init(of.EvaluationContext{}); err == nil {
+		t.Error("Init() error = nil with the server down")
+	}
+}
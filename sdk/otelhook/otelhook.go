@@ -0,0 +1,189 @@
+// Package otelhook records the evaluations of the SDKs as OpenTelemetry spans or span events and metrics.
+//
+//	ff := featureflag.NewFeatureFlagSDK(host).WithEvaluationHook(otelhook.FeatureFlag())
+package otelhook
+
+import (
+	"context"
+	"time"
+
+	"github.com/IsaacDSC/featureflag/sdk/contenthub"
+	"github.com/IsaacDSC/featureflag/sdk/featureflag"
+	"github.com/IsaacDSC/featureflag/sdk/httpclient"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const Scope = "github.com/IsaacDSC/featureflag/sdk/otelhook"
+
+const (
+	VariantOn      = "on"
+	VariantOff     = "off"
+	VariantDefault = "default"
+)
+
+type config struct {
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	events         bool
+}
+
+type Option func(c *config)
+
+// WithTracerProvider replaces the global tracer provider
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(c *config) {
+		c.tracerProvider = tp
+	}
+}
+
+// WithMeterProvider replaces the global meter provider
+func WithMeterProvider(mp metric.MeterProvider) Option {
+	return func(c *config) {
+		c.meterProvider = mp
+	}
+}
+
+// WithEvents adds an event to the span of the ctx given to EvaluateContext instead of starting
+// a span per evaluation, the evaluations without a span in ctx are only counted
+func WithEvents() Option {
+	return func(c *config) {
+		c.events = true
+	}
+}
+
+func newConfig(opts []Option) config {
+	c := config{
+		tracerProvider: otel.GetTracerProvider(),
+		meterProvider:  otel.GetMeterProvider(),
+	}
+
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	return c
+}
+
+func (c config) tracer() trace.Tracer {
+	return c.tracerProvider.Tracer(Scope, trace.WithInstrumentationVersion(httpclient.Version))
+}
+
+func (c config) meter() metric.Meter {
+	return c.meterProvider.Meter(Scope, metric.WithInstrumentationVersion(httpclient.Version))
+}
+
+// FeatureFlag returns the hook of featureflag.FeatureFlagSDK.WithEvaluationHook, it records the span
+// "featureflag.evaluate" and counts featureflag.sdk.evaluations by flag and variant
+func FeatureFlag(opts ...Option) func(ctx context.Context, e featureflag.Evaluation) {
+	c := newConfig(opts)
+	tracer := c.tracer()
+
+	evaluations, err := c.meter().Int64Counter("featureflag.sdk.evaluations",
+		metric.WithDescription("Feature flags evaluated by the SDK, by flag and variant"),
+		metric.WithUnit("{evaluation}"))
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return func(ctx context.Context, e featureflag.Evaluation) {
+		variant := VariantOff
+		switch {
+		case e.Err != nil:
+			variant = VariantDefault
+		case e.Value:
+			variant = VariantOn
+		}
+
+		attrs := []attribute.KeyValue{
+			attribute.String("feature_flag.key", e.Key),
+			attribute.String("feature_flag.variant", variant),
+		}
+		if e.Context.TargetingKey != "" {
+			attrs = append(attrs, attribute.String("feature_flag.context.id", e.Context.TargetingKey))
+		}
+
+		record(ctx, c, tracer, "featureflag.evaluate", e.Start, e.Duration, e.Err, attrs)
+
+		if evaluations != nil {
+			evaluations.Add(ctx, 1, metric.WithAttributes(attrs[:2]...))
+		}
+	}
+}
+
+// Contenthub returns the hook of contenthub.ContenthubSDK.WithEvaluationHook, it records the span
+// "contenthub.evaluate" and counts contenthub.sdk.evaluations by key
+func Contenthub(opts ...Option) func(ctx context.Context, e contenthub.Evaluation) {
+	c := newConfig(opts)
+	tracer := c.tracer()
+
+	evaluations, err := c.meter().Int64Counter("contenthub.sdk.evaluations",
+		metric.WithDescription("Contents evaluated by the SDK, by key"),
+		metric.WithUnit("{evaluation}"))
+	if err != nil {
+		otel.Handle(err)
+	}
+
+	return func(ctx context.Context, e contenthub.Evaluation) {
+		attrs := []attribute.KeyValue{
+			attribute.String("contenthub.key", e.Key),
+			attribute.Bool("contenthub.default", e.Err != nil),
+		}
+		if e.Context.TargetingKey != "" {
+			attrs = append(attrs, attribute.String("contenthub.context.id", e.Context.TargetingKey))
+		}
+
+		record(ctx, c, tracer, "contenthub.evaluate", e.Start, e.Duration, e.Err, attrs)
+
+		if evaluations != nil {
+			evaluations.Add(ctx, 1, metric.WithAttributes(attrs[:2]...))
+		}
+	}
+}
+
+// record starts and ends the span with the times of the evaluation, as the hook runs after it
+func record(ctx context.Context, c config, tracer trace.Tracer, name string, start time.Time, duration time.Duration, err error, attrs []attribute.KeyValue) {
+	if c.events {
+		span := trace.SpanFromContext(ctx)
+		if span.IsRecording() {
+			span.AddEvent(name, trace.WithTimestamp(start), trace.WithAttributes(attrs...))
+		}
+		return
+	}
+
+	_, span := tracer.Start(ctx, name, trace.WithTimestamp(start), trace.WithAttributes(attrs...))
+	if err != nil {
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End(trace.WithTimestamp(start.Add(duration)))
+}
+
+// Synced is implemented by both SDKs
+type Synced interface {
+	SyncedAt() time.Time
+}
+
+// CacheAge reports the seconds since the data of sdk was last received from the server as the gauge
+// featureflag.sdk.cache.age, with resource ("featureflag", "contenthub") as attribute. Nothing is
+// reported while the sdk only has offline data. Unregister the callback when the sdk is closed.
+func CacheAge(sdk Synced, resource string, opts ...Option) (metric.Registration, error) {
+	meter := newConfig(opts).meter()
+
+	age, err := meter.Float64ObservableGauge("featureflag.sdk.cache.age",
+		metric.WithDescription("Time since the SDK last received its data from the server"),
+		metric.WithUnit("s"))
+	if err != nil {
+		return nil, err
+	}
+
+	attrs := metric.WithAttributes(attribute.String("resource", resource))
+	return meter.RegisterCallback(func(ctx context.Context, o metric.Observer) error {
+		if syncedAt := sdk.SyncedAt(); !syncedAt.IsZero() {
+			o.ObserveFloat64(age, time.Since(syncedAt).Seconds(), attrs)
+		}
+		return nil
+	}, age)
+}
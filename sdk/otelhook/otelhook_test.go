@@ -0,0 +1,153 @@
+package otelhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/IsaacDSC/featureflag/sdk/contenthub"
+	"github.com/IsaacDSC/featureflag/sdk/evalctx"
+	"github.com/IsaacDSC/featureflag/sdk/featureflag"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newProviders() (*tracetest.InMemoryExporter, *sdktrace.TracerProvider, *sdkmetric.ManualReader, *sdkmetric.MeterProvider) {
+	spans := tracetest.NewInMemoryExporter()
+	reader := sdkmetric.NewManualReader()
+	return spans, sdktrace.NewTracerProvider(sdktrace.WithSyncer(spans)), reader, sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+}
+
+func collect(t *testing.T, reader *sdkmetric.ManualReader, name string) metricdata.Aggregation {
+	t.Helper()
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+
+	for _, scope := range rm.ScopeMetrics {
+		for _, m := range scope.Metrics {
+			if m.Name == name {
+				return m.Data
+			}
+		}
+	}
+
+	t.Fatalf("metric %s not recorded", name)
+	return nil
+}
+
+func newServer(t *testing.T, path, body string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == path {
+			w.Write([]byte(body))
+			return
+		}
+		<-r.Context().Done()
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestFeatureFlag(t *testing.T) {
+	spans, tp, reader, mp := newProviders()
+	server := newServer(t, "/featureflags", `[{"flag_name":"on","active":true}]`)
+
+	sdk := featureflag.NewFeatureFlagSDK(server.URL).
+		WithEvaluationHook(FeatureFlag(WithTracerProvider(tp), WithMeterProvider(mp)))
+	if err := sdk.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer sdk.Close()
+
+	ctx := evalctx.NewContext(context.Background(), evalctx.New("user-1"))
+	sdk.EvaluateContext(ctx, "on")
+	sdk.GetFeatureFlag("missing")
+
+	got := spans.GetSpans()
+	if len(got) != 2 {
+		t.Fatalf("recorded %d spans, want 2", len(got))
+	}
+
+	want := []attribute.KeyValue{
+		attribute.String("feature_flag.key", "on"),
+		attribute.String("feature_flag.variant", VariantOn),
+		attribute.String("feature_flag.context.id", "user-1"),
+	}
+	if got[0].Name != "featureflag.evaluate" || !equalAttributes(got[0].Attributes, want) {
+		t.Errorf("span = %s %v, want featureflag.evaluate %v", got[0].Name, got[0].Attributes, want)
+	}
+
+	if got[1].Status.Description != featureflag.ErrNotFoundFeatureFlag.Error() {
+		t.Errorf("status of missing flag = %+v", got[1].Status)
+	}
+
+	sum := collect(t, reader, "featureflag.sdk.evaluations").(metricdata.Sum[int64])
+	if len(sum.DataPoints) != 2 {
+		t.Errorf("evaluations by variant = %+v, want on and default", sum.DataPoints)
+	}
+}
+
+func TestContenthub_Events(t *testing.T) {
+	spans, tp, _, mp := newProviders()
+	server := newServer(t, "/contenthubs", `[{"key":"banner","balancer_strategy":[{"weight":100,"response":"a"}]}]`)
+
+	sdk := contenthub.NewContenthubSDK(server.URL).
+		WithEvaluationHook(Contenthub(WithTracerProvider(tp), WithMeterProvider(mp), WithEvents()))
+	if err := sdk.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer sdk.Close()
+
+	ctx, span := tp.Tracer("test").Start(context.Background(), "request")
+	if _, err := contenthub.String(sdk, "banner", ctx, ""); err != nil {
+		t.Fatalf("String() error = %v", err)
+	}
+	span.End()
+
+	// without span in ctx, the evaluation is only counted
+	sdk.Content("banner")
+
+	got := spans.GetSpans()
+	if len(got) != 1 || len(got[0].Events) != 1 || got[0].Events[0].Name != "contenthub.evaluate" {
+		t.Fatalf("spans = %+v, want the request span with one evaluation event", got)
+	}
+}
+
+func TestCacheAge(t *testing.T) {
+	_, _, reader, mp := newProviders()
+	server := newServer(t, "/featureflags", `[]`)
+
+	sdk := featureflag.NewFeatureFlagSDK(server.URL)
+	if err := sdk.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer sdk.Close()
+
+	registration, err := CacheAge(sdk, "featureflag", WithMeterProvider(mp))
+	if err != nil {
+		t.Fatalf("CacheAge() error = %v", err)
+	}
+	defer registration.Unregister()
+
+	time.Sleep(10 * time.Millisecond)
+
+	gauge := collect(t, reader, "featureflag.sdk.cache.age").(metricdata.Gauge[float64])
+	if len(gauge.DataPoints) != 1 || gauge.DataPoints[0].Value < 0.01 || gauge.DataPoints[0].Value > 1 {
+		t.Errorf("cache age = %+v, want about 10ms", gauge.DataPoints)
+	}
+}
+
+func equalAttributes(got, want []attribute.KeyValue) bool {
+	gotSet, wantSet := attribute.NewSet(got...), attribute.NewSet(want...)
+	return gotSet.Equals(&wantSet)
+}
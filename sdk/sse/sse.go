@@ -0,0 +1,73 @@
+package sse
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+const maxEventSize = 10 * 1024 * 1024
+
+// Event is a dispatched server-sent event. ID is the last event id seen on the stream,
+// so it is kept for events that do not set their own.
+type Event struct {
+	ID    string
+	Event string
+	Data  string
+}
+
+type Reader struct {
+	scanner *bufio.Scanner
+	lastID  string
+}
+
+func NewReader(r io.Reader) *Reader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEventSize)
+
+	return &Reader{scanner: scanner}
+}
+
+// Next blocks until the next event is dispatched, it returns io.EOF when the stream ends
+func (r *Reader) Next() (Event, error) {
+	var (
+		event string
+		data  []string
+	)
+
+	for r.scanner.Scan() {
+		line := r.scanner.Text()
+
+		if line == "" {
+			if len(data) == 0 {
+				event = ""
+				continue
+			}
+
+			return Event{ID: r.lastID, Event: event, Data: strings.Join(data, "\n")}, nil
+		}
+
+		// comments are used as heartbeat
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "id":
+			r.lastID = value
+		case "event":
+			event = value
+		case "data":
+			data = append(data, value)
+		}
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return Event{}, err
+	}
+
+	return Event{}, io.EOF
+}
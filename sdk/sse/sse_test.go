@@ -0,0 +1,68 @@
+package sse
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestReader_Next(t *testing.T) {
+	tests := []struct {
+		name   string
+		stream string
+		want   []Event
+	}{
+		{
+			name:   "data only event",
+			stream: "data: {\"flag_name\":\"a\"}\n\n",
+			want:   []Event{{Data: `{"flag_name":"a"}`}},
+		},
+		{
+			name:   "event with id and type",
+			stream: "id: 1-0\nevent: snapshot\ndata: []\n\n",
+			want:   []Event{{ID: "1-0", Event: "snapshot", Data: "[]"}},
+		},
+		{
+			name:   "id is kept for following events",
+			stream: "id: 1-0\ndata: a\n\ndata: b\n\n",
+			want:   []Event{{ID: "1-0", Data: "a"}, {ID: "1-0", Data: "b"}},
+		},
+		{
+			name:   "multi line data and comments",
+			stream: ": heartbeat\n\ndata: a\ndata: b\n\n",
+			want:   []Event{{Data: "a\nb"}},
+		},
+		{
+			name:   "event type is reset after dispatch",
+			stream: "event: snapshot\ndata: []\n\nid: 2-0\ndata: x\n\n",
+			want:   []Event{{Event: "snapshot", Data: "[]"}, {ID: "2-0", Data: "x"}},
+		},
+		{
+			name:   "incomplete event is not dispatched",
+			stream: "data: a\n\ndata: b\n",
+			want:   []Event{{Data: "a"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader := NewReader(strings.NewReader(tt.stream))
+
+			for _, want := range tt.want {
+				got, err := reader.Next()
+				if err != nil {
+					t.Fatalf("Next() error = %v", err)
+				}
+
+				if got != want {
+					t.Errorf("Next() = %+v, want %+v", got, want)
+				}
+			}
+
+			if _, err := reader.Next(); !errors.Is(err, io.EOF) {
+				t.Errorf("Next() error = %v, want io.EOF", err)
+			}
+		})
+	}
+}
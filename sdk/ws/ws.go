@@ -0,0 +1,70 @@
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/IsaacDSC/featureflag/sdk/sse"
+	"github.com/gorilla/websocket"
+)
+
+// message is the payload sent by GET /ws/{resource}, the same fields of a server-sent event
+type message struct {
+	ID    string          `json:"id"`
+	Event string          `json:"event"`
+	Data  json.RawMessage `json:"data"`
+}
+
+type Reader struct {
+	conn   *websocket.Conn
+	stop   func() bool
+	lastID string
+}
+
+// Dial connects to the websocket endpoint of resource, host is the http(s) address of the server
+func Dial(ctx context.Context, dialer *websocket.Dialer, host, resource string, header http.Header) (*Reader, error) {
+	url := fmt.Sprintf("%s/ws/%s", host, resource)
+	if after, ok := strings.CutPrefix(url, "http"); ok {
+		url = "ws" + after
+	}
+
+	conn, resp, err := dialer.DialContext(ctx, url, header)
+	if err != nil {
+		if resp != nil {
+			return nil, fmt.Errorf("error on connect websocket, status %d: %w", resp.StatusCode, err)
+		}
+		return nil, fmt.Errorf("error on connect websocket: %w", err)
+	}
+
+	// the read blocks without context, closing the connection releases it
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+
+	return &Reader{conn: conn, stop: stop}, nil
+}
+
+// Next blocks until the next message, pings from the server are answered while reading.
+// It returns io.EOF when the server closes the connection normally.
+func (r *Reader) Next() (sse.Event, error) {
+	var msg message
+	if err := r.conn.ReadJSON(&msg); err != nil {
+		if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+			return sse.Event{}, io.EOF
+		}
+		return sse.Event{}, err
+	}
+
+	if msg.ID != "" {
+		r.lastID = msg.ID
+	}
+
+	return sse.Event{ID: r.lastID, Event: msg.Event, Data: string(msg.Data)}, nil
+}
+
+func (r *Reader) Close() error {
+	r.stop()
+	return r.conn.Close()
+}